@@ -0,0 +1,822 @@
+package cli
+
+import (
+	metadata "Kindria/internal/core/api/books"
+	"Kindria/internal/core/platform/storage"
+	kindle "Kindria/tools"
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+var errUsage = errors.New("usage")
+
+var validStatuses = []string{"Read", "Unread", "To Be Read", "Currently Reading", "Archived"}
+
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(h *metadata.Handler, args []string) error
+	// covers marks the commands that queue cover lookups and so need the
+	// cover worker running.
+	covers bool
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{name: "import", args: "[--on-duplicate skip|keep|replace] <files...>", summary: "Copy .epub files into the library and insert them", run: runImport, covers: true},
+		{name: "scan", args: "", summary: "Insert books found in the library folder that are not in the database", run: runScan},
+		{name: "list", args: "[--status S] [--query Q] [--shelf NAME] [--json]", summary: "List books in the library, optionally only those matching a shelf query (e.g. \"status:unread rating>=4 genre:fantasy lang:es added<30d\") or on a shelf", run: runList},
+		{name: "rate", args: "<file> <0.0-5.0>", summary: "Set the rating of a book", run: runRate},
+		{name: "status", args: "[--note TEXT] <file> <status>", summary: "Set the status of a book (Read, Unread, \"To Be Read\", \"Currently Reading\", Archived); --note is added to its latest read", run: runStatus},
+		{name: "history", args: "[--json] <file>", summary: "Show every read of a book with its dates, rating and notes", run: runHistory},
+		{name: "stats", args: "[--year YYYY] [--json]", summary: "Show the reading log and statistics of a year (the current one by default, 0 for all years)", run: runStats},
+		{name: "goals", args: "[--year YYYY] [--json] | set [--year YYYY] [--pages] <target> | add [--year YYYY] [--pages] [--language L] [--genre G] <name> <target> | remove [--year YYYY] [name]", summary: "Show the reading goals of a year with their pace, or set the yearly goal, add a challenge or remove one", run: runGoals},
+		{name: "progress", args: "<file> <0-100>", summary: "Set how much of a book has been read, in percent", run: runProgress},
+		{name: "remove", args: "[--trash] [--yes] <files...>", summary: "Remove books from the library and delete (or trash) their files", run: runRemove},
+		{name: "sync-kindle", args: "[--on-duplicate skip|keep|replace] [files...]", summary: "Copy books from a connected Kindle (all when no files are given)", run: runSyncKindle, covers: true},
+		{name: "covers", args: "[--retry]", summary: "Show the Open Library cover queue; --retry queues failed lookups again", run: runCovers, covers: true},
+		{name: "enrich", args: "[--yes] [--dry-run] [files...]", summary: "Fill missing metadata from the metadata providers (books not looked up yet when no files are given)", run: runEnrich},
+		{name: "db", args: "migrate|rollback|status", summary: "Manage the database schema", run: runDB},
+		{name: "help", args: "", summary: "Show this help", run: runHelp},
+	}
+}
+
+// NeedsCoverWorker reports whether the command in args queues cover lookups.
+func NeedsCoverWorker(args []string) bool {
+	for _, c := range commands {
+		if len(args) > 0 && c.name == args[0] {
+			return c.covers
+		}
+	}
+	return false
+}
+
+func Run(h *metadata.Handler, args []string) error {
+	if len(args) == 0 {
+		printUsage(os.Stderr)
+		return errUsage
+	}
+	name := args[0]
+	if name == "-h" || name == "--help" {
+		name = "help"
+	}
+	for _, c := range commands {
+		if c.name != name {
+			continue
+		}
+		err := c.run(h, args[1:])
+		if errors.Is(err, errUsage) {
+			fmt.Fprintf(os.Stderr, "usage: kindria %s %s\n", c.name, c.args)
+		}
+		return err
+	}
+	printUsage(os.Stderr)
+	return fmt.Errorf("unknown command: %s", name)
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: kindria [command] [args]")
+	fmt.Fprintln(w, "\nWithout a command the terminal UI is started.")
+	fmt.Fprintln(w, "\nCommands:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, c := range commands {
+		fmt.Fprintf(tw, "  %s %s\t%s\n", c.name, c.args, c.summary)
+	}
+	tw.Flush()
+}
+
+func runHelp(h *metadata.Handler, args []string) error {
+	printUsage(os.Stdout)
+	return nil
+}
+
+func runImport(h *metadata.Handler, args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	onDuplicate := duplicateFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() == 0 {
+		return errUsage
+	}
+	policy, err := metadata.ParseDuplicatePolicy(*onDuplicate)
+	if err != nil {
+		return err
+	}
+	files := make([]string, 0, fs.NArg())
+	for _, a := range fs.Args() {
+		if !strings.EqualFold(filepath.Ext(a), ".epub") {
+			return fmt.Errorf("not an .epub file: %s", a)
+		}
+		if _, err := os.Stat(a); err != nil {
+			return err
+		}
+		files = append(files, a)
+	}
+	res, err := h.ImportFiles(files, policy)
+	for _, f := range res.Failed {
+		fmt.Fprintf(os.Stderr, "failed: %s\n", f)
+	}
+	for _, f := range res.Replaced {
+		fmt.Printf("replaced: %s\n", f)
+	}
+	fmt.Printf("Inserted: %d | Replaced: %d | Failed: %d | Duplicated: %d\n", len(res.Inserted), len(res.Replaced), len(res.Failed), res.Duplicated)
+	if err != nil {
+		return err
+	}
+	if len(res.Failed) > 0 {
+		return fmt.Errorf("%d books failed to import", len(res.Failed))
+	}
+	return nil
+}
+
+func duplicateFlag(fs *flag.FlagSet) *string {
+	return fs.String("on-duplicate", string(metadata.DuplicateSkip), "what to do with a book whose title and author are already in the library: skip, keep (import both) or replace")
+}
+
+func runScan(h *metadata.Handler, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+	inserted, err := h.InsertBooks()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Inserted: %d\n", len(inserted))
+	return nil
+}
+
+type bookJSON struct {
+	Title       string   `json:"title"`
+	Author      string   `json:"author"`
+	Genres      []string `json:"genres"`
+	Series      string   `json:"series"`
+	SeriesIndex float64  `json:"series_index"`
+	Status      string   `json:"status"`
+	Rating      float64  `json:"rating"`
+	ReadingDate string   `json:"reading_date"`
+	FileName    string   `json:"file_name"`
+}
+
+func runList(h *metadata.Handler, args []string) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	status := fs.String("status", "", "only list books with this status (archived books are only listed with --status Archived)")
+	query := fs.String("query", "", "only list books matching this shelf query")
+	shelf := fs.String("shelf", "", "only list books on this shelf")
+	asJSON := fs.Bool("json", false, "print books as JSON")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() != 0 {
+		return errUsage
+	}
+	if *status != "" {
+		normalized, err := normalizeStatus(*status)
+		if err != nil {
+			return err
+		}
+		*status = normalized
+	}
+
+	// A query or a shelf decides on archived books itself.
+	var scope map[string]bool
+	if *query != "" || *shelf != "" {
+		matches, err := listScope(h, *query, *shelf)
+		if err != nil {
+			return err
+		}
+		scope = make(map[string]bool, len(matches))
+		for _, b := range matches {
+			scope[b.FileName] = true
+		}
+	}
+
+	books, err := h.SelectBookInfo()
+	if err != nil {
+		return err
+	}
+	out := make([]bookJSON, 0, len(books))
+	for _, b := range books {
+		if *status != "" && b.Status != *status {
+			continue
+		}
+		if scope != nil && !scope[b.BookFile] {
+			continue
+		}
+		if *status == "" && scope == nil && b.Status == "Archived" {
+			continue
+		}
+		genres := b.Metadata.Genres
+		if genres == nil {
+			genres = []string{}
+		}
+		out = append(out, bookJSON{
+			Title:       b.Metadata.Title,
+			Author:      b.Metadata.Author,
+			Genres:      genres,
+			Series:      b.Metadata.Series,
+			SeriesIndex: b.Metadata.SeriesIndex,
+			Status:      b.Status,
+			Rating:      b.Rating,
+			ReadingDate: b.ReadingDate,
+			FileName:    b.BookFile,
+		})
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TITLE\tAUTHOR\tSERIES\tSTATUS\tRATING\tREAD ON\tFILE")
+	for _, b := range out {
+		series := metadata.MetaData{Series: b.Series, SeriesIndex: b.SeriesIndex}.SeriesLabel()
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%.1f\t%s\t%s\n", b.Title, b.Author, series, b.Status, b.Rating, b.ReadingDate, b.FileName)
+	}
+	return tw.Flush()
+}
+
+// listScope returns the books matching a query and on a shelf, when given.
+func listScope(h *metadata.Handler, query, shelf string) ([]metadata.ShelfBook, error) {
+	var books []metadata.ShelfBook
+	if query != "" {
+		matches, err := h.QueryBooks(query)
+		if err != nil {
+			return nil, fmt.Errorf("invalid query: %w", err)
+		}
+		books = matches
+	}
+	if shelf == "" {
+		return books, nil
+	}
+	shelves, err := h.ListShelves()
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range shelves {
+		if !strings.EqualFold(s.Name, strings.TrimSpace(shelf)) {
+			continue
+		}
+		onShelf, err := h.ShelfBooks(s.ID)
+		if err != nil || query == "" {
+			return onShelf, err
+		}
+		both := make([]metadata.ShelfBook, 0, len(books))
+		for _, b := range books {
+			for _, o := range onShelf {
+				if o.FileName == b.FileName {
+					both = append(both, b)
+					break
+				}
+			}
+		}
+		return both, nil
+	}
+	return nil, fmt.Errorf("no shelf called %q", shelf)
+}
+
+func runRate(h *metadata.Handler, args []string) error {
+	if len(args) != 2 {
+		return errUsage
+	}
+	file := filepath.Base(args[0])
+	rating, err := strconv.ParseFloat(args[1], 64)
+	if err != nil || rating > 5.0 || rating < 0.0 {
+		return fmt.Errorf("invalid rating %q: expected a number between 0.0 and 5.0", args[1])
+	}
+	if err := requireBook(h, file); err != nil {
+		return err
+	}
+	if err := h.UpdateBookRating(rating, file); err != nil {
+		return err
+	}
+	fmt.Printf("%s: rating %.1f\n", file, rating)
+	return nil
+}
+
+func runStatus(h *metadata.Handler, args []string) error {
+	fs := flag.NewFlagSet("status", flag.ContinueOnError)
+	note := fs.String("note", "", "note added to the latest read of the book")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() != 2 {
+		return errUsage
+	}
+	file := filepath.Base(fs.Arg(0))
+	status, err := normalizeStatus(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+	if err := requireBook(h, file); err != nil {
+		return err
+	}
+	if *note != "" && status != "Read" && status != "Currently Reading" {
+		sessions, err := h.Sessions(file)
+		if err != nil {
+			return err
+		}
+		if len(sessions) == 0 {
+			return fmt.Errorf("%s has not been read yet: a note needs the status Read or \"Currently Reading\"", file)
+		}
+	}
+	if status == "Archived" {
+		if _, err := h.ArchiveBook(file, true); err != nil {
+			return err
+		}
+		fmt.Printf("%s: %s\n", file, status)
+		return h.AddSessionNote(file, *note)
+	}
+	status, readingDate, err := h.UpdateBookStatus(status, file)
+	if err != nil {
+		return err
+	}
+	if err := h.AddSessionNote(file, *note); err != nil {
+		return err
+	}
+	switch {
+	case readingDate == "":
+		fmt.Printf("%s: %s\n", file, status)
+	case status == "Read":
+		fmt.Printf("%s: %s (%s)\n", file, status, readingDate)
+	default:
+		fmt.Printf("%s: %s (last read %s)\n", file, status, readingDate)
+	}
+	return nil
+}
+
+type sessionJSON struct {
+	StartedAt  string  `json:"started_at"`
+	FinishedAt string  `json:"finished_at"`
+	Rating     float64 `json:"rating"`
+	Notes      string  `json:"notes"`
+}
+
+func runHistory(h *metadata.Handler, args []string) error {
+	fs := flag.NewFlagSet("history", flag.ContinueOnError)
+	asJSON := fs.Bool("json", false, "print the reads as JSON")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() != 1 {
+		return errUsage
+	}
+	file := filepath.Base(fs.Arg(0))
+	if err := requireBook(h, file); err != nil {
+		return err
+	}
+	sessions, err := h.Sessions(file)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		out := make([]sessionJSON, 0, len(sessions))
+		for _, s := range sessions {
+			out = append(out, sessionJSON{StartedAt: s.StartedAt, FinishedAt: s.FinishedAt, Rating: s.Rating, Notes: s.Notes})
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(out)
+	}
+	if len(sessions) == 0 {
+		fmt.Printf("%s: not read yet\n", file)
+		return nil
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "#\tSTARTED\tFINISHED\tRATING\tNOTES")
+	for i, s := range sessions {
+		started, finished := s.StartedAt, s.FinishedAt
+		if started == "" {
+			started = "-"
+		}
+		if finished == "" {
+			finished = "reading"
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%.1f\t%s\n", i+1, started, finished, s.Rating, strings.ReplaceAll(s.Notes, "\n", "; "))
+	}
+	return tw.Flush()
+}
+
+func runStats(h *metadata.Handler, args []string) error {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	year := fs.Int("year", time.Now().Year(), "year to report on, 0 for all years")
+	asJSON := fs.Bool("json", false, "print the statistics as JSON")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() != 0 || *year < 0 {
+		return errUsage
+	}
+	stats, err := h.ReadingStats(*year)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(stats)
+	}
+
+	period := "All years"
+	if *year != 0 {
+		period = strconv.Itoa(*year)
+	}
+	pages := strconv.FormatInt(stats.Pages, 10)
+	if stats.EstimatedPages {
+		pages += " (partly estimated)"
+	}
+	fmt.Printf("%s: %d books read, %d being read\n", period, len(stats.Log), stats.Reading)
+	if stats.Rated > 0 {
+		fmt.Printf("Average rating: %.2f (%d rated)\n", stats.AverageRating, stats.Rated)
+	}
+	fmt.Printf("Pages: %s | Words: %d\n", pages, stats.Words)
+	if stats.DayStreak.Length > 0 {
+		fmt.Printf("Longest reading streak: %s (%s to %s)\n", plural(stats.DayStreak.Length, "day"), stats.DayStreak.Start, stats.DayStreak.End)
+	}
+	if stats.MonthStreak.Length > 0 {
+		fmt.Printf("Finished a book every month for: %s (%s to %s)\n", plural(stats.MonthStreak.Length, "month"), stats.MonthStreak.Start, stats.MonthStreak.End)
+	}
+	if len(stats.Log) == 0 {
+		return nil
+	}
+	fmt.Println()
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "FINISHED\tTITLE\tAUTHOR\tRATING\tPAGES\tFILE")
+	for _, r := range stats.Log {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%.1f\t%d\t%s\n", r.FinishedAt, r.Title, r.Author, r.Rating, r.Pages, r.FileName)
+	}
+	return tw.Flush()
+}
+
+func runGoals(h *metadata.Handler, args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case "set", "add":
+			return runGoalsSet(h, args[0], args[1:])
+		case "remove":
+			return runGoalsRemove(h, args[1:])
+		}
+	}
+	fs := flag.NewFlagSet("goals", flag.ContinueOnError)
+	year := fs.Int("year", time.Now().Year(), "year of the goals")
+	asJSON := fs.Bool("json", false, "print the goals as JSON")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() != 0 {
+		return errUsage
+	}
+	goals, err := h.Goals(*year)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		if goals == nil {
+			goals = []metadata.Goal{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(goals)
+	}
+	if len(goals) == 0 {
+		fmt.Printf("No goals for %d: kindria goals set <target>\n", *year)
+		return nil
+	}
+	now := time.Now()
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "GOAL\tFILTER\tPROGRESS\tPACE")
+	for _, g := range goals {
+		filters := []string{}
+		if g.Language != "" {
+			filters = append(filters, "language "+g.Language)
+		}
+		if g.Genre != "" {
+			filters = append(filters, "genre "+g.Genre)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d/%d %s\t%s\n", g.Label(), strings.Join(filters, ", "), g.Progress, g.Target, g.Unit, g.Pace(now))
+	}
+	return tw.Flush()
+}
+
+func runGoalsSet(h *metadata.Handler, name string, args []string) error {
+	fs := flag.NewFlagSet("goals "+name, flag.ContinueOnError)
+	year := fs.Int("year", time.Now().Year(), "year of the goal")
+	pages := fs.Bool("pages", false, "count pages instead of books")
+	var language, genre *string
+	if name == "add" {
+		language = fs.String("language", "", "only count books in this language (code, e.g. es)")
+		genre = fs.String("genre", "", "only count books with this genre")
+	}
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	g := metadata.Goal{Year: *year, Unit: metadata.GoalBooks}
+	if *pages {
+		g.Unit = metadata.GoalPages
+	}
+	rest := fs.Args()
+	if name == "add" {
+		if len(rest) != 2 || strings.TrimSpace(rest[0]) == "" {
+			return errUsage
+		}
+		g.Name, g.Language, g.Genre = rest[0], *language, *genre
+		rest = rest[1:]
+	}
+	if len(rest) != 1 {
+		return errUsage
+	}
+	target, err := strconv.ParseInt(rest[0], 10, 64)
+	if err != nil || target <= 0 {
+		return fmt.Errorf("invalid target %q: expected a positive whole number", rest[0])
+	}
+	g.Target = target
+	if err := h.SetGoal(g); err != nil {
+		return err
+	}
+	fmt.Printf("%s: %d %s\n", g.Label(), g.Target, g.Unit)
+	return nil
+}
+
+func runGoalsRemove(h *metadata.Handler, args []string) error {
+	fs := flag.NewFlagSet("goals remove", flag.ContinueOnError)
+	year := fs.Int("year", time.Now().Year(), "year of the goal")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() > 1 {
+		return errUsage
+	}
+	g := metadata.Goal{Year: *year, Name: fs.Arg(0)}
+	if err := h.RemoveGoal(g.Year, g.Name); err != nil {
+		return err
+	}
+	fmt.Printf("Removed %s\n", g.Label())
+	return nil
+}
+
+func plural(n int, word string) string {
+	if n == 1 {
+		return "1 " + word
+	}
+	return strconv.Itoa(n) + " " + word + "s"
+}
+
+func runProgress(h *metadata.Handler, args []string) error {
+	if len(args) != 2 {
+		return errUsage
+	}
+	file := filepath.Base(args[0])
+	percent, err := strconv.ParseFloat(strings.TrimSuffix(args[1], "%"), 64)
+	if err != nil || percent > 100 || percent < 0 {
+		return fmt.Errorf("invalid progress %q: expected a number between 0 and 100", args[1])
+	}
+	if err := requireBook(h, file); err != nil {
+		return err
+	}
+	status, _, err := h.SaveProgress(file, metadata.Progress{Chapter: -1, Percent: percent})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s: %.0f%% (%s)\n", file, percent, status)
+	return nil
+}
+
+func runRemove(h *metadata.Handler, args []string) error {
+	fs := flag.NewFlagSet("remove", flag.ContinueOnError)
+	trash := fs.Bool("trash", false, "move the files to the library's "+metadata.TrashDir+" folder instead of deleting them")
+	yes := fs.Bool("yes", false, "do not ask for confirmation")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() == 0 {
+		return errUsage
+	}
+	files := make([]string, 0, fs.NArg())
+	for _, a := range fs.Args() {
+		file := filepath.Base(a)
+		if err := requireBook(h, file); err != nil {
+			return err
+		}
+		files = append(files, file)
+	}
+	if !*yes {
+		action := "delete their files"
+		if *trash {
+			action = "move their files to " + metadata.TrashDir
+		}
+		fmt.Printf("Remove %d book(s) and %s? [y/N] ", len(files), action)
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+			fmt.Println("Aborted")
+			return nil
+		}
+	}
+	for _, file := range files {
+		dest, err := h.RemoveBook(file, *trash)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		if dest != "" {
+			fmt.Printf("%s: removed (moved to %s)\n", file, dest)
+		} else {
+			fmt.Printf("%s: removed\n", file)
+		}
+	}
+	return nil
+}
+
+func runSyncKindle(h *metadata.Handler, args []string) error {
+	fs := flag.NewFlagSet("sync-kindle", flag.ContinueOnError)
+	onDuplicate := duplicateFlag(fs)
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	policy, err := metadata.ParseDuplicatePolicy(*onDuplicate)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+	res, err := kindle.KindleExtract(ctx, h, "", fs.Args(), policy)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Detected: %d | Inserted: %d | Replaced: %d | Failed: %d | Duplicated: %d\n", len(res.DetectedBooks), res.Inserted, res.Replaced, res.Failed, res.Duplicated)
+	return nil
+}
+
+func runCovers(h *metadata.Handler, args []string) error {
+	fs := flag.NewFlagSet("covers", flag.ContinueOnError)
+	retry := fs.Bool("retry", false, "queue failed and not-found lookups again")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() != 0 {
+		return errUsage
+	}
+	if *retry {
+		n, err := h.RetryCoverJobs()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Queued %d covers again\n", n)
+	}
+	counts, err := h.CoverJobCounts()
+	if err != nil {
+		return err
+	}
+	statuses := []string{metadata.CoverJobPending, metadata.CoverJobRunning, metadata.CoverJobDone, metadata.CoverJobMissing, metadata.CoverJobFailed}
+	parts := make([]string, len(statuses))
+	for i, s := range statuses {
+		parts[i] = fmt.Sprintf("%s: %d", strings.ToUpper(s[:1])+s[1:], counts[s])
+	}
+	fmt.Println(strings.Join(parts, " | "))
+	return nil
+}
+
+func runEnrich(h *metadata.Handler, args []string) error {
+	fs := flag.NewFlagSet("enrich", flag.ContinueOnError)
+	yes := fs.Bool("yes", false, "apply every change without asking")
+	dryRun := fs.Bool("dry-run", false, "only show the changes")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	var files []string
+	for _, a := range fs.Args() {
+		file := filepath.Base(a)
+		if err := requireBook(h, file); err != nil {
+			return err
+		}
+		files = append(files, file)
+	}
+	if len(files) == 0 {
+		var err error
+		if files, err = h.BooksToEnrich(); err != nil {
+			return err
+		}
+	}
+	stdin := bufio.NewReader(os.Stdin)
+	applied := 0
+	for _, file := range files {
+		e, err := h.EnrichBook(context.Background(), file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", file, err)
+			continue
+		}
+		if len(e.Changes) == 0 {
+			fmt.Printf("%s: nothing to add\n", file)
+		} else {
+			fmt.Printf("%s (%s)\n", file, e.Title)
+			for _, c := range e.Changes {
+				old := c.Old
+				if old == "" {
+					old = "-"
+				}
+				fmt.Printf("  %s: %s -> %s\n", c.Field, old, strings.Join(strings.Fields(c.New), " "))
+			}
+		}
+		if *dryRun {
+			continue
+		}
+		if len(e.Changes) > 0 && !*yes {
+			fmt.Print("Apply? [y/N] ")
+			answer, _ := stdin.ReadString('\n')
+			if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+				for i := range e.Changes {
+					e.Changes[i].Accept = false
+				}
+			}
+		}
+		if err := h.ApplyEnrichment(e); err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		if slices.ContainsFunc(e.Changes, func(c metadata.FieldChange) bool { return c.Accept }) {
+			applied++
+		}
+	}
+	fmt.Printf("Looked up: %d | Updated: %d\n", len(files), applied)
+	return nil
+}
+
+func runDB(h *metadata.Handler, args []string) error {
+	if len(args) != 1 {
+		return errUsage
+	}
+	switch args[0] {
+	case "migrate":
+		ran, err := storage.Migrate(h.DB)
+		for _, m := range ran {
+			fmt.Printf("Applied %05d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(ran) == 0 {
+			fmt.Println("Database is up to date")
+		}
+	case "rollback":
+		m, err := storage.Rollback(h.DB)
+		if err != nil {
+			return err
+		}
+		if m == nil {
+			fmt.Println("No migrations to roll back")
+			return nil
+		}
+		fmt.Printf("Rolled back %05d_%s\n", m.Version, m.Name)
+	case "status":
+		statuses, err := storage.Status(h.DB)
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.Applied() {
+				appliedAt = s.AppliedAt
+			}
+			fmt.Fprintf(tw, "%05d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+		return tw.Flush()
+	default:
+		return errUsage
+	}
+	return nil
+}
+
+func requireBook(h *metadata.Handler, file string) error {
+	exists, err := h.CheckBookExist(file)
+	if err != nil {
+		return err
+	}
+	if exists == 0 {
+		return fmt.Errorf("book not found: %s", file)
+	}
+	return nil
+}
+
+func normalizeStatus(s string) (string, error) {
+	for _, v := range validStatuses {
+		if strings.EqualFold(v, strings.TrimSpace(s)) {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("invalid status %q: expected one of %s", s, strings.Join(validStatuses, ", "))
+}
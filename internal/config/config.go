@@ -0,0 +1,218 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type Config struct {
+	LibraryDir string `json:"library_dir"`
+	DBPath     string `json:"db_path"`
+	CacheDir   string `json:"cache_dir"`
+	LogPath    string `json:"log_path"`
+	// Providers lists the metadata and cover sources in priority order. When
+	// empty the app's defaults are used.
+	Providers []Provider `json:"providers,omitempty"`
+}
+
+type Provider struct {
+	Name      string `json:"name"`
+	Dir       string `json:"dir,omitempty"`
+	RateLimit string `json:"rate_limit,omitempty"`
+}
+
+// Interval parses RateLimit, the minimum time between two lookups.
+func (p Provider) Interval() (time.Duration, error) {
+	if p.RateLimit == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(p.RateLimit)
+}
+
+func (c Config) CoversDir() string {
+	return filepath.Join(c.CacheDir, "covers")
+}
+
+// Load resolves the configuration with the precedence
+// defaults < config file < environment < flags. Global flags must come before
+// the subcommand; the remaining arguments are returned untouched.
+func Load(args []string) (Config, []string, error) {
+	cfg, err := Default()
+	if err != nil {
+		return Config{}, nil, err
+	}
+
+	fs := flag.NewFlagSet("kindria", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	configPath := fs.String("config", "", "path to a JSON config file")
+	libraryDir := fs.String("library", "", "directory holding the .epub files")
+	dbPath := fs.String("db", "", "path to the SQLite database")
+	cacheDir := fs.String("cache", "", "directory for cached covers")
+	logPath := fs.String("log", "", "path to the log file")
+	var rest []string
+	if err := fs.Parse(args); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			return Config{}, nil, err
+		}
+		rest = []string{"help"}
+	} else {
+		rest = fs.Args()
+	}
+
+	path := *configPath
+	if path == "" {
+		path = os.Getenv("KINDRIA_CONFIG")
+	}
+	explicit := path != ""
+	if !explicit {
+		path, err = DefaultConfigPath()
+		if err != nil {
+			return Config{}, nil, err
+		}
+	}
+	if err := cfg.mergeFile(path); err != nil {
+		if explicit || !errors.Is(err, os.ErrNotExist) {
+			return Config{}, nil, fmt.Errorf("config %s: %w", path, err)
+		}
+	}
+
+	cfg.merge(Config{
+		LibraryDir: os.Getenv("KINDRIA_LIBRARY_DIR"),
+		DBPath:     os.Getenv("KINDRIA_DB"),
+		CacheDir:   os.Getenv("KINDRIA_CACHE_DIR"),
+		LogPath:    os.Getenv("KINDRIA_LOG"),
+	})
+	cfg.merge(Config{
+		LibraryDir: *libraryDir,
+		DBPath:     *dbPath,
+		CacheDir:   *cacheDir,
+		LogPath:    *logPath,
+	})
+
+	for _, p := range []*string{&cfg.LibraryDir, &cfg.DBPath, &cfg.CacheDir, &cfg.LogPath} {
+		if *p, err = expandPath(*p); err != nil {
+			return Config{}, nil, err
+		}
+	}
+	for i, p := range cfg.Providers {
+		if _, err := p.Interval(); err != nil {
+			return Config{}, nil, fmt.Errorf("provider %s: rate_limit: %w", p.Name, err)
+		}
+		if p.Dir != "" {
+			if cfg.Providers[i].Dir, err = expandPath(p.Dir); err != nil {
+				return Config{}, nil, err
+			}
+		}
+	}
+	return cfg, rest, nil
+}
+
+func Default() (Config, error) {
+	dataDir, err := xdgDir("XDG_DATA_HOME", ".local", "share")
+	if err != nil {
+		return Config{}, err
+	}
+	cacheDir, err := xdgDir("XDG_CACHE_HOME", ".cache")
+	if err != nil {
+		return Config{}, err
+	}
+	stateDir, err := xdgDir("XDG_STATE_HOME", ".local", "state")
+	if err != nil {
+		return Config{}, err
+	}
+	return Config{
+		LibraryDir: filepath.Join(dataDir, "books"),
+		DBPath:     filepath.Join(dataDir, "books.db"),
+		CacheDir:   cacheDir,
+		LogPath:    filepath.Join(stateDir, "kindria.log"),
+	}, nil
+}
+
+func DefaultConfigPath() (string, error) {
+	dir, err := xdgDir("XDG_CONFIG_HOME", ".config")
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.json"), nil
+}
+
+// EnsureDirs creates every directory the app writes into.
+func (c Config) EnsureDirs() error {
+	dirs := []string{c.LibraryDir, c.CoversDir(), filepath.Dir(c.DBPath), filepath.Dir(c.LogPath)}
+	for _, d := range dirs {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *Config) mergeFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var fileCfg Config
+	if err := json.Unmarshal(data, &fileCfg); err != nil {
+		return err
+	}
+	base := filepath.Dir(path)
+	paths := []*string{&fileCfg.LibraryDir, &fileCfg.DBPath, &fileCfg.CacheDir, &fileCfg.LogPath}
+	for i := range fileCfg.Providers {
+		paths = append(paths, &fileCfg.Providers[i].Dir)
+	}
+	for _, p := range paths {
+		if *p != "" && !filepath.IsAbs(*p) && !strings.HasPrefix(*p, "~") {
+			*p = filepath.Join(base, *p)
+		}
+	}
+	c.merge(fileCfg)
+	return nil
+}
+
+func (c *Config) merge(o Config) {
+	if o.LibraryDir != "" {
+		c.LibraryDir = o.LibraryDir
+	}
+	if o.DBPath != "" {
+		c.DBPath = o.DBPath
+	}
+	if o.CacheDir != "" {
+		c.CacheDir = o.CacheDir
+	}
+	if o.LogPath != "" {
+		c.LogPath = o.LogPath
+	}
+	if len(o.Providers) > 0 {
+		c.Providers = o.Providers
+	}
+}
+
+func xdgDir(env string, fallback ...string) (string, error) {
+	if dir := os.Getenv(env); dir != "" {
+		return filepath.Join(dir, "kindria"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(append(append([]string{home}, fallback...), "kindria")...), nil
+}
+
+func expandPath(p string) (string, error) {
+	if p == "~" || strings.HasPrefix(p, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		p = filepath.Join(home, strings.TrimPrefix(p, "~"))
+	}
+	return filepath.Abs(p)
+}
@@ -0,0 +1,249 @@
+package metadata
+
+import (
+	"Kindria/internal/core/db"
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	RoleAuthor      = "aut"
+	RoleContributor = "ctb"
+	roleProducer    = "bkp"
+)
+
+var roleLabels = map[string]string{
+	"aut": "Author",
+	"ctb": "Contributor",
+	"edt": "Editor",
+	"ill": "Illustrator",
+	"nrt": "Narrator",
+	"trl": "Translator",
+	"aui": "Introduction",
+	"aft": "Afterword",
+	"cov": "Cover artist",
+}
+
+// Creator is a dc:creator or dc:contributor element. EPUB2 carries the role
+// and sort name as opf attributes, EPUB3 as <meta refines="#id"> elements.
+type Creator struct {
+	ID     string `xml:"id,attr"`
+	Role   string `xml:"role,attr"`
+	FileAs string `xml:"file-as,attr"`
+	Name   string `xml:",chardata"`
+}
+
+type Credit struct {
+	Name   string
+	FileAs string
+	Role   string
+}
+
+type AuthorSummary struct {
+	ID        int64
+	Name      string
+	FileAs    string
+	BookCount int64
+}
+
+type AuthorBook struct {
+	FileName string
+	Title    string
+	Roles    []string
+}
+
+func RoleLabel(role string) string {
+	if label, ok := roleLabels[role]; ok {
+		return label
+	}
+	return role
+}
+
+// refinements groups EPUB3 <meta refines="#id" property="..."> values by the
+// id of the element they refine.
+func (md MetaData) refinements() map[string]map[string]string {
+	refined := make(map[string]map[string]string)
+	for _, m := range md.Metas {
+		if m.Refines == "" || m.Property == "" {
+			continue
+		}
+		id := strings.TrimPrefix(m.Refines, "#")
+		if refined[id] == nil {
+			refined[id] = make(map[string]string)
+		}
+		refined[id][m.Property] = strings.TrimSpace(m.Value)
+	}
+	return refined
+}
+
+func (md *MetaData) resolveCredits() {
+	refined := md.refinements()
+	md.Credits = nil
+	seen := make(map[string]struct{})
+	add := func(list []Creator, defaultRole string) {
+		for _, c := range list {
+			name := strings.Join(strings.Fields(c.Name), " ")
+			if name == "" {
+				continue
+			}
+			role, fileAs := c.Role, c.FileAs
+			if r, ok := refined[c.ID]; ok && c.ID != "" {
+				if role == "" {
+					role = r["role"]
+				}
+				if fileAs == "" {
+					fileAs = r["file-as"]
+				}
+			}
+			role = strings.ToLower(strings.TrimSpace(role))
+			if role == "" {
+				role = defaultRole
+			}
+			if role == roleProducer {
+				continue
+			}
+			key := strings.ToLower(name) + "|" + role
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			md.Credits = append(md.Credits, Credit{Name: name, FileAs: strings.TrimSpace(fileAs), Role: role})
+		}
+	}
+	add(md.Creators, RoleAuthor)
+	add(md.Contributors, RoleContributor)
+	md.Author = strings.Join(md.AuthorNames(), ", ")
+}
+
+// AuthorNames returns the credited authors, falling back to the first credit
+// for books that only list editors or translators.
+func (md MetaData) AuthorNames() []string {
+	names := make([]string, 0, len(md.Credits))
+	for _, c := range md.Credits {
+		if c.Role == RoleAuthor {
+			names = append(names, c.Name)
+		}
+	}
+	if len(names) == 0 && len(md.Credits) > 0 {
+		names = append(names, md.Credits[0].Name)
+	}
+	return names
+}
+
+func (md MetaData) PrimaryAuthor() string {
+	if names := md.AuthorNames(); len(names) > 0 {
+		return names[0]
+	}
+	first, _, _ := strings.Cut(md.Author, ", ")
+	return first
+}
+
+// storedPrimaryAuthor is PrimaryAuthor for a book already in the library,
+// taken from its linked credits so it agrees with the one found at import.
+func storedPrimaryAuthor(ctx context.Context, q *db.Queries, bookID int64, author string) string {
+	md := MetaData{Author: author}
+	rows, err := q.SelectBookAuthors(ctx, bookID)
+	if err != nil {
+		log.Printf("Err reading authors of book %d: %v", bookID, err)
+	}
+	for _, r := range rows {
+		md.Credits = append(md.Credits, Credit{Name: r.Name, FileAs: r.FileAs, Role: r.Role})
+	}
+	return md.PrimaryAuthor()
+}
+
+func storeCredits(ctx context.Context, q *db.Queries, bookID int64, credits []Credit) error {
+	for i, c := range credits {
+		authorID, err := q.UpsertAuthor(ctx, db.UpsertAuthorParams{Name: c.Name, FileAs: c.FileAs})
+		if err != nil {
+			return err
+		}
+		err = q.InsertBookAuthor(ctx, db.InsertBookAuthorParams{
+			BookID:   bookID,
+			AuthorID: authorID,
+			Role:     c.Role,
+			Position: int64(i),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BackfillAuthors links books inserted before the authors table existed. The
+// EPUB is parsed again to recover co-authors and contributors; when the file is
+// gone the stored author string is used as the only credit.
+func (h *Handler) BackfillAuthors() (int, error) {
+	ctx := context.Background()
+	rows, err := h.Queries.SelectBooksWithoutAuthors(ctx)
+	if err != nil {
+		return 0, err
+	}
+	linked := 0
+	for _, row := range rows {
+		var credits []Credit
+		author := row.Author
+		if _, err := os.Stat(filepath.Join(h.LibraryDir, row.FileName)); err == nil {
+			if bookData, err := extractMetadata(h.LibraryDir, row.FileName); err == nil {
+				credits = bookData.Metadata.Credits
+				if bookData.Metadata.Author != "" {
+					author = bookData.Metadata.Author
+				}
+			}
+		}
+		if len(credits) == 0 {
+			if strings.TrimSpace(row.Author) == "" {
+				continue
+			}
+			credits = []Credit{{Name: strings.TrimSpace(row.Author), Role: RoleAuthor}}
+		}
+		if err := storeCredits(ctx, h.Queries, row.ID, credits); err != nil {
+			log.Printf("Err storing authors of %s: %v", row.FileName, err)
+			continue
+		}
+		if author != row.Author {
+			if err := h.Queries.UpdateBookAuthor(ctx, db.UpdateBookAuthorParams{Author: author, ID: row.ID}); err != nil {
+				log.Printf("Err updating author of %s: %v", row.FileName, err)
+			}
+		}
+		linked++
+	}
+	return linked, nil
+}
+
+func (h *Handler) ListAuthors() ([]AuthorSummary, error) {
+	rows, err := h.Queries.ListAuthors(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	authors := make([]AuthorSummary, 0, len(rows))
+	for _, row := range rows {
+		authors = append(authors, AuthorSummary{
+			ID:        row.ID,
+			Name:      row.Name,
+			FileAs:    row.FileAs,
+			BookCount: row.BookCount,
+		})
+	}
+	return authors, nil
+}
+
+func (h *Handler) AuthorBooks(authorID int64) ([]AuthorBook, error) {
+	rows, err := h.Queries.SelectAuthorBooks(context.Background(), authorID)
+	if err != nil {
+		return nil, err
+	}
+	books := make([]AuthorBook, 0, len(rows))
+	for _, row := range rows {
+		books = append(books, AuthorBook{
+			FileName: row.FileName,
+			Title:    row.Title,
+			Roles:    strings.Split(row.Roles, ","),
+		})
+	}
+	return books, nil
+}
@@ -0,0 +1,166 @@
+package metadata
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type Identifier struct {
+	ID     string `xml:"id,attr"`
+	Scheme string `xml:"scheme,attr"`
+	Value  string `xml:",chardata"`
+}
+
+type BookDetail struct {
+	Book        *Package
+	Credits     []Credit
+	Identifiers []Identifier
+	CoverPath   string
+	Cover       StoredCover
+	Progress    Progress
+	Sessions    []Session
+	FilePath    string
+	FileSize    int64
+	PublishYear int64
+	PageCount   int64
+	ISBNs       []string
+}
+
+var identifierPrefixes = []struct {
+	prefix string
+	scheme string
+}{
+	{"urn:isbn:", "ISBN"},
+	{"isbn:", "ISBN"},
+	{"urn:uuid:", "UUID"},
+	{"uuid:", "UUID"},
+	{"urn:doi:", "DOI"},
+	{"doi:", "DOI"},
+	{"urn:amazon:asin:", "ASIN"},
+	{"asin:", "ASIN"},
+}
+
+// resolveIdentifiers normalises dc:identifier elements into a scheme and a bare
+// value. The scheme comes from opf:scheme, an EPUB3 identifier-type refinement
+// or a URN prefix, in that order.
+func (md *MetaData) resolveIdentifiers() {
+	refined := md.refinements()
+	out := make([]Identifier, 0, len(md.Identifiers))
+	seen := make(map[string]struct{}, len(md.Identifiers))
+	for _, id := range md.Identifiers {
+		value := strings.TrimSpace(id.Value)
+		if value == "" {
+			continue
+		}
+		scheme := strings.TrimSpace(id.Scheme)
+		if scheme == "" && id.ID != "" {
+			scheme = refined[id.ID]["identifier-type"]
+		}
+		lower := strings.ToLower(value)
+		for _, p := range identifierPrefixes {
+			if strings.HasPrefix(lower, p.prefix) {
+				value = strings.TrimSpace(value[len(p.prefix):])
+				if scheme == "" {
+					scheme = p.scheme
+				}
+				break
+			}
+		}
+		if scheme == "" && looksLikeISBN(value) {
+			scheme = "ISBN"
+		}
+		if scheme == "" {
+			scheme = "ID"
+		}
+		scheme = strings.ToUpper(scheme)
+		key := scheme + "|" + strings.ToLower(value)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		out = append(out, Identifier{ID: id.ID, Scheme: scheme, Value: value})
+	}
+	md.Identifiers = out
+}
+
+func looksLikeISBN(v string) bool {
+	digits := 0
+	for i, r := range v {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case r == '-' || r == ' ':
+		case (r == 'X' || r == 'x') && i == len(v)-1:
+			digits++
+		default:
+			return false
+		}
+	}
+	return digits == 10 || digits == 13
+}
+
+// BookDetail gathers everything the detail screen shows. Identifiers are not
+// stored in the database, so they are read from the EPUB when it is present.
+func (h *Handler) BookDetail(fileName string) (*BookDetail, error) {
+	ctx := context.Background()
+	row, err := h.Queries.SelectBookByFileName(ctx, fileName)
+	if err != nil {
+		return nil, err
+	}
+	detail := &BookDetail{
+		Book: &Package{
+			ID: row.ID,
+			Metadata: MetaData{
+				Title:       row.Title,
+				Author:      row.Author,
+				Description: row.Description,
+				Genres:      normalizeGenres(strings.Split(row.Genres, ",")),
+				Language:    row.Language,
+				Series:      row.Series.String,
+				SeriesIndex: row.SeriesIndex,
+			},
+			BookFile:    row.FileName,
+			Rating:      row.Rating.Float64,
+			Status:      row.Status,
+			ReadingDate: row.ReadingDate,
+			AddedAt:     row.AddedAt,
+		},
+		CoverPath:   row.Bookpath,
+		FilePath:    filepath.Join(h.LibraryDir, row.FileName),
+		PublishYear: row.PublishYear,
+		PageCount:   row.PageCount,
+	}
+	if row.Isbns != "" {
+		detail.ISBNs = strings.Split(row.Isbns, ",")
+	}
+
+	if c, err := h.Queries.SelectCover(ctx, row.ID); err == nil {
+		detail.Cover = StoredCover{Path: c.Path, Hash: c.Hash, Format: c.Format, Source: c.Source, Width: int(c.Width), Height: int(c.Height), Pinned: c.Pinned == 1}
+	}
+	detail.Progress = Progress{Chapter: -1}
+	if p, err := h.Queries.SelectProgress(ctx, row.ID); err == nil {
+		detail.Progress = progressFromRow(p)
+		detail.Book.Progress = p.Percent
+	}
+	if detail.Sessions, err = loadSessions(ctx, h.Queries, row.ID); err != nil {
+		return nil, err
+	}
+
+	credits, err := h.Queries.SelectBookAuthors(ctx, row.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range credits {
+		detail.Credits = append(detail.Credits, Credit{Name: c.Name, FileAs: c.FileAs, Role: c.Role})
+	}
+
+	if info, err := os.Stat(detail.FilePath); err == nil {
+		detail.FileSize = info.Size()
+		if bookData, err := extractMetadata(h.LibraryDir, row.FileName); err == nil {
+			detail.Identifiers = bookData.Metadata.Identifiers
+		}
+	}
+	return detail, nil
+}
@@ -0,0 +1,249 @@
+package metadata
+
+import (
+	"Kindria/internal/core/db"
+	"Kindria/internal/utils"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// DuplicatePolicy decides what an import does with a book whose title and
+// author match one already in the library. Byte-identical files are always
+// skipped.
+type DuplicatePolicy string
+
+const (
+	DuplicateAsk     DuplicatePolicy = "ask"
+	DuplicateSkip    DuplicatePolicy = "skip"
+	DuplicateKeep    DuplicatePolicy = "keep"
+	DuplicateReplace DuplicatePolicy = "replace"
+)
+
+func ParseDuplicatePolicy(s string) (DuplicatePolicy, error) {
+	switch p := DuplicatePolicy(strings.ToLower(strings.TrimSpace(s))); p {
+	case DuplicateSkip, DuplicateKeep, DuplicateReplace:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown duplicate policy %q (want skip, keep or replace)", s)
+}
+
+// Conflict is a file left out of an import with DuplicateAsk because it looks
+// like a book that is already in the library.
+type Conflict struct {
+	Path          string
+	Title         string
+	ExistingFile  string
+	ExistingTitle string
+}
+
+// FileHash returns the hex SHA-256 of a file.
+func FileHash(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	sum := sha256.New()
+	if _, err := io.Copy(sum, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(sum.Sum(nil)), nil
+}
+
+var foldReplacer = strings.NewReplacer(
+	"à", "a", "á", "a", "â", "a", "ã", "a", "ä", "a", "å", "a", "æ", "ae",
+	"ç", "c", "è", "e", "é", "e", "ê", "e", "ë", "e",
+	"ì", "i", "í", "i", "î", "i", "ï", "i", "ñ", "n",
+	"ò", "o", "ó", "o", "ô", "o", "õ", "o", "ö", "o", "ø", "o", "œ", "oe",
+	"ù", "u", "ú", "u", "û", "u", "ü", "u", "ý", "y", "ÿ", "y", "ß", "ss",
+)
+
+func keyWords(s string) []string {
+	s = foldReplacer.Replace(strings.ToLower(s))
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// DedupKey normalizes a title and its primary author so that two editions of
+// the same book compare equal: case, accents and punctuation are ignored and
+// the author's words are sorted, which makes "Herbert, Frank" match
+// "Frank Herbert". Books without a title get no key.
+func DedupKey(title, author string) string {
+	t := keyWords(title)
+	if len(t) == 0 {
+		return ""
+	}
+	a := keyWords(author)
+	sort.Strings(a)
+	return strings.Join(t, " ") + "|" + strings.Join(a, " ")
+}
+
+// uniqueFileName returns name, or name with a numeric suffix when it is
+// already taken.
+func uniqueFileName(taken map[string]struct{}, name string) string {
+	if _, ok := taken[name]; !ok {
+		return name
+	}
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s (%d)%s", base, i, ext)
+		if _, ok := taken[candidate]; !ok {
+			return candidate
+		}
+	}
+}
+
+// replaceBook swaps the file of an existing book for src and re-reads its
+// metadata. Rating, status and reading date are kept; the old file goes to
+// TrashDir.
+func (h *Handler) replaceBook(existing db.Book, src, hash string, taken map[string]struct{}) error {
+	ctx := context.Background()
+	name := filepath.Base(src)
+	sameName := name == existing.FileName
+	var trashed string
+	if sameName {
+		dest, err := moveToTrash(h.LibraryDir, existing.FileName)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		trashed = dest
+	} else {
+		name = uniqueFileName(taken, name)
+	}
+	restore := func() {
+		if trashed != "" {
+			os.Rename(trashed, filepath.Join(h.LibraryDir, existing.FileName))
+		}
+	}
+	dest := filepath.Join(h.LibraryDir, name)
+	if err := utils.CopyFile(src, dest); err != nil {
+		restore()
+		return err
+	}
+	fail := func(err error) error {
+		if !sameName {
+			os.Remove(dest)
+		}
+		restore()
+		return err
+	}
+
+	bookData, err := extractMetadata(h.LibraryDir, name)
+	if err != nil {
+		return fail(err)
+	}
+	// A cover picked by hand survives the new file.
+	var cover StoredCover
+	if current, err := h.Queries.SelectCover(ctx, existing.ID); err == nil && current.Pinned == 1 {
+		cover = StoredCover{Path: current.Path, Pinned: true}
+	} else if cover, err = h.CM.ProcessCover(bookData); err != nil {
+		log.Printf("Error trying to get cover path: %v", err)
+	}
+	md := bookData.Metadata
+
+	tx, err := h.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return fail(err)
+	}
+	defer tx.Rollback()
+	qtx := h.Queries.WithTx(tx)
+	err = qtx.ReplaceBookFile(ctx, db.ReplaceBookFileParams{
+		Title:       md.Title,
+		Author:      md.Author,
+		Description: md.Description,
+		Genres:      strings.Join(normalizeGenres(md.Genres), ","),
+		Language:    md.Language,
+		FileName:    name,
+		Bookpath:    cover.Path,
+		Series:      sql.NullString{String: md.Series, Valid: true},
+		SeriesIndex: md.SeriesIndex,
+		ContentHash: hash,
+		DedupKey:    DedupKey(md.Title, md.PrimaryAuthor()),
+		ID:          existing.ID,
+	})
+	if err != nil {
+		return fail(err)
+	}
+	switch {
+	case cover.Pinned:
+		// Still recorded as it was.
+	case cover.Path != "":
+		err = recordCover(ctx, qtx, existing.ID, cover)
+	default:
+		if err = qtx.DeleteCover(ctx, existing.ID); err == nil {
+			err = queueCover(ctx, qtx, existing.ID)
+		}
+	}
+	if err != nil {
+		return fail(err)
+	}
+	if err := qtx.DeleteBookAuthors(ctx, existing.ID); err != nil {
+		return fail(err)
+	}
+	if err := storeCredits(ctx, qtx, existing.ID, md.Credits); err != nil {
+		return fail(err)
+	}
+	if err := qtx.DeleteOrphanAuthors(ctx); err != nil {
+		return fail(err)
+	}
+	if !sameName {
+		if _, err := moveToTrash(h.LibraryDir, existing.FileName); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fail(err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	taken[name] = struct{}{}
+	if existing.Bookpath != cover.Path {
+		h.removeUnusedCover(existing.Bookpath)
+	}
+	if cover.Path == "" {
+		h.CM.wakeWorker()
+	}
+	return nil
+}
+
+// BackfillHashes fingerprints books inserted before duplicate detection
+// existed. Books whose file is missing only get a dedup key.
+func (h *Handler) BackfillHashes() (int, error) {
+	ctx := context.Background()
+	rows, err := h.Queries.SelectBooksWithoutHash(ctx)
+	if err != nil {
+		return 0, err
+	}
+	hashed := 0
+	for _, row := range rows {
+		hash, err := FileHash(filepath.Join(h.LibraryDir, row.FileName))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("Err hashing %s: %v", row.FileName, err)
+			continue
+		}
+		err = h.Queries.UpdateBookFingerprint(ctx, db.UpdateBookFingerprintParams{
+			ContentHash: hash,
+			DedupKey:    DedupKey(row.Title, storedPrimaryAuthor(ctx, h.Queries, row.ID, row.Author)),
+			ID:          row.ID,
+		})
+		if err != nil {
+			log.Printf("Err updating fingerprint of %s: %v", row.FileName, err)
+			continue
+		}
+		if hash != "" {
+			hashed++
+		}
+	}
+	return hashed, nil
+}
@@ -0,0 +1,304 @@
+package metadata
+
+import (
+	"Kindria/internal/core/db"
+	"Kindria/internal/core/platform/storage"
+	"archive/zip"
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+// newTestHandler returns a handler on an empty library folder and a migrated
+// in-memory database.
+func newTestHandler(t *testing.T) *Handler {
+	t.Helper()
+	database, err := sql.Open("sqlite", ":memory:?_pragma=foreign_keys(1)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Every connection would get its own in-memory database.
+	database.SetMaxOpenConns(1)
+	t.Cleanup(func() { database.Close() })
+	if _, err := storage.Migrate(database); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	library := filepath.Join(dir, "books")
+	if err := os.Mkdir(library, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return &Handler{
+		Queries:    db.New(database),
+		DB:         database,
+		CM:         NewCoverManager(library, filepath.Join(dir, "covers")),
+		LibraryDir: library,
+	}
+}
+
+// writeTestEPUB writes a minimal EPUB; text goes in its only chapter, so
+// books with the same title and author can still differ in content.
+func writeTestEPUB(t *testing.T, dir, name, title, author, text string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	files := []struct{ name, body string }{
+		{"mimetype", "application/epub+zip"},
+		{"META-INF/container.xml", `<?xml version="1.0"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles><rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/></rootfiles>
+</container>`},
+		{"OEBPS/content.opf", fmt.Sprintf(`<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="id">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:title>%s</dc:title>
+    <dc:creator>%s</dc:creator>
+    <dc:language>en</dc:language>
+  </metadata>
+  <manifest><item id="c1" href="c1.xhtml" media-type="application/xhtml+xml"/></manifest>
+</package>`, title, author)},
+		{"OEBPS/c1.xhtml", "<html><body><p>" + text + "</p></body></html>"},
+	}
+	zw := zip.NewWriter(f)
+	for _, file := range files {
+		w, err := zw.Create(file.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(file.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func libraryFiles(t *testing.T, h *Handler) []string {
+	t.Helper()
+	names, err := h.Queries.SelectFileNames(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestDedupKey(t *testing.T) {
+	tests := []struct {
+		title, author string
+		want          string
+	}{
+		{"Dune", "Frank Herbert", "dune|frank herbert"},
+		{"  DUNE! ", "Herbert, Frank", "dune|frank herbert"},
+		{"Cien años de soledad", "Gabriel García Márquez", "cien anos de soledad|gabriel garcia marquez"},
+		{"The Hobbit", "", "the hobbit|"},
+		{"", "Frank Herbert", ""},
+		{"...", "Frank Herbert", ""},
+	}
+	for _, tt := range tests {
+		if got := DedupKey(tt.title, tt.author); got != tt.want {
+			t.Errorf("DedupKey(%q, %q) = %q, want %q", tt.title, tt.author, got, tt.want)
+		}
+	}
+}
+
+func TestImportFilesDuplicatePolicy(t *testing.T) {
+	tests := []struct {
+		policy     DuplicatePolicy
+		name       string
+		wantFiles  []string
+		wantResult [3]int // inserted, replaced, duplicated
+		wantTrash  bool
+	}{
+		{DuplicateSkip, "dune.epub", []string{"dune.epub"}, [3]int{0, 0, 1}, false},
+		{DuplicateKeep, "dune.epub", []string{"dune (2).epub", "dune.epub"}, [3]int{1, 0, 0}, false},
+		{DuplicateReplace, "dune.epub", []string{"dune.epub"}, [3]int{0, 1, 0}, true},
+		{DuplicateReplace, "dune-2e.epub", []string{"dune-2e.epub"}, [3]int{0, 1, 0}, true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy)+" "+tt.name, func(t *testing.T) {
+			h := newTestHandler(t)
+			src := t.TempDir()
+			first := writeTestEPUB(t, src, "dune.epub", "Dune", "Frank Herbert", "first edition")
+			if _, err := h.ImportFiles([]string{first}, DuplicateSkip); err != nil {
+				t.Fatal(err)
+			}
+			if err := h.UpdateBookRating(4.5, "dune.epub"); err != nil {
+				t.Fatal(err)
+			}
+			original, err := h.Queries.SelectBookByFileName(context.Background(), "dune.epub")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			second := writeTestEPUB(t, t.TempDir(), tt.name, "DUNE", "Herbert, Frank", "second edition")
+			res, err := h.ImportFiles([]string{second}, tt.policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := [3]int{len(res.Inserted), len(res.Replaced), res.Duplicated}
+			if got != tt.wantResult {
+				t.Errorf("inserted, replaced, duplicated = %v, want %v", got, tt.wantResult)
+			}
+			if files := libraryFiles(t, h); fmt.Sprint(files) != fmt.Sprint(tt.wantFiles) {
+				t.Errorf("library = %v, want %v", files, tt.wantFiles)
+			}
+			_, err = os.Stat(filepath.Join(h.LibraryDir, TrashDir, "dune.epub"))
+			if trashed := err == nil; trashed != tt.wantTrash {
+				t.Errorf("old file in trash = %v, want %v", trashed, tt.wantTrash)
+			}
+			if tt.policy != DuplicateReplace {
+				return
+			}
+			// The row is reused, so the rating stays with the book.
+			row, err := h.Queries.SelectBookByFileName(context.Background(), tt.name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if row.ID != original.ID || row.Rating.Float64 != 4.5 {
+				t.Errorf("replaced row = id %d rating %v, want id %d rating 4.5", row.ID, row.Rating.Float64, original.ID)
+			}
+			if hash, _ := FileHash(second); row.ContentHash != hash {
+				t.Errorf("content hash = %s, want %s", row.ContentHash, hash)
+			}
+		})
+	}
+}
+
+func TestImportFilesDuplicatesInBatch(t *testing.T) {
+	tests := []struct {
+		policy    DuplicatePolicy
+		wantFiles []string
+		wantTitle string
+	}{
+		{DuplicateSkip, []string{"a.epub"}, "Dune"},
+		{DuplicateKeep, []string{"a.epub", "b.epub"}, "Dune"},
+		{DuplicateReplace, []string{"b.epub"}, "DUNE"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			h := newTestHandler(t)
+			src := t.TempDir()
+			files := []string{
+				writeTestEPUB(t, src, "a.epub", "Dune", "Frank Herbert", "first edition"),
+				writeTestEPUB(t, src, "b.epub", "DUNE", "Herbert, Frank", "second edition"),
+				writeTestEPUB(t, src, "c.epub", "Dune", "Frank Herbert", "first edition"),
+			}
+			res, err := h.ImportFiles(files, tt.policy)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := libraryFiles(t, h); fmt.Sprint(got) != fmt.Sprint(tt.wantFiles) {
+				t.Errorf("library = %v, want %v", got, tt.wantFiles)
+			}
+			if len(res.Inserted) != len(tt.wantFiles) {
+				t.Errorf("inserted %v, want %d files", res.Inserted, len(tt.wantFiles))
+			}
+			// c.epub is a byte-identical copy of a.epub.
+			if res.Duplicated != 3-len(tt.wantFiles) {
+				t.Errorf("duplicated = %d, want %d", res.Duplicated, 3-len(tt.wantFiles))
+			}
+			row, err := h.Queries.SelectBookByFileName(context.Background(), tt.wantFiles[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if row.Title != tt.wantTitle {
+				t.Errorf("title = %q, want %q", row.Title, tt.wantTitle)
+			}
+		})
+	}
+}
+
+func TestImportFilesAskReportsConflicts(t *testing.T) {
+	h := newTestHandler(t)
+	src := t.TempDir()
+	files := []string{
+		writeTestEPUB(t, src, "a.epub", "Dune", "Frank Herbert", "first edition"),
+		writeTestEPUB(t, src, "b.epub", "Dune", "Frank Herbert", "second edition"),
+	}
+	res, err := h.ImportFiles(files, DuplicateAsk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Conflicts) != 1 || res.Conflicts[0].Path != files[1] || res.Conflicts[0].ExistingFile != "a.epub" {
+		t.Errorf("conflicts = %+v, want b.epub against a.epub", res.Conflicts)
+	}
+	if got := libraryFiles(t, h); fmt.Sprint(got) != "[a.epub]" {
+		t.Errorf("library = %v, want [a.epub]", got)
+	}
+}
+
+func TestUpdateMetadataKeepsContentHash(t *testing.T) {
+	h := newTestHandler(t)
+	src := writeTestEPUB(t, t.TempDir(), "rose.epub", "Bloody Rose", "Nicholas Eames", "text")
+	if _, err := h.ImportFiles([]string{src}, DuplicateSkip); err != nil {
+		t.Fatal(err)
+	}
+	edit := MetadataEdit{Title: "Bloody Rose Edited", Authors: []string{"Nicholas Eames"}}
+	if err := h.UpdateMetadata("rose.epub", edit, true); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(h.LibraryDir, "rose.epub")
+	row, err := h.Queries.SelectBookByFileName(context.Background(), "rose.epub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash, _ := FileHash(path); row.ContentHash != hash {
+		t.Fatalf("content hash = %s, file hashes to %s", row.ContentHash, hash)
+	}
+
+	// A renamed file is relinked by its hash instead of inserted again.
+	if err := os.Rename(path, filepath.Join(h.LibraryDir, "renamed.epub")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.InsertBooks(); err != nil {
+		t.Fatal(err)
+	}
+	if got := libraryFiles(t, h); fmt.Sprint(got) != "[renamed.epub]" {
+		t.Errorf("library = %v, want [renamed.epub]", got)
+	}
+	entries, err := os.ReadDir(h.LibraryDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("library folder has %d entries, want only the book", len(entries))
+	}
+}
+
+func TestBackfillHashesMatchesImportKey(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	src := writeTestEPUB(t, t.TempDir(), "dune.epub", "Dune", "Herbert, Frank", "text")
+	if _, err := h.ImportFiles([]string{src}, DuplicateSkip); err != nil {
+		t.Fatal(err)
+	}
+	imported, err := h.Queries.SelectBookByFileName(ctx, "dune.epub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Rows from before duplicate detection had neither column.
+	if _, err := h.DB.Exec("UPDATE books SET content_hash = '', dedup_key = ''"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.BackfillHashes(); err != nil {
+		t.Fatal(err)
+	}
+	row, err := h.Queries.SelectBookByFileName(ctx, "dune.epub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if row.DedupKey != imported.DedupKey || row.ContentHash != imported.ContentHash {
+		t.Errorf("backfilled key %q hash %s, want %q %s", row.DedupKey, row.ContentHash, imported.DedupKey, imported.ContentHash)
+	}
+}
@@ -0,0 +1,172 @@
+package metadata
+
+import (
+	"Kindria/internal/core/db"
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MetadataEdit holds the user-editable fields of a book. Authors replaces the
+// "aut" credits only; translators, editors and other contributors are kept.
+type MetadataEdit struct {
+	Title       string
+	Authors     []string
+	Genres      []string
+	Language    string
+	Description string
+	Series      string
+	SeriesIndex float64
+}
+
+func (md MetaData) Edit() MetadataEdit {
+	var authors []string
+	for _, c := range md.Credits {
+		if c.Role == RoleAuthor {
+			authors = append(authors, c.Name)
+		}
+	}
+	if len(md.Credits) == 0 && md.Author != "" {
+		authors = strings.Split(md.Author, ", ")
+	}
+	return MetadataEdit{
+		Title:       md.Title,
+		Authors:     authors,
+		Genres:      md.Genres,
+		Language:    md.Language,
+		Description: md.Description,
+		Series:      md.Series,
+		SeriesIndex: md.SeriesIndex,
+	}
+}
+
+func (e MetadataEdit) normalize() MetadataEdit {
+	e.Title = strings.Join(strings.Fields(e.Title), " ")
+	e.Language = strings.TrimSpace(e.Language)
+	e.Description = strings.TrimSpace(e.Description)
+	e.Series = strings.Join(strings.Fields(e.Series), " ")
+	if e.Series == "" || e.SeriesIndex < 0 {
+		e.SeriesIndex = 0
+	}
+	e.Genres = normalizeGenres(e.Genres)
+	authors := make([]string, 0, len(e.Authors))
+	seen := make(map[string]struct{}, len(e.Authors))
+	for _, a := range e.Authors {
+		a = strings.Join(strings.Fields(a), " ")
+		if _, ok := seen[strings.ToLower(a)]; ok || a == "" {
+			continue
+		}
+		seen[strings.ToLower(a)] = struct{}{}
+		authors = append(authors, a)
+	}
+	e.Authors = authors
+	return e
+}
+
+// UpdateMetadata stores the edited fields and re-links the book's authors in a
+// single transaction. With writeEPUB the OPF inside the .epub is rewritten as
+// well, so the change survives a re-import; if that fails nothing is saved.
+func (h *Handler) UpdateMetadata(fileName string, edit MetadataEdit, writeEPUB bool) error {
+	edit = edit.normalize()
+	if edit.Title == "" {
+		return errors.New("title cannot be empty")
+	}
+	ctx := context.Background()
+	row, err := h.Queries.SelectBookByFileName(ctx, fileName)
+	if err != nil {
+		return err
+	}
+	current, err := h.Queries.SelectBookAuthors(ctx, row.ID)
+	if err != nil {
+		return err
+	}
+
+	fileAs := make(map[string]string, len(current))
+	for _, c := range current {
+		if c.Role == RoleAuthor {
+			fileAs[strings.ToLower(c.Name)] = c.FileAs
+		}
+	}
+	credits := make([]Credit, 0, len(edit.Authors)+len(current))
+	for _, a := range edit.Authors {
+		credits = append(credits, Credit{Name: a, FileAs: fileAs[strings.ToLower(a)], Role: RoleAuthor})
+	}
+	authors := credits
+	for _, c := range current {
+		if c.Role != RoleAuthor {
+			credits = append(credits, Credit{Name: c.Name, FileAs: c.FileAs, Role: c.Role})
+		}
+	}
+
+	primary := ""
+	if len(edit.Authors) > 0 {
+		primary = edit.Authors[0]
+	}
+
+	tx, err := h.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	qtx := h.Queries.WithTx(tx)
+
+	err = qtx.UpdateBookMetadata(ctx, db.UpdateBookMetadataParams{
+		Title:       edit.Title,
+		Author:      strings.Join(edit.Authors, ", "),
+		Description: edit.Description,
+		Genres:      strings.Join(edit.Genres, ","),
+		Language:    edit.Language,
+		Series:      sql.NullString{String: edit.Series, Valid: true},
+		SeriesIndex: edit.SeriesIndex,
+		DedupKey:    DedupKey(edit.Title, primary),
+		ID:          row.ID,
+	})
+	if err != nil {
+		return err
+	}
+	if err := qtx.DeleteBookAuthors(ctx, row.ID); err != nil {
+		return err
+	}
+	if err := storeCredits(ctx, qtx, row.ID, credits); err != nil {
+		return err
+	}
+	if err := qtx.DeleteOrphanAuthors(ctx); err != nil {
+		return err
+	}
+
+	if !writeEPUB {
+		return tx.Commit()
+	}
+	// The edited copy replaces the book only after the new hash is committed;
+	// if it cannot, the old hash is put back so the file and its content_hash
+	// never disagree.
+	epubPath := filepath.Join(h.LibraryDir, fileName)
+	tmp, err := writeEPUBMetadata(epubPath, edit, authors)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+	hash, err := FileHash(tmp)
+	if err != nil {
+		return err
+	}
+	err = qtx.UpdateBookFingerprint(ctx, db.UpdateBookFingerprintParams{ContentHash: hash, DedupKey: DedupKey(edit.Title, primary), ID: row.ID})
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, epubPath); err != nil {
+		restore := db.UpdateBookFingerprintParams{ContentHash: row.ContentHash, DedupKey: DedupKey(edit.Title, primary), ID: row.ID}
+		if err := h.Queries.UpdateBookFingerprint(ctx, restore); err != nil {
+			log.Printf("Err restoring the hash of %s: %v", fileName, err)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package metadata
+
+import (
+	"Kindria/internal/core/db"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	GoalBooks = "books"
+	GoalPages = "pages"
+)
+
+// Goal is a reading goal for a year: the yearly goal has no name, challenges
+// have one and count only the books matching their language and genre.
+// Progress and CompletedAt are counted from the finished reads of the year
+// every time the goals are read and never stored.
+type Goal struct {
+	Year        int    `json:"year"`
+	Name        string `json:"name"`
+	Unit        string `json:"unit"`
+	Target      int64  `json:"target"`
+	Language    string `json:"language"`
+	Genre       string `json:"genre"`
+	Progress    int64  `json:"progress"`
+	CompletedAt string `json:"completed_at"`
+}
+
+// Label is how the goal is called on screen.
+func (g Goal) Label() string {
+	if g.Name == "" {
+		return strconv.Itoa(g.Year) + " goal"
+	}
+	return g.Name
+}
+
+// Matches reports whether a finished read counts towards the goal. A
+// language matches its regional variants ("es" matches "es-MX").
+func (g Goal) Matches(r LoggedRead) bool {
+	if g.Language != "" {
+		language := strings.ToLower(strings.TrimSpace(r.Language))
+		want := strings.ToLower(g.Language)
+		if language != want && !strings.HasPrefix(language, want+"-") {
+			return false
+		}
+	}
+	if g.Genre == "" {
+		return true
+	}
+	for _, genre := range r.Genres {
+		if strings.EqualFold(genre, g.Genre) {
+			return true
+		}
+	}
+	return false
+}
+
+// Ahead is how far the goal is ahead of an even pace through its year at now,
+// in its unit; it is negative when behind. Past years are held to the whole
+// target and future ones to nothing.
+func (g Goal) Ahead(now time.Time) int64 {
+	expected := 0.0
+	switch {
+	case now.Year() > g.Year:
+		expected = float64(g.Target)
+	case now.Year() == g.Year:
+		days := time.Date(g.Year, 12, 31, 0, 0, 0, 0, time.Local).YearDay()
+		expected = float64(g.Target) * float64(now.YearDay()) / float64(days)
+	}
+	return g.Progress - int64(expected)
+}
+
+// Pace describes the goal against the calendar, like "3 books behind
+// schedule".
+func (g Goal) Pace(now time.Time) string {
+	if g.CompletedAt != "" {
+		return "completed on " + g.CompletedAt
+	}
+	ahead := g.Ahead(now)
+	switch {
+	case now.Year() > g.Year:
+		return "missed by " + g.amount(-ahead)
+	case ahead > 0:
+		return g.amount(ahead) + " ahead of schedule"
+	case ahead < 0:
+		return g.amount(-ahead) + " behind schedule"
+	}
+	return "on track"
+}
+
+func (g Goal) amount(n int64) string {
+	unit := strings.TrimSuffix(g.Unit, "s")
+	if n != 1 {
+		unit += "s"
+	}
+	return strconv.FormatInt(n, 10) + " " + unit
+}
+
+// SetGoal adds a goal, or replaces the one with the same year and name.
+func (h *Handler) SetGoal(g Goal) error {
+	if g.Unit != GoalBooks && g.Unit != GoalPages {
+		return fmt.Errorf("invalid goal unit %q: expected %s or %s", g.Unit, GoalBooks, GoalPages)
+	}
+	if g.Target <= 0 {
+		return fmt.Errorf("invalid goal target %d: expected a positive number", g.Target)
+	}
+	return h.Queries.UpsertGoal(context.Background(), db.UpsertGoalParams{
+		Year:     int64(g.Year),
+		Name:     strings.TrimSpace(g.Name),
+		Unit:     g.Unit,
+		Target:   g.Target,
+		Language: strings.TrimSpace(g.Language),
+		Genre:    strings.TrimSpace(g.Genre),
+	})
+}
+
+// RemoveGoal deletes a goal; an empty name is the yearly goal.
+func (h *Handler) RemoveGoal(year int, name string) error {
+	n, err := h.Queries.DeleteGoal(context.Background(), db.DeleteGoalParams{Year: int64(year), Name: strings.TrimSpace(name)})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("no goal %q for %d", name, year)
+	}
+	return nil
+}
+
+// Goals returns the goals of a year, the yearly goal first, with their
+// progress counted from the year's finished reads.
+func (h *Handler) Goals(year int) ([]Goal, error) {
+	ctx := context.Background()
+	rows, err := h.Queries.SelectGoals(ctx, int64(year))
+	if err != nil || len(rows) == 0 {
+		return nil, err
+	}
+	stats, err := h.ReadingStats(year)
+	if err != nil {
+		return nil, err
+	}
+	goals := make([]Goal, 0, len(rows))
+	for _, row := range rows {
+		g := Goal{
+			Year:     int(row.Year),
+			Name:     row.Name,
+			Unit:     row.Unit,
+			Target:   row.Target,
+			Language: row.Language,
+			Genre:    row.Genre,
+		}
+		for _, r := range stats.Log {
+			if !g.Matches(r) {
+				continue
+			}
+			if g.Unit == GoalPages {
+				g.Progress += r.Pages
+			} else {
+				g.Progress++
+			}
+			if g.CompletedAt == "" && g.Progress >= g.Target {
+				g.CompletedAt = r.FinishedAt
+			}
+		}
+		goals = append(goals, g)
+	}
+	return goals, nil
+}
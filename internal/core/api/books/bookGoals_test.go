@@ -0,0 +1,53 @@
+package metadata
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGoalsCountFinishedReads(t *testing.T) {
+	h := newTestHandler(t)
+	src := t.TempDir()
+	files := []string{
+		writeTestEPUB(t, src, "dune.epub", "Dune", "Frank Herbert", "one"),
+		writeTestEPUB(t, src, "emma.epub", "Emma", "Jane Austen", "two"),
+	}
+	if _, err := h.ImportFiles(files, DuplicateSkip); err != nil {
+		t.Fatal(err)
+	}
+	year := time.Now().Year()
+	for _, g := range []Goal{
+		{Year: year, Unit: GoalBooks, Target: 2},
+		{Year: year, Name: "Spanish", Unit: GoalBooks, Target: 1, Language: "es"},
+	} {
+		if err := h.SetGoal(g); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	steps := []struct {
+		file      string
+		progress  []int64
+		completed []bool
+	}{
+		{"dune.epub", []int64{1, 0}, []bool{false, false}},
+		{"emma.epub", []int64{2, 0}, []bool{true, false}},
+	}
+	for _, s := range steps {
+		if _, _, err := h.UpdateBookStatus("Read", s.file); err != nil {
+			t.Fatal(err)
+		}
+		goals, err := h.Goals(year)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(goals) != 2 || goals[0].Name != "" {
+			t.Fatalf("goals = %+v, want the yearly goal and one challenge", goals)
+		}
+		for i, g := range goals {
+			if g.Progress != s.progress[i] || (g.CompletedAt != "") != s.completed[i] {
+				t.Errorf("after %s: %s = %d completed %q, want %d completed %v", s.file, g.Label(), g.Progress, g.CompletedAt, s.progress[i], s.completed[i])
+			}
+		}
+	}
+}
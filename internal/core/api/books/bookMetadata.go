@@ -5,53 +5,72 @@ import (
 	"archive/zip"
 	"context"
 	"database/sql"
-	"encoding/json"
 	"encoding/xml"
-	"fmt"
+	"errors"
 	"io"
 	"log"
 	_ "modernc.org/sqlite"
-	"net/http"
-	"net/url"
 	"os"
 	"path"
+	"path/filepath"
 	"strings"
 	"time"
 )
 
 type CoverManager struct {
-	coversQueue chan *Package
+	wake       chan struct{}
+	updates    chan CoverUpdate
+	libraryDir string
+	coversDir  string
 }
 
-func NewCoverManager() *CoverManager {
+func NewCoverManager(libraryDir, coversDir string) *CoverManager {
 	return &CoverManager{
-		coversQueue: make(chan *Package, 128),
+		wake:       make(chan struct{}, 1),
+		updates:    make(chan CoverUpdate, 64),
+		libraryDir: libraryDir,
+		coversDir:  coversDir,
 	}
 }
 
 type Package struct {
+	ID                int64    `db:"id"`
 	Metadata          MetaData `xml:"metadata"`
 	Manifest          Manifest `xml:"manifest"`
 	Guide             Guide    `xml:"guide"`
+	Spine             Spine    `xml:"spine"`
 	InternalCoverPath string   `json:"cover_path"`
 	BookFile          string   `db:"file_name"`
 	Rating            float64  `db:"rating"`
 	Status            string   `db:"status"`
 	ReadingDate       string   `db:"reading_date"`
+	AddedAt           string   `db:"added_at"`
+	Progress          float64  `db:"percent"`
+	opfPath           string
 }
 
 type MetaData struct {
-	Author      string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
-	Title       string   `xml:"http://purl.org/dc/elements/1.1/ title"`
-	Description string   `xml:"http://purl.org/dc/elements/1.1/ description"`
-	Genres      []string `xml:"http://purl.org/dc/elements/1.1/ subject"`
-	Language    string   `xml:"http://purl.org/dc/elements/1.1/ language"`
-	Metas       []Meta   `xml:"meta" json:"-"`
+	Author       string       `xml:"-"`
+	Credits      []Credit     `xml:"-"`
+	Creators     []Creator    `xml:"http://purl.org/dc/elements/1.1/ creator" json:"-"`
+	Contributors []Creator    `xml:"http://purl.org/dc/elements/1.1/ contributor" json:"-"`
+	Identifiers  []Identifier `xml:"http://purl.org/dc/elements/1.1/ identifier" json:"-"`
+	Title        string       `xml:"http://purl.org/dc/elements/1.1/ title"`
+	Description  string       `xml:"http://purl.org/dc/elements/1.1/ description"`
+	Genres       []string     `xml:"http://purl.org/dc/elements/1.1/ subject"`
+	Language     string       `xml:"http://purl.org/dc/elements/1.1/ language"`
+	Series       string       `xml:"-"`
+	SeriesIndex  float64      `xml:"-"`
+	Metas        []Meta       `xml:"meta" json:"-"`
 }
 
 type Meta struct {
-	Name    string `xml:"name,attr"`
-	Content string `xml:"content,attr"`
+	ID       string `xml:"id,attr"`
+	Name     string `xml:"name,attr"`
+	Content  string `xml:"content,attr"`
+	Refines  string `xml:"refines,attr"`
+	Property string `xml:"property,attr"`
+	Value    string `xml:",chardata"`
 }
 type Manifest struct {
 	Items []Item `xml:"item"`
@@ -60,6 +79,7 @@ type Manifest struct {
 type Item struct {
 	Id         string `xml:"id,attr"`
 	Href       string `xml:"href,attr"`
+	MediaType  string `xml:"media-type,attr"`
 	Properties string `xml:"properties,attr"`
 }
 
@@ -73,33 +93,35 @@ type Reference struct {
 	Title string `xml:"title,attr"`
 }
 
-type Handler struct {
-	Queries *db.Queries
-	DB      *sql.DB
-	CM      *CoverManager
+type Spine struct {
+	Toc      string    `xml:"toc,attr"`
+	ItemRefs []ItemRef `xml:"itemref"`
 }
 
-type jsonWrapper struct {
-	NumFound int     `json:"numFound"`
-	Docs     []OLDoc `json:"docs"`
+type ItemRef struct {
+	IdRef  string `xml:"idref,attr"`
+	Linear string `xml:"linear,attr"`
 }
 
-type OLDoc struct {
-	Cover_i int `json:"cover_i"`
+type Handler struct {
+	Queries    *db.Queries
+	DB         *sql.DB
+	CM         *CoverManager
+	Providers  *Providers
+	LibraryDir string
 }
 
 func (h *Handler) InsertBooks() ([]db.Book, error) {
 	ctx := context.Background()
-	path := "./books/"
 	fileNameMap := make(map[string]bool)
 
-	data, err := os.ReadDir(path)
+	data, err := os.ReadDir(h.LibraryDir)
 	if err != nil {
 		log.Fatal("Err while reading the books folder: ", err)
 		return nil, err
 	}
 
-	insertedJson := make([]db.Book, len(data))
+	insertedJson := make([]db.Book, 0, len(data))
 
 	fnSlice, err := h.Queries.SelectFileNames(ctx)
 	if err != nil {
@@ -118,12 +140,29 @@ func (h *Handler) InsertBooks() ([]db.Book, error) {
 		if e.IsDir() || !strings.HasSuffix(e.Name(), "epub") {
 			continue
 		}
-		bookData, err := extractMetadata(e.Name())
+		hash, err := FileHash(filepath.Join(h.LibraryDir, e.Name()))
+		if err != nil {
+			log.Printf("Err hashing %s: %v", e.Name(), err)
+			continue
+		}
+		if existing, err := h.Queries.SelectBookByContentHash(ctx, hash); err == nil {
+			// A renamed file is relinked; a second copy is left alone.
+			if _, err := os.Stat(filepath.Join(h.LibraryDir, existing.FileName)); !errors.Is(err, os.ErrNotExist) {
+				log.Printf("Skipping %s: same content as %s", e.Name(), existing.FileName)
+				continue
+			}
+			err := h.Queries.UpdateBookFileName(ctx, db.UpdateBookFileNameParams{FileName: e.Name(), ID: existing.ID})
+			if err != nil {
+				log.Printf("Err relinking %s: %v", e.Name(), err)
+			}
+			continue
+		}
+		bookData, err := extractMetadata(h.LibraryDir, e.Name())
 		if err != nil {
 			log.Printf("\nErr extracting data from book: %s | %v", e.Name(), err)
 			continue
 		}
-		coverPath, err := h.CM.ProcessCover(bookData)
+		cover, err := h.CM.ProcessCover(bookData)
 		if err != nil {
 			log.Printf("Error trying to get cover path: %v", err)
 		}
@@ -134,11 +173,29 @@ func (h *Handler) InsertBooks() ([]db.Book, error) {
 			Genres:      strings.Join(normalizeGenres(bookData.Metadata.Genres), ","),
 			Language:    bookData.Metadata.Language,
 			FileName:    bookData.BookFile,
-			Bookpath:    coverPath,
+			Bookpath:    cover.Path,
+			AddedAt:     time.Now().Format("2006-01-02 15:04:05"),
+			Series:      sql.NullString{String: bookData.Metadata.Series, Valid: true},
+			SeriesIndex: bookData.Metadata.SeriesIndex,
+			ContentHash: hash,
+			DedupKey:    DedupKey(bookData.Metadata.Title, bookData.Metadata.PrimaryAuthor()),
 		})
 		if err != nil {
 			return nil, err
 		}
+		for _, b := range booksJson {
+			if err := storeCredits(ctx, h.Queries, b.ID, bookData.Metadata.Credits); err != nil {
+				log.Printf("Err storing authors of %s: %v", b.FileName, err)
+			}
+			if cover.Path == "" {
+				if err := queueCover(ctx, h.Queries, b.ID); err != nil {
+					log.Printf("Err queueing cover of %s: %v", b.FileName, err)
+				}
+				h.CM.wakeWorker()
+			} else if err := recordCover(ctx, h.Queries, b.ID, cover); err != nil {
+				log.Printf("Err storing cover of %s: %v", b.FileName, err)
+			}
+		}
 		insertedJson = append(insertedJson, booksJson...)
 	}
 	return insertedJson, nil
@@ -166,10 +223,9 @@ func normalizeGenres(genres []string) []string {
 	return out
 }
 
-func extractMetadata(src string) (*Package, error) {
-	var BookData Package
-	initialPath := "./books/"
-	r, err := zip.OpenReader(initialPath + src)
+func extractMetadata(libraryDir, src string) (*Package, error) {
+	BookData := Package{BookFile: src}
+	r, err := zip.OpenReader(filepath.Join(libraryDir, src))
 	if err != nil {
 		log.Printf("Err opening .epub file: %v", err)
 		return nil, err
@@ -195,6 +251,10 @@ func extractMetadata(src string) (*Package, error) {
 				log.Printf("Err parsing xml data: %v", err)
 				continue
 			}
+			BookData.opfPath = f.Name
+			BookData.Metadata.resolveCredits()
+			BookData.Metadata.resolveSeries()
+			BookData.Metadata.resolveIdentifiers()
 			baseDir := path.Dir(f.Name)
 			coverID := ""
 			coverGuideHref := ""
@@ -244,68 +304,35 @@ func extractMetadata(src string) (*Package, error) {
 	return &BookData, nil
 }
 
-func SearchOpenLibrary(title, author string) (int, error) {
-	var o jsonWrapper
-
-	u, err := url.Parse("https://openlibrary.org/search.json?title=reyes+de+la+tierra+salvaje&author=Nicholas+Eames&limit=2&offset=0")
-	if err != nil {
-		log.Printf("Error trying to parse base url: %v", err)
-		return 0, err
-	}
-	q := u.Query()
-	q.Set("title", title)
-	q.Set("author", author)
-	u.RawQuery = q.Encode()
-
-	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
-	if err != nil {
-		return 0, err
-	}
-	contact := os.Getenv("OLContact")
-	req.Header.Set("User-Agent", "Kindria/0.1 (contact: "+contact+")")
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return 0, err
-	}
-
-	defer resp.Body.Close()
-
-	err = json.NewDecoder(resp.Body).Decode(&o)
-	if err != nil {
-		return 0, err
-	}
-
-	if o.NumFound == 0 {
-		return 0, err
-	}
-	coverId := o.Docs[0].Cover_i
-	if coverId > 0 {
-		return coverId, nil
-	}
-	return 0, nil
-}
-
 func (h *Handler) SelectBooks() ([]*Package, error) {
 	books := make([]*Package, 0)
 	rows, err := h.Queries.SelectAllBooks(context.Background())
 	if err != nil {
 		return nil, err
 	}
+	progress, err := h.progressByBook(context.Background())
+	if err != nil {
+		return nil, err
+	}
 	for _, row := range rows {
 		genresSlice := normalizeGenres(strings.Split(row.Genres, ","))
 		p := &Package{
+			ID: row.ID,
 			Metadata: MetaData{
 				Title:       row.Title,
 				Author:      row.Author,
 				Description: row.Description,
 				Genres:      genresSlice,
 				Language:    row.Language,
+				Series:      row.Series.String,
+				SeriesIndex: row.SeriesIndex,
 			},
 			BookFile:    row.FileName,
+			Rating:      row.Rating.Float64,
 			Status:      row.Status,
 			ReadingDate: row.ReadingDate,
+			AddedAt:     row.AddedAt,
+			Progress:    progress[row.ID],
 		}
 		books = append(books, p)
 	}
@@ -328,9 +355,11 @@ func (h *Handler) SelectBookInfo() ([]*Package, error) {
 		genresSlice := normalizeGenres(strings.Split(row.Genres, ","))
 		p := &Package{
 			Metadata: MetaData{
-				Title:  row.Title,
-				Author: row.Author,
-				Genres: genresSlice,
+				Title:       row.Title,
+				Author:      row.Author,
+				Genres:      genresSlice,
+				Series:      row.Series.String,
+				SeriesIndex: row.SeriesIndex,
 			},
 			BookFile:    row.FileName,
 			Rating:      finalRating,
@@ -350,69 +379,54 @@ func (h *Handler) SelectBookPath(bookFile string) (string, error) {
 	return path, nil
 }
 
-func (h *Handler) UpdateBookStatus(status, fileName string) (string, error) {
-	readingDate := ""
-	if status == "Read" {
-		readingDate = time.Now().Format("2006-01-02")
+func (h *Handler) SearchBooks(input string) ([]string, error) {
+	query := ftsQuery(input)
+	if query == "" {
+		return nil, nil
 	}
-	err := h.Queries.UpdateStatus(context.Background(), db.UpdateStatusParams{Status: status, ReadingDate: readingDate, FileName: fileName})
-	if err != nil {
-		return "", err
+	return h.Queries.SearchBooks(context.Background(), query)
+}
+
+func ftsQuery(input string) string {
+	terms := strings.Fields(input)
+	parts := make([]string, 0, len(terms))
+	for _, t := range terms {
+		parts = append(parts, `"`+strings.ReplaceAll(t, `"`, `""`)+`"*`)
 	}
-	return readingDate, nil
+	return strings.Join(parts, " ")
 }
 
+// UpdateBookRating rates a book and, when its latest read is finished, that
+// read too.
 func (h *Handler) UpdateBookRating(rating float64, fileName string) error {
-	err := h.Queries.UpdateRating(context.Background(), db.UpdateRatingParams{
+	ctx := context.Background()
+	err := h.Queries.UpdateRating(ctx, db.UpdateRatingParams{
 		Rating:   sql.NullFloat64{Float64: rating, Valid: true},
 		FileName: fileName,
 	})
 	if err != nil {
 		return err
 	}
-	return nil
-}
-
-func (h *Handler) CheckBookExist(filename string) (int64, error) {
-	exists, err := h.Queries.CheckBookExists(context.Background(), filename)
-	if err != nil {
-		return 0, err
-	}
-	return exists, nil
-}
-
-func (h *Handler) EnsureReadingDateColumn() error {
-	rows, err := h.DB.Query("PRAGMA table_info(books)")
+	row, err := h.Queries.SelectBookByFileName(ctx, fileName)
 	if err != nil {
 		return err
 	}
-	defer rows.Close()
-
-	var (
-		cid        int
-		name       string
-		colType    string
-		notNull    int
-		defaultV   sql.NullString
-		primaryKey int
-	)
-	for rows.Next() {
-		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultV, &primaryKey); err != nil {
-			return err
-		}
-		if name == "reading_date" {
-			return nil
-		}
+	latest, err := h.Queries.SelectLatestSession(ctx, row.ID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && latest.FinishedAt == "") {
+		return nil
 	}
-	if err := rows.Err(); err != nil {
+	if err != nil {
 		return err
 	}
+	return h.Queries.UpdateSessionRating(ctx, db.UpdateSessionRatingParams{Rating: rating, ID: latest.ID})
+}
 
-	_, err = h.DB.Exec("ALTER TABLE books ADD reading_date TEXT NOT NULL DEFAULT ''")
+func (h *Handler) CheckBookExist(filename string) (int64, error) {
+	exists, err := h.Queries.CheckBookExists(context.Background(), filename)
 	if err != nil {
-		return fmt.Errorf("alter books add reading_date: %w", err)
+		return 0, err
 	}
-	return nil
+	return exists, nil
 }
 
 func resolveCoverFromXHTML(r *zip.ReadCloser, href string) (string, error) {
@@ -0,0 +1,87 @@
+package metadata
+
+import (
+	"Kindria/internal/core/db"
+	"context"
+	"fmt"
+	"time"
+)
+
+// Progress is how far into a book the reader got. Chapter is the spine index
+// and Offset the fraction of that chapter above the top of the page; both come
+// from the built-in reader, and Chapter is -1 for a manual entry, which only
+// sets Percent.
+type Progress struct {
+	Chapter  int
+	Offset   float64
+	Percent  float64
+	OpenedAt string
+}
+
+// InProgress reports whether a book is being read: its status says so, or it
+// was opened and not finished.
+func InProgress(b *Package) bool {
+	return b.Status == "Currently Reading" || (b.Progress > 0 && b.Progress < 100 && b.Status != "Read" && b.Status != "Archived")
+}
+
+// SaveProgress stores the progress of a book and moves its status along: a
+// book not started yet becomes "Currently Reading" and one being read becomes
+// "Read" at 100%. A Read book read again below 100% is a re-read and gets a
+// new session through "Currently Reading"; archived books keep their status.
+// It returns the status and reading date the book ends up with.
+func (h *Handler) SaveProgress(fileName string, p Progress) (string, string, error) {
+	if p.Percent < 0 || p.Percent > 100 {
+		return "", "", fmt.Errorf("invalid progress %.1f: expected 0 to 100", p.Percent)
+	}
+	ctx := context.Background()
+	row, err := h.Queries.SelectBookByFileName(ctx, fileName)
+	if err != nil {
+		return "", "", err
+	}
+	if p.OpenedAt == "" {
+		p.OpenedAt = time.Now().Format("2006-01-02 15:04:05")
+	}
+	err = h.Queries.UpsertProgress(ctx, db.UpsertProgressParams{
+		BookID:      row.ID,
+		SpineIndex:  int64(p.Chapter),
+		SpineOffset: p.Offset,
+		Percent:     p.Percent,
+		OpenedAt:    p.OpenedAt,
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	status := row.Status
+	switch {
+	case status == "Archived", status == "Read" && (p.Percent <= 0 || p.Percent >= 100):
+		return status, row.ReadingDate, nil
+	case status == "Read":
+		status = "Currently Reading"
+	case p.Percent >= 100:
+		status = "Read"
+	case p.Percent > 0:
+		status = "Currently Reading"
+	}
+	if status == row.Status {
+		return status, row.ReadingDate, nil
+	}
+	return h.UpdateBookStatus(status, fileName)
+}
+
+func progressFromRow(p db.ReadingProgress) Progress {
+	return Progress{Chapter: int(p.SpineIndex), Offset: p.SpineOffset, Percent: p.Percent, OpenedAt: p.OpenedAt}
+}
+
+// progressByBook maps book ids to their progress percentage.
+func (h *Handler) progressByBook(ctx context.Context) (map[int64]float64, error) {
+	rows, err := h.Queries.SelectAllProgress(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[int64]float64, len(rows))
+	for _, r := range rows {
+		out[r.BookID] = r.Percent
+	}
+	return out, nil
+}
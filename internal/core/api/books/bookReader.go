@@ -0,0 +1,626 @@
+package metadata
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ReaderBook is an EPUB opened for reading: the documents of its spine, in
+// reading order, and its table of contents.
+type ReaderBook struct {
+	Title    string
+	Chapters []string
+	TOC      []TOCEntry
+	bookPath string
+	sizes    []int64
+}
+
+// TOCEntry is a line of the table of contents. Chapter indexes Chapters and
+// Anchor, when set, is the id the entry points at inside that chapter.
+type TOCEntry struct {
+	Title   string
+	Depth   int
+	Chapter int
+	Anchor  string
+}
+
+type BlockKind int
+
+const (
+	BlockText BlockKind = iota
+	BlockHeading
+	BlockPre
+	BlockRule
+	BlockImage
+)
+
+// Block is a paragraph-level piece of a chapter. Level is the heading level,
+// Quote the blockquote depth and List the list depth; Marker is the bullet or
+// number of the first block of a list item. IDs are the anchors that land on
+// the block. An image block holds its alt text, if any, as its only span.
+type Block struct {
+	Kind   BlockKind
+	Level  int
+	Quote  int
+	List   int
+	Marker string
+	Spans  []Span
+	IDs    []string
+}
+
+type Span struct {
+	Text   string
+	Bold   bool
+	Italic bool
+}
+
+type tocLink struct {
+	Title string
+	Depth int
+	Href  string
+}
+
+type ncxDocument struct {
+	Points []ncxPoint `xml:"navMap>navPoint"`
+}
+
+type ncxPoint struct {
+	Label   string     `xml:"navLabel>text"`
+	Content ncxContent `xml:"content"`
+	Points  []ncxPoint `xml:"navPoint"`
+}
+
+type ncxContent struct {
+	Src string `xml:"src,attr"`
+}
+
+// OpenBook reads the spine and the table of contents of an EPUB in the
+// library. The EPUB 3 navigation document is preferred over the NCX; a book
+// with neither gets one entry per chapter.
+func (h *Handler) OpenBook(fileName string) (*ReaderBook, error) {
+	bookData, err := extractMetadata(h.LibraryDir, fileName)
+	if err != nil {
+		return nil, err
+	}
+	if bookData.opfPath == "" {
+		return nil, fmt.Errorf("%s has no package document", fileName)
+	}
+	book := &ReaderBook{
+		Title:    bookData.Metadata.Title,
+		bookPath: filepath.Join(h.LibraryDir, fileName),
+	}
+
+	baseDir := path.Dir(bookData.opfPath)
+	items := make(map[string]Item, len(bookData.Manifest.Items))
+	for _, it := range bookData.Manifest.Items {
+		items[it.Id] = it
+	}
+	chapterIndex := make(map[string]int)
+	for _, ref := range bookData.Spine.ItemRefs {
+		it, ok := items[ref.IdRef]
+		if !ok || ref.Linear == "no" {
+			continue
+		}
+		name, _ := resolveHref(baseDir, it.Href)
+		if _, seen := chapterIndex[name]; seen {
+			continue
+		}
+		chapterIndex[name] = len(book.Chapters)
+		book.Chapters = append(book.Chapters, name)
+	}
+	if len(book.Chapters) == 0 {
+		return nil, fmt.Errorf("%s has an empty spine", fileName)
+	}
+
+	r, err := zip.OpenReader(book.bookPath)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	for _, name := range book.Chapters {
+		size := int64(1)
+		if f, err := findZipFile(r, name); err == nil {
+			size = max(int64(f.UncompressedSize64), 1)
+		}
+		book.sizes = append(book.sizes, size)
+	}
+
+	var links []tocLink
+	tocDir := ""
+	for _, it := range bookData.Manifest.Items {
+		if !hasProperty(it.Properties, "nav") {
+			continue
+		}
+		name, _ := resolveHref(baseDir, it.Href)
+		if links, err = readNav(r, name); err != nil {
+			log.Printf("Err reading the navigation document of %s: %v", fileName, err)
+		}
+		tocDir = path.Dir(name)
+		break
+	}
+	if len(links) == 0 {
+		ncx, ok := items[bookData.Spine.Toc]
+		if !ok {
+			for _, it := range bookData.Manifest.Items {
+				if it.MediaType == "application/x-dtbncx+xml" {
+					ncx, ok = it, true
+					break
+				}
+			}
+		}
+		if ok {
+			name, _ := resolveHref(baseDir, ncx.Href)
+			if links, err = readNCX(r, name); err != nil {
+				log.Printf("Err reading the NCX of %s: %v", fileName, err)
+			}
+			tocDir = path.Dir(name)
+		}
+	}
+
+	for _, l := range links {
+		name, anchor := resolveHref(tocDir, l.Href)
+		chapter, ok := chapterIndex[name]
+		if !ok || l.Title == "" {
+			continue
+		}
+		book.TOC = append(book.TOC, TOCEntry{Title: l.Title, Depth: l.Depth, Chapter: chapter, Anchor: anchor})
+	}
+	if len(book.TOC) == 0 {
+		for i := range book.Chapters {
+			book.TOC = append(book.TOC, TOCEntry{Title: "Section " + strconv.Itoa(i+1), Chapter: i})
+		}
+	}
+	return book, nil
+}
+
+// ChapterTitle is the title of the first table of contents entry that points
+// into the chapter, or "".
+func (b *ReaderBook) ChapterTitle(i int) string {
+	for _, e := range b.TOC {
+		if e.Chapter == i {
+			return e.Title
+		}
+	}
+	return ""
+}
+
+// Percent is how far into the book a position is, weighing each chapter by
+// the size of its document.
+func (b *ReaderBook) Percent(chapter int, offset float64) float64 {
+	var before, total int64
+	for i, size := range b.sizes {
+		if i < chapter {
+			before += size
+		}
+		total += size
+	}
+	if chapter < 0 || chapter >= len(b.sizes) || total == 0 {
+		return 0
+	}
+	return (float64(before) + offset*float64(b.sizes[chapter])) / float64(total) * 100
+}
+
+// Position is the chapter and offset a percentage of the book falls on.
+func (b *ReaderBook) Position(percent float64) (int, float64) {
+	var total int64
+	for _, size := range b.sizes {
+		total += size
+	}
+	at := percent / 100 * float64(total)
+	for i, size := range b.sizes {
+		if at < float64(size) || i == len(b.sizes)-1 {
+			return i, min(max(at/float64(size), 0), 1)
+		}
+		at -= float64(size)
+	}
+	return 0, 0
+}
+
+// Chapter reads a spine document and breaks it into blocks.
+func (b *ReaderBook) Chapter(i int) ([]Block, error) {
+	if i < 0 || i >= len(b.Chapters) {
+		return nil, fmt.Errorf("chapter %d out of range", i)
+	}
+	rc, err := openEpubEntry(b.bookPath, b.Chapters[i])
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return parseXHTML(rc)
+}
+
+// resolveHref turns an href relative to dir into a zip entry name and the
+// fragment it carries.
+func resolveHref(dir, href string) (string, string) {
+	name, anchor, _ := strings.Cut(href, "#")
+	if unescaped, err := url.PathUnescape(name); err == nil {
+		name = unescaped
+	}
+	return path.Join(dir, name), anchor
+}
+
+func hasProperty(properties, name string) bool {
+	for _, p := range strings.Fields(properties) {
+		if p == name {
+			return true
+		}
+	}
+	return false
+}
+
+func readNCX(r *zip.ReadCloser, name string) ([]tocLink, error) {
+	f, err := findZipFile(r, name)
+	if err != nil {
+		return nil, err
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var doc ncxDocument
+	if err := newXHTMLDecoder(rc).Decode(&doc); err != nil {
+		return nil, err
+	}
+	var links []tocLink
+	var walk func(points []ncxPoint, depth int)
+	walk = func(points []ncxPoint, depth int) {
+		for _, p := range points {
+			links = append(links, tocLink{Title: collapseSpace(p.Label), Depth: depth, Href: p.Content.Src})
+			walk(p.Points, depth+1)
+		}
+	}
+	walk(doc.Points, 0)
+	return links, nil
+}
+
+// readNav reads the links of the <nav epub:type="toc"> of an EPUB 3
+// navigation document, falling back to its first <nav>.
+func readNav(r *zip.ReadCloser, name string) ([]tocLink, error) {
+	f, err := findZipFile(r, name)
+	if err != nil {
+		return nil, err
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var navs [][]tocLink
+	tocNav := -1
+	inNav, inLink := false, false
+	lists := 0
+	href := ""
+	var text strings.Builder
+	dec := newXHTMLDecoder(rc)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch strings.ToLower(t.Name.Local) {
+			case "nav":
+				if inNav {
+					continue
+				}
+				inNav, lists = true, 0
+				navs = append(navs, nil)
+				if tocNav < 0 && hasProperty(attrValue(t, "type"), "toc") {
+					tocNav = len(navs) - 1
+				}
+			case "ol", "ul":
+				lists++
+			case "a":
+				if inNav {
+					inLink, href = true, attrValue(t, "href")
+					text.Reset()
+				}
+			}
+		case xml.EndElement:
+			switch strings.ToLower(t.Name.Local) {
+			case "nav":
+				inNav = false
+			case "ol", "ul":
+				lists--
+			case "a":
+				if inLink {
+					last := len(navs) - 1
+					navs[last] = append(navs[last], tocLink{Title: collapseSpace(text.String()), Depth: max(lists-1, 0), Href: href})
+					inLink = false
+				}
+			}
+		case xml.CharData:
+			if inLink {
+				text.Write(t)
+			}
+		}
+	}
+	switch {
+	case tocNav >= 0:
+		return navs[tocNav], nil
+	case len(navs) > 0:
+		return navs[0], nil
+	}
+	return nil, nil
+}
+
+// newXHTMLDecoder reads content documents leniently: they are meant to be
+// XHTML but plenty are tag soup, and a declared charset other than UTF-8 is
+// read as is.
+func newXHTMLDecoder(r io.Reader) *xml.Decoder {
+	dec := xml.NewDecoder(r)
+	dec.Strict = false
+	dec.AutoClose = xml.HTMLAutoClose
+	dec.Entity = xml.HTMLEntity
+	dec.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	}
+	return dec
+}
+
+func attrValue(se xml.StartElement, name string) string {
+	for _, a := range se.Attr {
+		if strings.EqualFold(a.Name.Local, name) {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// blockBuilder collects the text of a content document into blocks, keeping
+// track of the elements it is inside of.
+type blockBuilder struct {
+	blocks  []Block
+	spans   []Span
+	ids     []string
+	marker  string
+	lists   []int
+	heading int
+	quote   int
+	pre     int
+	bold    int
+	italic  int
+	skip    int
+}
+
+// parseXHTML breaks a content document into blocks. A document that stops
+// parsing halfway keeps the blocks read up to there.
+func parseXHTML(r io.Reader) ([]Block, error) {
+	b := &blockBuilder{}
+	dec := newXHTMLDecoder(r)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			b.flush()
+			if len(b.blocks) == 0 {
+				return nil, err
+			}
+			log.Printf("Err parsing chapter: %v", err)
+			break
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			b.start(t)
+		case xml.EndElement:
+			b.end(strings.ToLower(t.Name.Local))
+		case xml.CharData:
+			b.text(string(t))
+		}
+	}
+	b.flush()
+	return b.blocks, nil
+}
+
+func (b *blockBuilder) start(se xml.StartElement) {
+	name := strings.ToLower(se.Name.Local)
+	if b.skip > 0 || name == "head" || name == "script" || name == "style" {
+		b.skip++
+		return
+	}
+	if id := attrValue(se, "id"); id != "" {
+		b.ids = append(b.ids, id)
+	}
+	switch name {
+	case "p", "div", "section", "article", "main", "header", "footer", "aside",
+		"figure", "figcaption", "table", "caption", "tr", "dl", "dt", "dd", "center", "body":
+		b.flush()
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		b.flush()
+		b.heading = int(name[1] - '0')
+	case "blockquote":
+		b.flush()
+		b.quote++
+	case "pre":
+		b.flush()
+		b.pre++
+	case "ul":
+		b.flush()
+		b.lists = append(b.lists, 0)
+	case "ol":
+		b.flush()
+		n := 1
+		if s, err := strconv.Atoi(attrValue(se, "start")); err == nil {
+			n = s
+		}
+		b.lists = append(b.lists, n)
+	case "li":
+		b.flush()
+		b.marker = "•"
+		if last := len(b.lists) - 1; last >= 0 && b.lists[last] > 0 {
+			b.marker = strconv.Itoa(b.lists[last]) + "."
+			b.lists[last]++
+		}
+	case "hr":
+		b.flush()
+		b.push(Block{Kind: BlockRule})
+	case "br":
+		b.spans = append(b.spans, Span{Text: "\n"})
+	case "td", "th":
+		b.text(" ")
+	case "img", "image":
+		alt := collapseSpace(attrValue(se, "alt"))
+		if b.hasText() {
+			if alt != "" {
+				b.text(" [" + alt + "] ")
+			}
+			return
+		}
+		b.flush()
+		img := Block{Kind: BlockImage}
+		if alt != "" {
+			img.Spans = []Span{{Text: alt}}
+		}
+		b.push(img)
+	case "b", "strong":
+		b.bold++
+	case "i", "em", "cite", "dfn", "var":
+		b.italic++
+	}
+}
+
+func (b *blockBuilder) end(name string) {
+	if b.skip > 0 {
+		b.skip--
+		return
+	}
+	switch name {
+	case "p", "div", "section", "article", "main", "header", "footer", "aside",
+		"figure", "figcaption", "table", "caption", "tr", "dl", "dt", "dd", "center", "body", "li":
+		b.flush()
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		b.flush()
+		b.heading = 0
+	case "blockquote":
+		b.flush()
+		b.quote = max(b.quote-1, 0)
+	case "pre":
+		b.flush()
+		b.pre = max(b.pre-1, 0)
+	case "ul", "ol":
+		b.flush()
+		if len(b.lists) > 0 {
+			b.lists = b.lists[:len(b.lists)-1]
+		}
+	case "b", "strong":
+		b.bold = max(b.bold-1, 0)
+	case "i", "em", "cite", "dfn", "var":
+		b.italic = max(b.italic-1, 0)
+	}
+}
+
+// text adds character data in the current style. Outside <pre> runs of white
+// space count as one space, and none is kept at the start of a block or
+// after a line break.
+func (b *blockBuilder) text(s string) {
+	if b.skip > 0 {
+		return
+	}
+	if b.pre > 0 {
+		s = strings.ReplaceAll(s, "\r\n", "\n")
+	} else {
+		var out strings.Builder
+		space := !b.hasText() || b.endsInSpace()
+		for _, r := range s {
+			if unicode.IsSpace(r) && r != ' ' {
+				if !space {
+					out.WriteByte(' ')
+				}
+				space = true
+				continue
+			}
+			out.WriteRune(r)
+			space = false
+		}
+		s = out.String()
+	}
+	if s == "" {
+		return
+	}
+	span := Span{Text: s, Bold: b.bold > 0, Italic: b.italic > 0}
+	if last := len(b.spans) - 1; last >= 0 && b.spans[last].Bold == span.Bold && b.spans[last].Italic == span.Italic {
+		b.spans[last].Text += s
+		return
+	}
+	b.spans = append(b.spans, span)
+}
+
+func (b *blockBuilder) hasText() bool {
+	for _, s := range b.spans {
+		if strings.TrimSpace(s.Text) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *blockBuilder) endsInSpace() bool {
+	if len(b.spans) == 0 {
+		return true
+	}
+	t := b.spans[len(b.spans)-1].Text
+	return strings.HasSuffix(t, " ") || strings.HasSuffix(t, "\n")
+}
+
+// flush closes the current block. Anchors seen in a block without text carry
+// over to the next one.
+func (b *blockBuilder) flush() {
+	if !b.hasText() {
+		b.spans = nil
+		return
+	}
+	for len(b.spans) > 0 {
+		last := len(b.spans) - 1
+		if b.pre > 0 {
+			b.spans[last].Text = strings.TrimRight(b.spans[last].Text, "\n")
+		} else {
+			b.spans[last].Text = strings.TrimRightFunc(b.spans[last].Text, unicode.IsSpace)
+		}
+		if b.spans[last].Text != "" {
+			break
+		}
+		b.spans = b.spans[:last]
+	}
+	if b.pre > 0 {
+		b.spans[0].Text = strings.TrimLeft(b.spans[0].Text, "\n")
+	}
+	block := Block{Kind: BlockText, Spans: b.spans}
+	switch {
+	case b.heading > 0:
+		block.Kind, block.Level = BlockHeading, b.heading
+	case b.pre > 0:
+		block.Kind = BlockPre
+	}
+	b.spans = nil
+	b.push(block)
+}
+
+func (b *blockBuilder) push(block Block) {
+	block.Quote = b.quote
+	block.List = len(b.lists)
+	block.Marker, b.marker = b.marker, ""
+	block.IDs, b.ids = b.ids, nil
+	b.blocks = append(b.blocks, block)
+}
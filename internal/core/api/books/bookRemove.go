@@ -0,0 +1,147 @@
+package metadata
+
+import (
+	"Kindria/internal/core/db"
+	"context"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// TrashDir is the folder, inside the library, that removed books are moved to
+// when they are not deleted outright. InsertBooks skips directories, so books
+// in it are never imported again.
+const TrashDir = ".trash"
+
+// RemoveBook deletes the book row, its author links, its cached cover and its
+// generated placeholders. The EPUB is deleted, or moved to TrashDir when trash
+// is set; the returned path is where it ended up. If the file cannot be
+// removed the row is kept. The file goes to TrashDir before the commit, so a
+// failed commit can put it back, and is only deleted for good once the row is
+// gone.
+func (h *Handler) RemoveBook(fileName string, trash bool) (string, error) {
+	ctx := context.Background()
+	row, err := h.Queries.SelectBookByFileName(ctx, fileName)
+	if err != nil {
+		return "", err
+	}
+
+	tx, err := h.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+	qtx := h.Queries.WithTx(tx)
+	if err := qtx.DeleteBookAuthors(ctx, row.ID); err != nil {
+		return "", err
+	}
+	if err := qtx.DeleteBook(ctx, row.ID); err != nil {
+		return "", err
+	}
+	if err := qtx.DeleteOrphanAuthors(ctx); err != nil {
+		return "", err
+	}
+
+	src := filepath.Join(h.LibraryDir, row.FileName)
+	dest, err := moveToTrash(h.LibraryDir, row.FileName)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	if err := tx.Commit(); err != nil {
+		if dest != "" {
+			if err := os.Rename(dest, src); err != nil {
+				log.Printf("Err restoring %s from the trash: %v", row.FileName, err)
+			}
+		}
+		return "", err
+	}
+	if !trash && dest != "" {
+		if err := os.Remove(dest); err != nil {
+			log.Printf("Err deleting %s: %v", dest, err)
+		}
+		dest = ""
+	}
+
+	h.removeUnusedCover(row.Bookpath)
+	h.removeUnusedPlaceholders(row.Title, row.Author)
+	return dest, nil
+}
+
+// removeUnusedPlaceholders deletes the generated covers of a removed book once
+// no other book has the same title and author.
+func (h *Handler) removeUnusedPlaceholders(title, author string) {
+	if h.CM == nil {
+		return
+	}
+	n, err := h.Queries.CountBooksByTitleAuthor(context.Background(), db.CountBooksByTitleAuthorParams{Title: title, Author: author})
+	if err != nil || n > 0 {
+		return
+	}
+	h.CM.removePlaceholders(title, author)
+}
+
+// removeUnusedCover deletes a cached cover once no book points at it. Covers
+// outside the covers directory are never touched; relative paths are resolved
+// first, so a legacy "./cache/covers/..." path still matches.
+func (h *Handler) removeUnusedCover(coverPath string) {
+	if coverPath == "" || h.CM == nil {
+		return
+	}
+	dir, err := filepath.Abs(filepath.Dir(coverPath))
+	if err != nil {
+		return
+	}
+	coversDir, err := filepath.Abs(h.CM.coversDir)
+	if err != nil || dir != coversDir {
+		return
+	}
+	n, err := h.Queries.CountBooksByCover(context.Background(), coverPath)
+	if err != nil || n > 0 {
+		return
+	}
+	if err := os.Remove(coverPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Printf("Err removing cover %s: %v", coverPath, err)
+	}
+}
+
+func moveToTrash(libraryDir, fileName string) (string, error) {
+	dir := filepath.Join(libraryDir, TrashDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	dest := filepath.Join(dir, fileName)
+	if _, err := os.Stat(dest); err == nil {
+		ext := filepath.Ext(fileName)
+		dest = filepath.Join(dir, strings.TrimSuffix(fileName, ext)+"-"+time.Now().Format("20060102-150405")+ext)
+	}
+	if err := os.Rename(filepath.Join(libraryDir, fileName), dest); err != nil {
+		return "", err
+	}
+	return dest, nil
+}
+
+// ArchiveBook hides a book from every view without deleting it. Unarchiving
+// restores the status of the latest read (see sessionStatus); the reading
+// date is kept both ways.
+func (h *Handler) ArchiveBook(fileName string, archived bool) (string, error) {
+	ctx := context.Background()
+	status := "Archived"
+	if !archived {
+		row, err := h.Queries.SelectBookByFileName(ctx, fileName)
+		if err != nil {
+			return "", err
+		}
+		status, err = sessionStatus(ctx, h.Queries, row.ID)
+		if err != nil {
+			return "", err
+		}
+	}
+	err := h.Queries.UpdateStatusKeepDate(ctx, db.UpdateStatusKeepDateParams{Status: status, FileName: fileName})
+	if err != nil {
+		return "", err
+	}
+	return status, nil
+}
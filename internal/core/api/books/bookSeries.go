@@ -0,0 +1,145 @@
+package metadata
+
+import (
+	"Kindria/internal/core/db"
+	"context"
+	"database/sql"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// resolveSeries prefers an EPUB3 belongs-to-collection typed as series, then
+// an untyped collection, then the calibre:series metas.
+func (md *MetaData) resolveSeries() {
+	md.Series, md.SeriesIndex = "", 0
+	refined := md.refinements()
+	fallback := -1
+	for i, m := range md.Metas {
+		if m.Property != "belongs-to-collection" || m.Refines != "" || strings.TrimSpace(m.Value) == "" {
+			continue
+		}
+		switch refined[m.ID]["collection-type"] {
+		case "series":
+			md.setSeries(m.Value, refined[m.ID]["group-position"])
+			return
+		case "":
+			if fallback < 0 {
+				fallback = i
+			}
+		}
+	}
+	if fallback >= 0 {
+		m := md.Metas[fallback]
+		md.setSeries(m.Value, refined[m.ID]["group-position"])
+		return
+	}
+
+	var name, index string
+	for _, m := range md.Metas {
+		switch m.Name {
+		case "calibre:series":
+			name = m.Content
+		case "calibre:series_index":
+			index = m.Content
+		}
+	}
+	md.setSeries(name, index)
+}
+
+func (md *MetaData) setSeries(name, index string) {
+	md.Series = strings.Join(strings.Fields(name), " ")
+	if md.Series == "" {
+		return
+	}
+	if v, err := strconv.ParseFloat(strings.TrimSpace(index), 64); err == nil && v >= 0 {
+		md.SeriesIndex = v
+	}
+}
+
+func (md MetaData) SeriesLabel() string {
+	if md.Series == "" {
+		return ""
+	}
+	if md.SeriesIndex == 0 {
+		return md.Series
+	}
+	return md.Series + " #" + FormatSeriesIndex(md.SeriesIndex)
+}
+
+func FormatSeriesIndex(index float64) string {
+	return strconv.FormatFloat(index, 'f', -1, 64)
+}
+
+// SortBySeries orders books by series name and then reading order, leaving
+// books without a series at the end.
+func SortBySeries(books []*Package) {
+	sort.SliceStable(books, func(i, j int) bool {
+		a, b := books[i].Metadata, books[j].Metadata
+		if (a.Series == "") != (b.Series == "") {
+			return b.Series == ""
+		}
+		if sa, sb := strings.ToLower(a.Series), strings.ToLower(b.Series); sa != sb {
+			return sa < sb
+		}
+		if a.SeriesIndex != b.SeriesIndex {
+			return a.SeriesIndex < b.SeriesIndex
+		}
+		return strings.ToLower(a.Title) < strings.ToLower(b.Title)
+	})
+}
+
+// NextUnreadInSeries returns the first book of the series, in reading order,
+// that is neither Read nor Archived. It returns nil when every book has been
+// read.
+func NextUnreadInSeries(books []*Package, series string) *Package {
+	if series == "" {
+		return nil
+	}
+	var next *Package
+	for _, b := range books {
+		if !strings.EqualFold(b.Metadata.Series, series) || b.Status == "Read" || b.Status == "Archived" {
+			continue
+		}
+		if next == nil || b.Metadata.SeriesIndex < next.Metadata.SeriesIndex ||
+			(b.Metadata.SeriesIndex == next.Metadata.SeriesIndex && strings.ToLower(b.Metadata.Title) < strings.ToLower(next.Metadata.Title)) {
+			next = b
+		}
+	}
+	return next
+}
+
+// BackfillSeries extracts the series of books inserted before the series
+// columns existed. A NULL series marks a book that has not been scanned yet.
+func (h *Handler) BackfillSeries() (int, error) {
+	ctx := context.Background()
+	rows, err := h.Queries.SelectBooksWithoutSeries(ctx)
+	if err != nil {
+		return 0, err
+	}
+	found := 0
+	for _, row := range rows {
+		var md MetaData
+		if _, err := os.Stat(filepath.Join(h.LibraryDir, row.FileName)); err == nil {
+			if bookData, err := extractMetadata(h.LibraryDir, row.FileName); err == nil {
+				md = bookData.Metadata
+			}
+		}
+		err := h.Queries.UpdateBookSeries(ctx, db.UpdateBookSeriesParams{
+			Series:      sql.NullString{String: md.Series, Valid: true},
+			SeriesIndex: md.SeriesIndex,
+			ID:          row.ID,
+		})
+		if err != nil {
+			log.Printf("Err updating series of %s: %v", row.FileName, err)
+			continue
+		}
+		if md.Series != "" {
+			found++
+		}
+	}
+	return found, nil
+}
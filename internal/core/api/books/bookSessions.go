@@ -0,0 +1,158 @@
+package metadata
+
+import (
+	"Kindria/internal/core/db"
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Session is one read of a book, kept in the append-only reading_sessions
+// table. A session without a finish date is the read in progress; sessions
+// backfilled from an old reading date have no start date. Only the rating and
+// notes of the latest read change after it is written.
+type Session struct {
+	StartedAt  string
+	FinishedAt string
+	Rating     float64
+	Notes      string
+}
+
+func sessionFromRow(s db.ReadingSession) Session {
+	return Session{StartedAt: s.StartedAt, FinishedAt: s.FinishedAt, Rating: s.Rating, Notes: s.Notes}
+}
+
+// Sessions returns the reading history of a book, oldest read first.
+func (h *Handler) Sessions(fileName string) ([]Session, error) {
+	ctx := context.Background()
+	row, err := h.Queries.SelectBookByFileName(ctx, fileName)
+	if err != nil {
+		return nil, err
+	}
+	return loadSessions(ctx, h.Queries, row.ID)
+}
+
+func loadSessions(ctx context.Context, q *db.Queries, bookID int64) ([]Session, error) {
+	rows, err := q.SelectSessions(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Session, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, sessionFromRow(r))
+	}
+	return out, nil
+}
+
+// lastFinished is the reading date of a book: when its latest read ended.
+func lastFinished(sessions []Session) string {
+	date := ""
+	for _, s := range sessions {
+		if s.FinishedAt > date {
+			date = s.FinishedAt
+		}
+	}
+	return date
+}
+
+// UpdateBookStatus records a status change in the reading history of a book.
+// "Currently Reading" starts a session unless one is open and "Read" finishes
+// the open one, or adds a finished session when the book had none; a book
+// whose latest read is already finished simply goes back to Read, so a status
+// pressed by mistake costs nothing. Read, Unread and Currently Reading are
+// then derived from the latest read (see sessionStatus), so a book with a
+// finished read cannot become Unread; other statuses, such as To Be Read, are
+// stored as given. A new read is not rated until the book is rated again. The
+// stored status and the reading date, the finish date of the latest read, are
+// returned.
+func (h *Handler) UpdateBookStatus(status, fileName string) (string, string, error) {
+	ctx := context.Background()
+	tx, err := h.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return "", "", err
+	}
+	defer tx.Rollback()
+	qtx := h.Queries.WithTx(tx)
+
+	row, err := qtx.SelectBookByFileName(ctx, fileName)
+	if err != nil {
+		return "", "", err
+	}
+	latest, err := qtx.SelectLatestSession(ctx, row.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return "", "", err
+	}
+	hasSession := err == nil
+	open := hasSession && latest.FinishedAt == ""
+	today := time.Now().Format("2006-01-02")
+	err = nil
+	switch {
+	case status == "Currently Reading" && !open:
+		err = qtx.InsertSession(ctx, db.InsertSessionParams{BookID: row.ID, StartedAt: today})
+	case status == "Read" && open:
+		err = qtx.FinishSession(ctx, db.FinishSessionParams{FinishedAt: today, ID: latest.ID})
+	case status == "Read" && !hasSession:
+		err = qtx.InsertSession(ctx, db.InsertSessionParams{BookID: row.ID, FinishedAt: today})
+	}
+	if err != nil {
+		return "", "", err
+	}
+
+	switch status {
+	case "Read", "Unread", "Currently Reading":
+		if status, err = sessionStatus(ctx, qtx, row.ID); err != nil {
+			return "", "", err
+		}
+	}
+	sessions, err := loadSessions(ctx, qtx, row.ID)
+	if err != nil {
+		return "", "", err
+	}
+	readingDate := lastFinished(sessions)
+	err = qtx.UpdateStatus(ctx, db.UpdateStatusParams{Status: status, ReadingDate: readingDate, FileName: fileName})
+	if err != nil {
+		return "", "", err
+	}
+	return status, readingDate, tx.Commit()
+}
+
+// sessionStatus is the status the latest read of a book implies: Currently
+// Reading while it is open, Read once finished and Unread without any.
+func sessionStatus(ctx context.Context, q *db.Queries, bookID int64) (string, error) {
+	latest, err := q.SelectLatestSession(ctx, bookID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return "Unread", nil
+	case err != nil:
+		return "", err
+	case latest.FinishedAt == "":
+		return "Currently Reading", nil
+	}
+	return "Read", nil
+}
+
+// AddSessionNote appends a note to the latest read of a book.
+func (h *Handler) AddSessionNote(fileName, note string) error {
+	note = strings.TrimSpace(note)
+	if note == "" {
+		return nil
+	}
+	ctx := context.Background()
+	row, err := h.Queries.SelectBookByFileName(ctx, fileName)
+	if err != nil {
+		return err
+	}
+	latest, err := h.Queries.SelectLatestSession(ctx, row.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.New("no reading session to attach the note to: mark the book Read or Currently Reading first")
+	}
+	if err != nil {
+		return err
+	}
+	if latest.Notes != "" {
+		note = latest.Notes + "\n" + note
+	}
+	return h.Queries.UpdateSessionNotes(ctx, db.UpdateSessionNotesParams{Notes: note, ID: latest.ID})
+}
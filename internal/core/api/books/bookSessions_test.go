@@ -0,0 +1,101 @@
+package metadata
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSaveProgressStartsReread(t *testing.T) {
+	h := newTestHandler(t)
+	src := writeTestEPUB(t, t.TempDir(), "dune.epub", "Dune", "Frank Herbert", "text")
+	if _, err := h.ImportFiles([]string{src}, DuplicateSkip); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := h.UpdateBookStatus("Read", "dune.epub"); err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		percent  float64
+		status   string
+		sessions int
+		open     bool
+	}{
+		{100, "Read", 1, false},
+		{30, "Currently Reading", 2, true},
+		{60, "Currently Reading", 2, true},
+		{100, "Read", 2, false},
+	}
+	for _, s := range steps {
+		status, _, err := h.SaveProgress("dune.epub", Progress{Chapter: -1, Percent: s.percent})
+		if err != nil {
+			t.Fatal(err)
+		}
+		sessions, err := h.Sessions("dune.epub")
+		if err != nil {
+			t.Fatal(err)
+		}
+		open := sessions[len(sessions)-1].FinishedAt == ""
+		if status != s.status || len(sessions) != s.sessions || open != s.open {
+			t.Errorf("at %v%%: status %q, %d sessions, open %v; want %q, %d, %v", s.percent, status, len(sessions), open, s.status, s.sessions, s.open)
+		}
+	}
+}
+
+func TestSessionsAppendOnly(t *testing.T) {
+	h := newTestHandler(t)
+	src := writeTestEPUB(t, t.TempDir(), "dune.epub", "Dune", "Frank Herbert", "text")
+	if _, err := h.ImportFiles([]string{src}, DuplicateSkip); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := h.UpdateBookStatus("Read", "dune.epub"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, stmt := range []string{
+		"UPDATE reading_sessions SET finished_at = '2000-01-01'",
+		"UPDATE reading_sessions SET started_at = '2000-01-01'",
+		"DELETE FROM reading_sessions",
+	} {
+		if _, err := h.DB.Exec(stmt); err == nil || !strings.Contains(err.Error(), "append-only") {
+			t.Errorf("%s: err = %v, want append-only", stmt, err)
+		}
+	}
+
+	// Rating and notes are annotations of the latest read and may change.
+	if err := h.UpdateBookRating(4, "dune.epub"); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.AddSessionNote("dune.epub", "first"); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.AddSessionNote("dune.epub", "second"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A re-read starts unrated and rating the book leaves the earlier read alone.
+	if _, _, err := h.UpdateBookStatus("Currently Reading", "dune.epub"); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.UpdateBookRating(2, "dune.epub"); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.AddSessionNote("dune.epub", "again"); err != nil {
+		t.Fatal(err)
+	}
+
+	sessions, err := h.Sessions("dune.epub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sessions) != 2 {
+		t.Fatalf("got %d sessions, want 2", len(sessions))
+	}
+	first, reread := sessions[0], sessions[1]
+	if first.FinishedAt == "" || first.Rating != 4 || first.Notes != "first\nsecond" {
+		t.Errorf("first read = %+v, want finished, rated 4 with both notes", first)
+	}
+	if reread.FinishedAt != "" || reread.Rating != 0 || reread.Notes != "again" {
+		t.Errorf("re-read = %+v, want open, unrated with its own note", reread)
+	}
+}
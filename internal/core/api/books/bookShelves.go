@@ -0,0 +1,181 @@
+package metadata
+
+import (
+	"Kindria/internal/core/db"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Shelf is a user-defined group of books; a book can be on any number of
+// shelves. A smart shelf has a Query instead and holds whatever books match
+// it at the moment.
+type Shelf struct {
+	ID        int64
+	Name      string
+	Query     string
+	BookCount int64
+}
+
+type ShelfBook struct {
+	FileName string
+	Title    string
+}
+
+// ListShelves returns every shelf in name order with how many books it holds.
+func (h *Handler) ListShelves() ([]Shelf, error) {
+	rows, err := h.Queries.ListShelves(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	shelves := make([]Shelf, 0, len(rows))
+	for _, row := range rows {
+		shelf := Shelf{ID: row.ID, Name: row.Name, Query: row.Query, BookCount: row.BookCount}
+		if shelf.Query != "" {
+			books, err := h.QueryBooks(shelf.Query)
+			if err != nil {
+				log.Printf("Error running the query of shelf %s: %v", shelf.Name, err)
+			}
+			shelf.BookCount = int64(len(books))
+		}
+		shelves = append(shelves, shelf)
+	}
+	return shelves, nil
+}
+
+// checkShelfName trims a new shelf name and refuses empty names and names
+// already taken by another shelf, whatever their case.
+func (h *Handler) checkShelfName(name string, id int64) (string, error) {
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" {
+		return "", errors.New("a shelf needs a name")
+	}
+	shelves, err := h.Queries.ListShelves(context.Background())
+	if err != nil {
+		return "", err
+	}
+	for _, s := range shelves {
+		if s.ID != id && strings.EqualFold(s.Name, name) {
+			return "", fmt.Errorf("there is already a shelf called %q", s.Name)
+		}
+	}
+	return name, nil
+}
+
+// CreateShelf adds a shelf, or a smart shelf when query is not empty.
+func (h *Handler) CreateShelf(name, query string) error {
+	name, err := h.checkShelfName(name, 0)
+	if err != nil {
+		return err
+	}
+	query = strings.TrimSpace(query)
+	if query != "" {
+		if _, err := ParseQuery(query); err != nil {
+			return err
+		}
+	}
+	return h.Queries.InsertShelf(context.Background(), db.InsertShelfParams{Name: name, Query: query})
+}
+
+func (h *Handler) RenameShelf(id int64, name string) error {
+	name, err := h.checkShelfName(name, id)
+	if err != nil {
+		return err
+	}
+	n, err := h.Queries.RenameShelf(context.Background(), db.RenameShelfParams{Name: name, ID: id})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("no shelf with id %d", id)
+	}
+	return nil
+}
+
+// SetShelfQuery changes the query of a smart shelf.
+func (h *Handler) SetShelfQuery(id int64, query string) error {
+	ctx := context.Background()
+	shelf, err := h.Queries.SelectShelf(ctx, id)
+	if err != nil {
+		return err
+	}
+	if shelf.Query == "" {
+		return fmt.Errorf("%s is not a smart shelf", shelf.Name)
+	}
+	query = strings.TrimSpace(query)
+	if _, err := ParseQuery(query); err != nil {
+		return err
+	}
+	_, err = h.Queries.UpdateShelfQuery(ctx, db.UpdateShelfQueryParams{Query: query, ID: id})
+	return err
+}
+
+// DeleteShelf removes a shelf; its books stay in the library.
+func (h *Handler) DeleteShelf(id int64) error {
+	n, err := h.Queries.DeleteShelf(context.Background(), id)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return fmt.Errorf("no shelf with id %d", id)
+	}
+	return nil
+}
+
+// ShelfBooks returns the books on a shelf, by title.
+func (h *Handler) ShelfBooks(id int64) ([]ShelfBook, error) {
+	ctx := context.Background()
+	shelf, err := h.Queries.SelectShelf(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if shelf.Query != "" {
+		return h.QueryBooks(shelf.Query)
+	}
+	rows, err := h.Queries.SelectShelfBooks(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	books := make([]ShelfBook, 0, len(rows))
+	for _, row := range rows {
+		books = append(books, ShelfBook{FileName: row.FileName, Title: row.Title})
+	}
+	return books, nil
+}
+
+// BookShelves returns the ids of the shelves a book is on.
+func (h *Handler) BookShelves(fileName string) ([]int64, error) {
+	ctx := context.Background()
+	row, err := h.Queries.SelectBookByFileName(ctx, fileName)
+	if err != nil {
+		return nil, err
+	}
+	return h.Queries.SelectBookShelfIDs(ctx, row.ID)
+}
+
+// SetBookShelves puts a book on exactly the given shelves.
+func (h *Handler) SetBookShelves(fileName string, shelfIDs []int64) error {
+	ctx := context.Background()
+	tx, err := h.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	qtx := h.Queries.WithTx(tx)
+
+	row, err := qtx.SelectBookByFileName(ctx, fileName)
+	if err != nil {
+		return err
+	}
+	if err := qtx.DeleteBookShelves(ctx, row.ID); err != nil {
+		return err
+	}
+	for _, id := range shelfIDs {
+		if err := qtx.InsertBookShelf(ctx, db.InsertBookShelfParams{BookID: row.ID, ShelfID: id}); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
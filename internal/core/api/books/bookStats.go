@@ -0,0 +1,248 @@
+package metadata
+
+import (
+	"Kindria/internal/core/db"
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// wordsPerPage estimates the pages of a book whose page count is unknown.
+const wordsPerPage = 275
+
+// ReadingStats are the figures behind the Stats screen and `kindria stats`
+// for one year, or for every year when Year is 0. Only finished reads count,
+// so a book read twice counts twice; streaks also take the reads in progress.
+type ReadingStats struct {
+	Year           int          `json:"year"`
+	Years          []Count      `json:"years"`  // finished reads per year, every year, oldest first
+	Months         [12]int      `json:"months"` // finished reads per month of Year
+	Log            []LoggedRead `json:"log"`
+	Reading        int          `json:"reading"`
+	AverageRating  float64      `json:"average_rating"`
+	Rated          int          `json:"rated"`
+	Genres         []Count      `json:"genres"`
+	Languages      []Count      `json:"languages"`
+	Pages          int64        `json:"pages"`
+	EstimatedPages bool         `json:"estimated_pages"`
+	Words          int64        `json:"words"`
+	MonthStreak    Streak       `json:"month_streak"`
+	DayStreak      Streak       `json:"day_streak"`
+}
+
+// LoggedRead is a finished read in the reading log.
+type LoggedRead struct {
+	Title      string   `json:"title"`
+	Author     string   `json:"author"`
+	FileName   string   `json:"file_name"`
+	Language   string   `json:"language"`
+	Genres     []string `json:"genres"`
+	StartedAt  string   `json:"started_at"`
+	FinishedAt string   `json:"finished_at"`
+	Rating     float64  `json:"rating"`
+	Pages      int64    `json:"pages"`
+	Words      int64    `json:"words"`
+}
+
+type Count struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// Streak is the longest run of consecutive days (or months) with reading in
+// it; Start and End are the first and last of them.
+type Streak struct {
+	Length int    `json:"length"`
+	Start  string `json:"start"`
+	End    string `json:"end"`
+}
+
+// ReadingStats gathers the reading statistics of a year (0 for every year).
+// Word counts are read from the EPUB spine the first time a read book shows up
+// and kept in word_counts until the file changes.
+func (h *Handler) ReadingStats(year int) (*ReadingStats, error) {
+	ctx := context.Background()
+	rows, err := h.Queries.SelectReadingLog(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stats := &ReadingStats{Year: year}
+	today := time.Now()
+	days := make(map[string]struct{})
+	months := make(map[string]struct{})
+	years := make(map[int]int)
+	genres := make(map[string]int)
+	languages := make(map[string]int)
+	counted := make(map[int64]int64)
+	ratings := 0.0
+
+	for _, r := range rows {
+		finished, finishedErr := time.Parse("2006-01-02", r.FinishedAt)
+		started, startedErr := time.Parse("2006-01-02", r.StartedAt)
+		switch {
+		case r.FinishedAt == "":
+			finished = today
+			if year == 0 || year == today.Year() {
+				stats.Reading++
+			}
+		case finishedErr != nil:
+			continue
+		}
+		if startedErr != nil || started.After(finished) {
+			started = finished
+		}
+		for d := started; !d.After(finished); d = d.AddDate(0, 0, 1) {
+			if year == 0 || d.Year() == year {
+				days[d.Format("2006-01-02")] = struct{}{}
+			}
+		}
+		if r.FinishedAt == "" {
+			continue
+		}
+
+		years[finished.Year()]++
+		if year != 0 && finished.Year() != year {
+			continue
+		}
+		stats.Months[finished.Month()-1]++
+		months[finished.Format("2006-01")] = struct{}{}
+
+		words, ok := counted[r.BookID]
+		if !ok {
+			words = r.Words
+			if r.CountedHash != r.ContentHash || r.CountedHash == "" {
+				words = h.countWords(ctx, r)
+			}
+			counted[r.BookID] = words
+		}
+		pages := r.PageCount
+		if pages <= 0 && words > 0 {
+			pages = (words + wordsPerPage/2) / wordsPerPage
+			stats.EstimatedPages = true
+		}
+		stats.Pages += pages
+		stats.Words += words
+
+		if r.Rating > 0 {
+			ratings += r.Rating
+			stats.Rated++
+		}
+		bookGenres := normalizeGenres(strings.Split(r.Genres, ","))
+		for _, g := range bookGenres {
+			genres[g]++
+		}
+		language := strings.ToLower(strings.TrimSpace(r.Language))
+		if language == "" {
+			language = "unknown"
+		}
+		languages[language]++
+
+		stats.Log = append(stats.Log, LoggedRead{
+			Title:      r.Title,
+			Author:     r.Author,
+			FileName:   r.FileName,
+			Language:   r.Language,
+			Genres:     bookGenres,
+			StartedAt:  r.StartedAt,
+			FinishedAt: r.FinishedAt,
+			Rating:     r.Rating,
+			Pages:      pages,
+			Words:      words,
+		})
+	}
+
+	if stats.Rated > 0 {
+		stats.AverageRating = ratings / float64(stats.Rated)
+	}
+	for y, n := range years {
+		stats.Years = append(stats.Years, Count{Name: strconv.Itoa(y), Count: n})
+	}
+	sort.Slice(stats.Years, func(i, j int) bool { return stats.Years[i].Name < stats.Years[j].Name })
+	stats.Genres = sortedCounts(genres)
+	stats.Languages = sortedCounts(languages)
+	stats.DayStreak = longestStreak(days, "2006-01-02", func(t time.Time) time.Time { return t.AddDate(0, 0, 1) })
+	stats.MonthStreak = longestStreak(months, "2006-01", func(t time.Time) time.Time { return t.AddDate(0, 1, 0) })
+	return stats, nil
+}
+
+// countWords counts the words of a book's spine and stores the count with the
+// hash of the file it was taken from. A book that cannot be read counts zero
+// until its file changes.
+func (h *Handler) countWords(ctx context.Context, r db.SelectReadingLogRow) int64 {
+	if _, err := os.Stat(filepath.Join(h.LibraryDir, r.FileName)); err != nil {
+		return 0
+	}
+	var words int64
+	book, err := h.OpenBook(r.FileName)
+	if err != nil {
+		log.Printf("Error counting the words of %s: %v", r.FileName, err)
+		book = &ReaderBook{}
+	}
+	for i := range book.Chapters {
+		blocks, err := book.Chapter(i)
+		if err != nil {
+			continue
+		}
+		for _, b := range blocks {
+			if b.Kind == BlockImage {
+				continue
+			}
+			for _, s := range b.Spans {
+				words += int64(len(strings.Fields(s.Text)))
+			}
+		}
+	}
+	err = h.Queries.UpsertWordCount(ctx, db.UpsertWordCountParams{BookID: r.BookID, ContentHash: r.ContentHash, Words: words})
+	if err != nil {
+		log.Printf("Error storing the word count of %s: %v", r.FileName, err)
+	}
+	return words
+}
+
+func sortedCounts(m map[string]int) []Count {
+	out := make([]Count, 0, len(m))
+	for name, n := range m {
+		out = append(out, Count{Name: name, Count: n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+// longestStreak finds the longest run of keys, formatted with layout, where
+// each one is next(previous).
+func longestStreak(keys map[string]struct{}, layout string, next func(time.Time) time.Time) Streak {
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+	var best, run Streak
+	var prev time.Time
+	for _, k := range sorted {
+		t, err := time.Parse(layout, k)
+		if err != nil {
+			continue
+		}
+		if run.Length > 0 && next(prev).Equal(t) {
+			run.Length++
+			run.End = k
+		} else {
+			run = Streak{Length: 1, Start: k, End: k}
+		}
+		if run.Length > best.Length {
+			best = run
+		}
+		prev = t
+	}
+	return best
+}
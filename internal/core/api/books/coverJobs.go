@@ -0,0 +1,176 @@
+package metadata
+
+import (
+	"Kindria/internal/core/db"
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+)
+
+const (
+	CoverJobPending = "pending"
+	CoverJobRunning = "running"
+	CoverJobDone    = "done"
+	CoverJobMissing = "missing"
+	CoverJobFailed  = "failed"
+)
+
+const (
+	maxCoverAttempts = 6
+	coverRetryBase   = time.Minute
+	coverRetryMax    = 6 * time.Hour
+)
+
+// Job times are stored in UTC with a fixed layout so they sort as text.
+const jobTimeLayout = "2006-01-02 15:04:05"
+
+func jobTime(t time.Time) string {
+	return t.UTC().Format(jobTimeLayout)
+}
+
+// CoverUpdate is sent when the worker links a downloaded cover to a book.
+type CoverUpdate struct {
+	BookID   int64
+	FileName string
+	Path     string
+}
+
+// Updates delivers covers fetched in the background. Updates are dropped when
+// nobody is listening.
+func (c *CoverManager) Updates() <-chan CoverUpdate {
+	return c.updates
+}
+
+func (c *CoverManager) wakeWorker() {
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+// queueCover schedules an Open Library lookup for a book. Call wakeWorker
+// once the surrounding transaction, if any, has committed.
+func queueCover(ctx context.Context, q *db.Queries, bookID int64) error {
+	now := jobTime(time.Now())
+	return q.EnqueueCoverJob(ctx, db.EnqueueCoverJobParams{BookID: bookID, NextAttemptAt: now, UpdatedAt: now})
+}
+
+// coverRetryDelay doubles coverRetryBase with every failed attempt.
+func coverRetryDelay(attempts int64) time.Duration {
+	d := coverRetryBase << (attempts - 1)
+	if d <= 0 || d > coverRetryMax {
+		d = coverRetryMax
+	}
+	return d
+}
+
+// UpdateCacheCovers runs the cover worker. Jobs live in the cover_jobs table,
+// so pending lookups survive a restart; failed requests are retried with
+// exponential backoff up to maxCoverAttempts times. Requests are spaced by the
+// providers' rate limits.
+func (h *Handler) UpdateCacheCovers() error {
+	ctx := context.Background()
+	if err := h.Queries.ResetRunningCoverJobs(ctx); err != nil {
+		return err
+	}
+	for {
+		job, err := h.Queries.SelectDueCoverJob(ctx, jobTime(time.Now()))
+		if errors.Is(err, sql.ErrNoRows) {
+			h.waitForCoverJob(ctx)
+			continue
+		}
+		if err != nil {
+			log.Printf("Err reading cover jobs: %v", err)
+			time.Sleep(coverRetryBase)
+			continue
+		}
+		h.runCoverJob(ctx, job)
+	}
+}
+
+// waitForCoverJob blocks until the next retry is due or a new job is queued.
+func (h *Handler) waitForCoverJob(ctx context.Context) {
+	var due <-chan time.Time
+	if next, err := h.Queries.SelectNextCoverJobAt(ctx); err == nil && next != "" {
+		if at, err := time.ParseInLocation(jobTimeLayout, next, time.UTC); err == nil {
+			due = time.After(time.Until(at))
+		}
+	}
+	select {
+	case <-h.CM.wake:
+	case <-due:
+	}
+}
+
+func (h *Handler) runCoverJob(ctx context.Context, job db.SelectDueCoverJobRow) {
+	update := func(status string, attempts int64, next time.Time, lastErr string) {
+		err := h.Queries.UpdateCoverJob(ctx, db.UpdateCoverJobParams{
+			Status:        status,
+			Attempts:      attempts,
+			NextAttemptAt: jobTime(next),
+			LastError:     lastErr,
+			UpdatedAt:     jobTime(time.Now()),
+			BookID:        job.BookID,
+		})
+		if err != nil {
+			log.Printf("Err updating cover job of %s: %v", job.FileName, err)
+		}
+	}
+	// The book got a cover some other way while it was waiting.
+	if job.Bookpath != "" {
+		update(CoverJobDone, job.Attempts, time.Now(), "")
+		return
+	}
+	update(CoverJobRunning, job.Attempts, time.Now(), "")
+
+	attempts := job.Attempts + 1
+	var sc StoredCover
+	row, err := h.Queries.SelectBookByFileName(ctx, job.FileName)
+	if err == nil {
+		sc, err = h.fetchCover(ctx, row)
+	}
+	if err == nil && sc.Path != "" {
+		err = h.setCover(job.BookID, sc)
+	}
+	switch {
+	case err != nil && attempts >= maxCoverAttempts:
+		log.Printf("Giving up on the cover of %s: %v", job.FileName, err)
+		update(CoverJobFailed, attempts, time.Now(), err.Error())
+	case err != nil:
+		update(CoverJobPending, attempts, time.Now().Add(coverRetryDelay(attempts)), err.Error())
+	case sc.Path == "":
+		update(CoverJobMissing, attempts, time.Now(), "")
+	default:
+		update(CoverJobDone, attempts, time.Now(), "")
+		select {
+		case h.CM.updates <- CoverUpdate{BookID: job.BookID, FileName: job.FileName, Path: sc.Path}:
+		default:
+		}
+	}
+}
+
+// CoverJobCounts returns the number of cover jobs in each status.
+func (h *Handler) CoverJobCounts() (map[string]int64, error) {
+	rows, err := h.Queries.CountCoverJobs(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Jobs
+	}
+	return counts, nil
+}
+
+// RetryCoverJobs queues failed and not-found lookups again.
+func (h *Handler) RetryCoverJobs() (int64, error) {
+	now := jobTime(time.Now())
+	n, err := h.Queries.RequeueFailedCoverJobs(context.Background(), db.RequeueFailedCoverJobsParams{NextAttemptAt: now, UpdatedAt: now})
+	if err != nil {
+		return 0, err
+	}
+	h.CM.wakeWorker()
+	return n, nil
+}
@@ -0,0 +1,130 @@
+package metadata
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// CoverChoice is an image the cover picker offers. Ref is the entry name for
+// an EPUB image, the provider's reference for a provider cover and the file
+// path for a manual pick. Size, score and the reasons behind the score are
+// only known for EPUB images.
+type CoverChoice struct {
+	Source  string
+	Ref     string
+	Label   string
+	Width   int
+	Height  int
+	Score   int
+	Reasons []string
+}
+
+// CoverChoices lists the images inside the book, best score first, followed
+// by what the cover providers offer. A provider error is returned together
+// with the choices that were found.
+func (h *Handler) CoverChoices(ctx context.Context, fileName string) ([]CoverChoice, error) {
+	row, err := h.Queries.SelectBookByFileName(ctx, fileName)
+	if err != nil {
+		return nil, err
+	}
+	var choices []CoverChoice
+	if bookData, err := extractMetadata(h.LibraryDir, row.FileName); err != nil {
+		log.Printf("Err reading %s: %v", row.FileName, err)
+	} else if candidates, err := bookData.CoverCandidates(h.LibraryDir); err != nil {
+		log.Printf("Err listing the images of %s: %v", row.FileName, err)
+	} else {
+		for _, c := range candidates {
+			choices = append(choices, CoverChoice{
+				Source:  CoverSourceEPUB,
+				Ref:     c.Name,
+				Label:   c.Name,
+				Width:   c.Width,
+				Height:  c.Height,
+				Score:   c.Score,
+				Reasons: c.Reasons,
+			})
+		}
+	}
+
+	options, err := h.providers().CoverOptions(ctx, h.bookLookup(row))
+	for _, o := range options {
+		choices = append(choices, CoverChoice{Source: o.Source, Ref: o.Ref, Label: o.Label})
+	}
+	return choices, err
+}
+
+// PickCover stores the chosen image as the book's cover and pins it, so
+// re-imports keep it.
+func (h *Handler) PickCover(ctx context.Context, fileName string, c CoverChoice) (StoredCover, error) {
+	row, err := h.Queries.SelectBookByFileName(ctx, fileName)
+	if err != nil {
+		return StoredCover{}, err
+	}
+	var rc io.ReadCloser
+	switch c.Source {
+	case CoverSourceEPUB:
+		rc, err = openEpubEntry(filepath.Join(h.LibraryDir, row.FileName), c.Ref)
+	case CoverSourceManual:
+		rc, err = os.Open(expandHome(c.Ref))
+	default:
+		rc, err = h.providers().OpenCover(ctx, c.Source, c.Ref)
+	}
+	if err != nil {
+		return StoredCover{}, err
+	}
+	defer rc.Close()
+	sc, err := h.CM.storeCover(rc, c.Source)
+	if err != nil {
+		return StoredCover{}, err
+	}
+	sc.Pinned = true
+	if err := h.setCover(row.ID, sc); err != nil {
+		return StoredCover{}, err
+	}
+	return sc, nil
+}
+
+// epubEntry keeps the archive open while an entry is read.
+type epubEntry struct {
+	io.ReadCloser
+	archive *zip.ReadCloser
+}
+
+func (e epubEntry) Close() error {
+	return errors.Join(e.ReadCloser.Close(), e.archive.Close())
+}
+
+func openEpubEntry(bookPath, name string) (io.ReadCloser, error) {
+	z, err := zip.OpenReader(bookPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range z.File {
+		if f.Name == name {
+			rc, err := f.Open()
+			if err != nil {
+				z.Close()
+				return nil, err
+			}
+			return epubEntry{ReadCloser: rc, archive: z}, nil
+		}
+	}
+	z.Close()
+	return nil, fmt.Errorf("%s: %w", name, os.ErrNotExist)
+}
+
+func expandHome(p string) string {
+	if p == "~" || strings.HasPrefix(p, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, strings.TrimPrefix(p, "~"))
+		}
+	}
+	return p
+}
@@ -0,0 +1,241 @@
+package metadata
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	placeholderWidth  = 600
+	placeholderHeight = 900
+	placeholderMargin = 36
+	placeholderText   = 440
+)
+
+// PlaceholderStyle colors a generated cover: a vertical gradient from Top to
+// Bottom, a frame and a rule in Accent and the lettering in Text.
+type PlaceholderStyle struct {
+	Top    color.RGBA
+	Bottom color.RGBA
+	Accent color.RGBA
+	Text   color.RGBA
+}
+
+// PlaceholderCover returns a typographic cover for a book that has none,
+// drawing it on first use. Placeholders live in <covers>/placeholders, named
+// after the title and author they show and then the colors, and are never
+// linked to the book: a real cover found later replaces them simply by
+// existing.
+func (c *CoverManager) PlaceholderCover(title, author string, style PlaceholderStyle) (string, error) {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%v", style)))
+	dir := filepath.Join(c.coversDir, "placeholders")
+	path := filepath.Join(dir, placeholderPrefix(title, author)+hex.EncodeToString(sum[:8])+".png")
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	tmp, err := os.CreateTemp(dir, ".placeholder-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+	if err := png.Encode(tmp, drawPlaceholder(title, author, style)); err != nil {
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// removePlaceholders deletes the placeholders drawn for a title and author,
+// in every set of colors.
+func (c *CoverManager) removePlaceholders(title, author string) {
+	paths, _ := filepath.Glob(filepath.Join(c.coversDir, "placeholders", placeholderPrefix(title, author)+"*.png"))
+	for _, p := range paths {
+		if err := os.Remove(p); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("Err removing placeholder %s: %v", p, err)
+		}
+	}
+}
+
+func placeholderPrefix(title, author string) string {
+	sum := sha256.Sum256([]byte(title + "\x00" + author))
+	return hex.EncodeToString(sum[:16]) + "-"
+}
+
+func drawPlaceholder(title, author string, style PlaceholderStyle) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, placeholderWidth, placeholderHeight))
+	for y := 0; y < placeholderHeight; y++ {
+		row := image.Rect(0, y, placeholderWidth, y+1)
+		draw.Draw(img, row, image.NewUniform(MixColor(style.Top, style.Bottom, float64(y)/placeholderHeight)), image.Point{}, draw.Src)
+	}
+
+	accent := image.NewUniform(style.Accent)
+	m := placeholderMargin
+	for _, r := range []image.Rectangle{
+		image.Rect(m, m, placeholderWidth-m, m+3),
+		image.Rect(m, placeholderHeight-m-3, placeholderWidth-m, placeholderHeight-m),
+		image.Rect(m, m, m+3, placeholderHeight-m),
+		image.Rect(placeholderWidth-m-3, m, placeholderWidth-m, placeholderHeight-m),
+	} {
+		draw.Draw(img, r, accent, image.Point{}, draw.Src)
+	}
+
+	title, author = placeholderLetters(title, author)
+	scale, lines := fitLetters(title, 6, 2, 5)
+	y := 180
+	for _, line := range lines {
+		drawLetters(img, line, y, scale, style.Text)
+		y += (basicfont.Face7x13.Height + 3) * scale
+	}
+
+	y += 40
+	draw.Draw(img, image.Rect(placeholderWidth/2-40, y, placeholderWidth/2+40, y+4), accent, image.Point{}, draw.Src)
+
+	if author != "" {
+		scale, lines = fitLetters(author, 3, 2, 2)
+		y = placeholderHeight - 120 - len(lines)*(basicfont.Face7x13.Height+3)*scale
+		for _, line := range lines {
+			drawLetters(img, line, y, scale, style.Text)
+			y += (basicfont.Face7x13.Height + 3) * scale
+		}
+	}
+	return img
+}
+
+// placeholderLetters returns the title and author lines of a placeholder in
+// the letters the bitmap font has. A title with none of them, like "Война и
+// мир", shows the author instead, and a dinkus when neither can be drawn.
+func placeholderLetters(title, author string) (string, string) {
+	t, a := coverLetters(title), coverLetters(author)
+	switch {
+	case strings.TrimSpace(title) == "":
+		t = "UNTITLED"
+	case t == "" && a != "":
+		t, a = a, ""
+	case t == "":
+		t = "* * *"
+	}
+	return t, a
+}
+
+// fitLetters picks the largest scale, from hi down to lo, at which s, already
+// folded by coverLetters, wraps into at most maxLines lines, without splitting
+// a word that would fit whole at a smaller scale. At lo the text is cut to
+// maxLines.
+func fitLetters(s string, hi, lo, maxLines int) (int, []string) {
+	longest := 0
+	for _, word := range strings.Fields(s) {
+		longest = max(longest, len(word))
+	}
+	if longest > placeholderText/(basicfont.Face7x13.Advance*lo) {
+		longest = 0
+	}
+	for scale := hi; ; scale-- {
+		width := placeholderText / (basicfont.Face7x13.Advance * scale)
+		lines := wrapLetters(s, width)
+		if len(lines) <= maxLines && longest <= width {
+			return scale, lines
+		}
+		if scale == lo {
+			lines = lines[:maxLines]
+			last := lines[maxLines-1]
+			if len(last) > width-3 {
+				last = strings.TrimSpace(last[:width-3])
+			}
+			lines[maxLines-1] = last + "..."
+			return scale, lines
+		}
+	}
+}
+
+// coverLetters upper-cases s and folds it to the ASCII the bitmap font has.
+func coverLetters(s string) string {
+	s = foldReplacer.Replace(strings.ToLower(s))
+	var b strings.Builder
+	for _, r := range strings.ToUpper(s) {
+		if r >= ' ' && r <= '~' {
+			b.WriteRune(r)
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// wrapLetters breaks s into lines of at most width characters, splitting
+// words that do not fit on a line of their own.
+func wrapLetters(s string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(s) {
+		for len(word) > width {
+			if line != "" {
+				lines = append(lines, line)
+				line = ""
+			}
+			lines = append(lines, word[:width])
+			word = word[width:]
+		}
+		switch {
+		case line == "":
+			line = word
+		case len(line)+1+len(word) <= width:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// drawLetters draws a line centered at y, blowing each pixel of the 7x13
+// bitmap font up to a scale x scale block.
+func drawLetters(img *image.RGBA, line string, y, scale int, c color.RGBA) {
+	face := basicfont.Face7x13
+	mask := image.NewAlpha(image.Rect(0, 0, len(line)*face.Advance, face.Height))
+	d := font.Drawer{Dst: mask, Src: image.Opaque, Face: face, Dot: fixed.P(0, face.Ascent)}
+	d.DrawString(line)
+
+	x := (placeholderWidth - mask.Bounds().Dx()*scale) / 2
+	fill := image.NewUniform(c)
+	for my := 0; my < face.Height; my++ {
+		for mx := 0; mx < mask.Bounds().Dx(); mx++ {
+			if mask.AlphaAt(mx, my).A == 0 {
+				continue
+			}
+			block := image.Rect(x+mx*scale, y+my*scale, x+(mx+1)*scale, y+(my+1)*scale)
+			draw.Draw(img, block, fill, image.Point{}, draw.Src)
+		}
+	}
+}
+
+// MixColor blends a into b, t running from 0 (all a) to 1 (all b).
+func MixColor(a, b color.RGBA, t float64) color.RGBA {
+	mix := func(x, y uint8) uint8 { return uint8(float64(x) + (float64(y)-float64(x))*t) }
+	return color.RGBA{R: mix(a.R, b.R), G: mix(a.G, b.G), B: mix(a.B, b.B), A: 255}
+}
@@ -0,0 +1,78 @@
+package metadata
+
+import (
+	"errors"
+	"image/color"
+	"os"
+	"testing"
+)
+
+func TestPlaceholderLetters(t *testing.T) {
+	tests := []struct {
+		title, author string
+		wantTitle     string
+		wantAuthor    string
+	}{
+		{"Cien años de soledad", "Gabriel García Márquez", "CIEN ANOS DE SOLEDAD", "GABRIEL GARCIA MARQUEZ"},
+		{"", "Frank Herbert", "UNTITLED", "FRANK HERBERT"},
+		{"ノルウェイの森", "Haruki Murakami", "HARUKI MURAKAMI", ""},
+		{"Война и мир", "Лев Толстой", "* * *", ""},
+		{"Война и мир", "", "* * *", ""},
+		{"1984", "Джордж Оруэлл", "1984", ""},
+	}
+	for _, tt := range tests {
+		title, author := placeholderLetters(tt.title, tt.author)
+		if title != tt.wantTitle || author != tt.wantAuthor {
+			t.Errorf("placeholderLetters(%q, %q) = %q, %q; want %q, %q", tt.title, tt.author, title, author, tt.wantTitle, tt.wantAuthor)
+		}
+	}
+}
+
+func TestRemoveBookDeletesPlaceholders(t *testing.T) {
+	h := newTestHandler(t)
+	src := t.TempDir()
+	files := []string{
+		writeTestEPUB(t, src, "dune.epub", "Dune", "Frank Herbert", "one"),
+		writeTestEPUB(t, src, "dune-copy.epub", "Dune", "Frank Herbert", "two"),
+		writeTestEPUB(t, src, "other.epub", "Other", "Someone", "three"),
+	}
+	if _, err := h.ImportFiles(files, DuplicateKeep); err != nil {
+		t.Fatal(err)
+	}
+	dark := PlaceholderStyle{Top: color.RGBA{A: 255}, Bottom: color.RGBA{A: 255}}
+	light := PlaceholderStyle{Top: color.RGBA{R: 255, G: 255, B: 255, A: 255}}
+	var dune []string
+	for _, style := range []PlaceholderStyle{dark, light} {
+		p, err := h.CM.PlaceholderCover("Dune", "Frank Herbert", style)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dune = append(dune, p)
+	}
+	other, err := h.CM.PlaceholderCover("Other", "Someone", dark)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Another book still shows the same placeholders.
+	if _, err := h.RemoveBook("dune.epub", false); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range append(dune, other) {
+		if _, err := os.Stat(p); err != nil {
+			t.Errorf("%s removed while still in use: %v", p, err)
+		}
+	}
+
+	if _, err := h.RemoveBook("dune-copy.epub", false); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range dune {
+		if _, err := os.Stat(p); !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("%s kept after its last book was removed: %v", p, err)
+		}
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("placeholder of another book removed: %v", err)
+	}
+}
@@ -2,113 +2,175 @@ package metadata
 
 import (
 	"archive/zip"
+	"fmt"
+	"image"
 	"image/color"
-	"image/jpeg"
 	"log"
 	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 )
 
-func (p *Package) GoodQualityCover() (finalPath string) {
-	bookPath := ("./books/" + p.BookFile)
-	dimensionCap := 2.0 / 3.0
-	uniqueColors := 5
-	winner := ""
-	bestScore := 0
-	ignoredNameTokens := []string{
-		"title",
-		"endpaper",
-		"endpapers",
-		"backad",
-		"adcard",
-		"abouttheauthor",
-		"newsletter",
-		"contents",
-		"toc",
-		"copyright",
-		"frontmatter",
-		"backmatter",
-	}
-	possibleCovers := make([]*zip.File, 0)
-	r, err := zip.OpenReader(bookPath)
+const (
+	coverDimensionCap = 2.0 / 3.0
+	coverUniqueColors = 5
+)
+
+var ignoredCoverTokens = []string{
+	"title",
+	"endpaper",
+	"endpapers",
+	"backad",
+	"adcard",
+	"abouttheauthor",
+	"newsletter",
+	"contents",
+	"toc",
+	"copyright",
+	"frontmatter",
+	"backmatter",
+}
+
+// CoverCandidate is an image inside an EPUB. Score is 0 when the image is
+// ruled out; Reasons says why, or what the score is made of.
+type CoverCandidate struct {
+	Name    string
+	Format  string
+	Width   int
+	Height  int
+	Score   int
+	Reasons []string
+}
+
+func (c CoverCandidate) String() string {
+	return fmt.Sprintf("%s %dx%d score %d (%s)", c.Name, c.Width, c.Height, c.Score, strings.Join(c.Reasons, ", "))
+}
+
+// GoodQualityCover returns the best scoring image inside the EPUB, or "" when
+// every image is ruled out.
+func (p *Package) GoodQualityCover(libraryDir string) (finalPath string) {
+	ranked, err := p.CoverCandidates(libraryDir)
 	if err != nil {
 		log.Printf("Err opening .epub file: %v", err)
 		return ""
 	}
+	if len(ranked) == 0 || ranked[0].Score == 0 {
+		return ""
+	}
+	if len(ranked) > 1 {
+		log.Printf("Cover of %s: %v, over %v", p.BookFile, ranked[0], ranked[1])
+	}
+	return ranked[0].Name
+}
+
+// CoverCandidates ranks every JPEG, PNG, GIF and WebP image in the EPUB, best
+// score first. Images that do not decode are left out.
+func (p *Package) CoverCandidates(libraryDir string) ([]CoverCandidate, error) {
+	r, err := zip.OpenReader(filepath.Join(libraryDir, p.BookFile))
+	if err != nil {
+		return nil, err
+	}
 	defer r.Close()
 
+	var out []CoverCandidate
 	for _, z := range r.File {
-		if strings.HasSuffix(z.Name, ".jpg") || strings.HasSuffix(z.Name, "jpeg") {
-			lowerName := strings.ToLower(z.Name)
-			skip := false
-			for _, token := range ignoredNameTokens {
-				if strings.Contains(lowerName, token) {
-					skip = true
-					break
-				}
-			}
-			if skip {
-				continue
-			}
-			rc, err := z.Open()
-			if err != nil {
-				return ""
-			}
-			imageCover, err := jpeg.DecodeConfig(rc)
-			rc.Close()
-			if err != nil {
-				return ""
-			}
-			switch {
-			case imageCover.Width <= 400 || imageCover.Height <= 600:
-				continue
-			case float64(imageCover.Width)/float64(imageCover.Height) > dimensionCap:
-				continue
-			case imageCover.Width == imageCover.Height:
-				continue
-			default:
-				possibleCovers = append(possibleCovers, z)
-			}
+		switch strings.ToLower(path.Ext(z.Name)) {
+		case ".jpg", ".jpeg", ".png", ".gif", ".webp":
+		default:
+			continue
 		}
-	}
-
-	for _, c := range possibleCovers {
-		currentScore := 0
-		rc, err := c.Open()
+		rc, err := z.Open()
 		if err != nil {
-			return ""
+			continue
 		}
-		coverDecoding, err := jpeg.Decode(rc)
+		cfg, format, err := image.DecodeConfig(rc)
 		rc.Close()
 		if err != nil {
-			return ""
+			log.Printf("Err decoding %s in %s: %v", z.Name, p.BookFile, err)
+			continue
 		}
-		currentScore = coverDecoding.Bounds().Dx() * coverDecoding.Bounds().Dy()
-		if p.InternalCoverPath != "" && strings.EqualFold(path.Clean(c.Name), path.Clean(p.InternalCoverPath)) {
-			currentScore *= 4
+		c := CoverCandidate{Name: z.Name, Format: format, Width: cfg.Width, Height: cfg.Height}
+		if token := coverIgnoredToken(z.Name); token != "" {
+			c.Reasons = []string{"name contains " + strconv.Quote(token)}
+			out = append(out, c)
+			continue
 		}
-		if strings.Contains(c.Name, "cover") {
-			currentScore *= 2
+		if problem := coverShapeProblem(c.Width, c.Height); problem != "" {
+			c.Reasons = []string{problem}
+			out = append(out, c)
+			continue
 		}
-		if float64(coverDecoding.Bounds().Dx())/float64(coverDecoding.Bounds().Dy()) == dimensionCap {
-			currentScore *= 3
+		if rc, err = z.Open(); err != nil {
+			continue
 		}
-
-		colorMap := make(map[color.Color]int)
-		for i := coverDecoding.Bounds().Min.X; i < coverDecoding.Bounds().Max.X; i += 50 {
-			for j := coverDecoding.Bounds().Min.Y; j < coverDecoding.Bounds().Max.Y; j += 50 {
-				colorMap[coverDecoding.At(i, j)] += 1
-			}
-		}
-		if len(colorMap) >= uniqueColors {
-			currentScore += len(colorMap)
+		img, _, err := image.Decode(rc)
+		rc.Close()
+		if err != nil {
+			log.Printf("Err decoding %s in %s: %v", z.Name, p.BookFile, err)
+			continue
 		}
+		c.Score, c.Reasons = p.scoreCover(z.Name, img)
+		out = append(out, c)
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Score > out[j].Score })
+	return out, nil
+}
 
-		if currentScore > bestScore {
-			bestScore = currentScore
-			winner = c.Name
+// coverIgnoredToken returns the token that marks the file as a title page, an
+// ad or other front and back matter, if any.
+func coverIgnoredToken(name string) string {
+	lowerName := strings.ToLower(name)
+	for _, token := range ignoredCoverTokens {
+		if strings.Contains(lowerName, token) {
+			return token
 		}
+	}
+	return ""
+}
 
+// coverShapeProblem rules out small, wide and square images.
+func coverShapeProblem(width, height int) string {
+	switch {
+	case width <= 400 || height <= 600:
+		return "400x600 or smaller"
+	case float64(width)/float64(height) > coverDimensionCap:
+		return "wider than 2:3"
+	case width == height:
+		return "square"
+	}
+	return ""
+}
+
+// scoreCover favours large images, the cover the OPF points at, files named
+// "cover", an exact 2:3 page and images that are not a single flat color.
+func (p *Package) scoreCover(name string, img image.Image) (int, []string) {
+	bounds := img.Bounds()
+	score := bounds.Dx() * bounds.Dy()
+	reasons := []string{strconv.Itoa(score) + " px"}
+	if p.InternalCoverPath != "" && strings.EqualFold(path.Clean(name), path.Clean(p.InternalCoverPath)) {
+		score *= 4
+		reasons = append(reasons, "OPF cover x4")
+	}
+	if strings.Contains(name, "cover") {
+		score *= 2
+		reasons = append(reasons, "named cover x2")
+	}
+	if float64(bounds.Dx())/float64(bounds.Dy()) == coverDimensionCap {
+		score *= 3
+		reasons = append(reasons, "exactly 2:3 x3")
+	}
+
+	colorMap := make(map[color.Color]int)
+	for i := bounds.Min.X; i < bounds.Max.X; i += 50 {
+		for j := bounds.Min.Y; j < bounds.Max.Y; j += 50 {
+			colorMap[img.At(i, j)] += 1
+		}
+	}
+	if len(colorMap) >= coverUniqueColors {
+		score += len(colorMap)
+		reasons = append(reasons, "+"+strconv.Itoa(len(colorMap))+" colors")
 	}
-	return winner
+	return score, reasons
 }
@@ -0,0 +1,208 @@
+package metadata
+
+import (
+	"Kindria/internal/core/db"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	CoverSourceEPUB        = "epub"
+	CoverSourceOpenLibrary = "openlibrary"
+	CoverSourceLocal       = "local"
+	CoverSourceManual      = "manual"
+)
+
+// maxCoverSize caps how much of a cover is read into memory.
+const maxCoverSize = 32 << 20
+
+// StoredCover is an image in the cover store. Files are named after the
+// SHA-256 of their content, so books sharing a cover share the file and a
+// title never ends up in a path. A pinned cover was picked by hand and is
+// kept when the book is re-imported.
+type StoredCover struct {
+	Path   string
+	Hash   string
+	Format string
+	Source string
+	Width  int
+	Height int
+	Pinned bool
+}
+
+// storeCover writes the image read from r into the covers directory, keeping
+// its real format. The file is written to a temporary name and renamed, so
+// readers never see a partial image.
+func (c *CoverManager) storeCover(r io.Reader, source string) (StoredCover, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxCoverSize+1))
+	if err != nil {
+		return StoredCover{}, err
+	}
+	if len(data) > maxCoverSize {
+		return StoredCover{}, errors.New("cover image is too large")
+	}
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return StoredCover{}, fmt.Errorf("unsupported cover image: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+	ext := format
+	if ext == "jpeg" {
+		ext = "jpg"
+	}
+	sc := StoredCover{
+		Path:   filepath.Join(c.coversDir, hash+"."+ext),
+		Hash:   hash,
+		Format: format,
+		Source: source,
+		Width:  cfg.Width,
+		Height: cfg.Height,
+	}
+	if _, err := os.Stat(sc.Path); err == nil {
+		return sc, nil
+	}
+
+	if err := os.MkdirAll(c.coversDir, 0o755); err != nil {
+		return StoredCover{}, err
+	}
+	tmp, err := os.CreateTemp(c.coversDir, ".cover-*")
+	if err != nil {
+		return StoredCover{}, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+	if _, err := tmp.Write(data); err != nil {
+		return StoredCover{}, err
+	}
+	if err := tmp.Sync(); err != nil {
+		return StoredCover{}, err
+	}
+	if err := tmp.Close(); err != nil {
+		return StoredCover{}, err
+	}
+	if err := os.Rename(tmp.Name(), sc.Path); err != nil {
+		return StoredCover{}, err
+	}
+	return sc, nil
+}
+
+func recordCover(ctx context.Context, q *db.Queries, bookID int64, sc StoredCover) error {
+	var pinned int64
+	if sc.Pinned {
+		pinned = 1
+	}
+	return q.UpsertCover(ctx, db.UpsertCoverParams{
+		BookID:    bookID,
+		Hash:      sc.Hash,
+		Path:      sc.Path,
+		Format:    sc.Format,
+		Source:    sc.Source,
+		Width:     int64(sc.Width),
+		Height:    int64(sc.Height),
+		UpdatedAt: time.Now().Format("2006-01-02 15:04:05"),
+		Pinned:    pinned,
+	})
+}
+
+// setCover points a book at a stored cover and drops the previous file when
+// no other book uses it.
+func (h *Handler) setCover(bookID int64, sc StoredCover) error {
+	ctx := context.Background()
+	previous := ""
+	if current, err := h.Queries.SelectCover(ctx, bookID); err == nil {
+		previous = current.Path
+	}
+	tx, err := h.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	qtx := h.Queries.WithTx(tx)
+	if err := recordCover(ctx, qtx, bookID, sc); err != nil {
+		return err
+	}
+	if err := qtx.UpdateBookCover(ctx, db.UpdateBookCoverParams{Bookpath: sc.Path, ID: bookID}); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+	if previous != "" && previous != sc.Path {
+		h.removeUnusedCover(previous)
+	}
+	return nil
+}
+
+// BackfillCovers moves covers cached before the cover store existed, which
+// were named after the book title, into the store. Their source is unknown,
+// but only covers taken from the EPUB were ever linked to a book. Books whose
+// cached cover is gone are queued for the cover worker instead.
+func (h *Handler) BackfillCovers() (int, error) {
+	ctx := context.Background()
+	rows, err := h.Queries.SelectUntrackedCovers(ctx)
+	if err != nil {
+		return 0, err
+	}
+	moved, queued := 0, false
+	defer func() {
+		if queued {
+			h.CM.wakeWorker()
+		}
+	}()
+	for _, row := range rows {
+		f, err := os.Open(row.Bookpath)
+		if errors.Is(err, os.ErrNotExist) {
+			if err := h.Queries.UpdateBookCover(ctx, db.UpdateBookCoverParams{Bookpath: "", ID: row.ID}); err != nil {
+				log.Printf("Err clearing missing cover %s: %v", row.Bookpath, err)
+				continue
+			}
+			if err := queueCover(ctx, h.Queries, row.ID); err != nil {
+				log.Printf("Err queueing cover of book %d: %v", row.ID, err)
+				continue
+			}
+			queued = true
+			continue
+		}
+		if err != nil {
+			log.Printf("Err opening cover %s: %v", row.Bookpath, err)
+			continue
+		}
+		sc, err := h.CM.storeCover(f, CoverSourceEPUB)
+		f.Close()
+		if err != nil {
+			log.Printf("Err storing cover %s: %v", row.Bookpath, err)
+			continue
+		}
+		if err := h.setCover(row.ID, sc); err != nil {
+			log.Printf("Err linking cover %s: %v", row.Bookpath, err)
+			continue
+		}
+		// The old cache may live outside the covers directory, so its copy is
+		// removed here rather than by removeUnusedCover.
+		if row.Bookpath != sc.Path {
+			if n, err := h.Queries.CountBooksByCover(ctx, row.Bookpath); err == nil && n == 0 {
+				if err := os.Remove(row.Bookpath); err != nil && !errors.Is(err, os.ErrNotExist) {
+					log.Printf("Err removing cover %s: %v", row.Bookpath, err)
+				}
+			}
+		}
+		moved++
+	}
+	return moved, nil
+}
@@ -0,0 +1,107 @@
+package metadata
+
+import (
+	"Kindria/internal/core/db"
+	"context"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBackfillCovers(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	src := t.TempDir()
+	files := []string{
+		writeTestEPUB(t, src, "kept.epub", "Kept", "Someone", "kept"),
+		writeTestEPUB(t, src, "lost.epub", "Lost", "Someone", "lost"),
+	}
+	if _, err := h.ImportFiles(files, DuplicateSkip); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := h.DB.Exec("DELETE FROM cover_jobs"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Old covers were stored relative to the working directory.
+	t.Chdir(t.TempDir())
+	if err := os.MkdirAll(filepath.Join("cache", "covers"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	legacy := "./cache/covers/Kept.png"
+	f, err := os.Create(legacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := png.Encode(f, image.NewRGBA(image.Rect(0, 0, 2, 3))); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	for file, path := range map[string]string{"kept.epub": legacy, "lost.epub": "./cache/covers/Lost.jpg"} {
+		row, err := h.Queries.SelectBookByFileName(ctx, file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := h.Queries.UpdateBookCover(ctx, db.UpdateBookCoverParams{Bookpath: path, ID: row.ID}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	moved, err := h.BackfillCovers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if moved != 1 {
+		t.Errorf("moved = %d, want 1", moved)
+	}
+	kept, err := h.Queries.SelectBookByFileName(ctx, "kept.epub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filepath.Dir(kept.Bookpath) != h.CM.coversDir {
+		t.Errorf("kept cover = %s, want a file in %s", kept.Bookpath, h.CM.coversDir)
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Errorf("old cover still on disk: %v", err)
+	}
+	lost, err := h.Queries.SelectBookByFileName(ctx, "lost.epub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lost.Bookpath != "" {
+		t.Errorf("lost cover = %q, want it cleared", lost.Bookpath)
+	}
+	counts, err := h.CoverJobCounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts[CoverJobPending] != 1 {
+		t.Errorf("pending cover jobs = %d, want 1 for the book whose cover is gone", counts[CoverJobPending])
+	}
+}
+
+func TestRemoveUnusedCoverResolvesRelativePaths(t *testing.T) {
+	h := newTestHandler(t)
+	dir := t.TempDir()
+	t.Chdir(dir)
+	h.CM.coversDir = filepath.Join(dir, "cache", "covers")
+	if err := os.MkdirAll(h.CM.coversDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	inside := "./cache/covers/old.jpg"
+	outside := "./old.jpg"
+	for _, p := range []string{inside, outside} {
+		if err := os.WriteFile(p, []byte("cover"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		h.removeUnusedCover(p)
+	}
+	if _, err := os.Stat(inside); !os.IsNotExist(err) {
+		t.Errorf("%s was kept, want it removed", inside)
+	}
+	if _, err := os.Stat(outside); err != nil {
+		t.Errorf("%s outside the covers directory was touched: %v", outside, err)
+	}
+}
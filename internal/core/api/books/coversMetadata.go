@@ -1,74 +1,42 @@
 package metadata
 
 import (
+	"Kindria/internal/core/db"
 	"archive/zip"
-	"io"
+	"context"
+	"errors"
 	"log"
-	"net/http"
-	"net/url"
-	"os"
-	"strconv"
+	"path/filepath"
 	"strings"
-	"time"
 )
 
-func (c *CoverManager) ProcessCover(p *Package) (string, error) {
-	finalPath := ("./cache/covers/" + strings.ReplaceAll(p.Metadata.Title, " ", "_") + ".jpg")
-	initialPath := p.GoodQualityCover()
+// ProcessCover stores the cover found inside the EPUB. The returned cover has
+// an empty Path when the book has none; the caller queues it for Open Library
+// (queueCover) once the book has an ID.
+func (c *CoverManager) ProcessCover(p *Package) (StoredCover, error) {
+	initialPath := p.GoodQualityCover(c.libraryDir)
 	if initialPath != "" {
-		coverEpubPath, err := p.extractCoverFromEpub(initialPath)
+		sc, err := c.extractCoverFromEpub(p, initialPath)
 		if err != nil {
 			log.Printf("Eror trying to call extractCoverFromEpub func: %v", err)
 		}
-		if coverEpubPath != "" {
-			return coverEpubPath, nil
+		if sc.Path != "" {
+			return sc, nil
 		}
 	}
 
 	if p.InternalCoverPath != "" {
-		coverEpubPath, err := p.extractCoverFromEpub(p.InternalCoverPath)
-		if err != nil {
-			return "", err
-		}
-		if coverEpubPath != "" {
-			return coverEpubPath, nil
-		}
-	}
-
-	_, err := os.Stat(finalPath)
-	if err == nil {
-		return finalPath, nil
-	}
-
-	if p.InternalCoverPath == "" {
-		c.coversQueue <- p
-		return "", nil
-	}
-
-	tempCoverEpubPath, err := p.extractCoverFromEpub(p.InternalCoverPath)
-	if err != nil {
-		return "", err
-	}
-	c.coversQueue <- p
-	return tempCoverEpubPath, nil
-}
-
-func (h *Handler) UpdateCacheCovers() error {
-	for book := range h.CM.coversQueue {
-		book.extractCoverFromApi()
-		time.Sleep(3 * time.Second)
+		return c.extractCoverFromEpub(p, p.InternalCoverPath)
 	}
-
-	return nil
+	return StoredCover{}, nil
 }
 
-func (p *Package) extractCoverFromEpub(path string) (string, error) {
-	finalPath := ("./cache/covers/" + strings.ReplaceAll(p.Metadata.Title, " ", "_") + ".jpg")
-	completePath := "./books/" + p.BookFile
+func (c *CoverManager) extractCoverFromEpub(p *Package, path string) (StoredCover, error) {
+	completePath := filepath.Join(c.libraryDir, p.BookFile)
 	z, err := zip.OpenReader(completePath)
 	if err != nil {
 		log.Printf("Error opening .epub file: %v", err)
-		return "", err
+		return StoredCover{}, err
 	}
 
 	defer z.Close()
@@ -77,63 +45,26 @@ func (p *Package) extractCoverFromEpub(path string) (string, error) {
 		if strings.EqualFold(f.Name, path) {
 			rc, err := f.Open()
 			if err != nil {
-				return "", err
-			}
-			file, err := os.Create(finalPath)
-			if err != nil {
-				log.Printf("Err creating file for the cover: %v", err)
-				return "", err
+				return StoredCover{}, err
 			}
-			_, err = io.Copy(file, rc)
-			if err != nil {
-				return "", err
-			}
-			break
+			defer rc.Close()
+			return c.storeCover(rc, CoverSourceEPUB)
 		}
 	}
-	return finalPath, err
+	return StoredCover{}, nil
 }
 
-func (p *Package) extractCoverFromApi() (string, error) {
-	finalPath := ("./cache/covers/" + strings.ReplaceAll(p.Metadata.Title, " ", "_") + ".jpg")
-	cover_i, err := SearchOpenLibrary(p.Metadata.Title, p.Metadata.Author)
-	if err != nil {
-		log.Printf("Err getting cover_i for Covers API: %v", err)
-		return "", err
-	}
-	if cover_i == 0 {
-		return "", nil
-	}
-	u, err := url.JoinPath("https://covers.openlibrary.org/b/id/", strconv.Itoa(cover_i)+".jpg")
-	if err != nil {
-		log.Printf("Error trying to parse base url: %v", err)
-		return "", err
-	}
-	req, err := http.NewRequest(http.MethodGet, u, nil)
-	if err != nil {
-		return "", err
-	}
-	contact := os.Getenv("OLContact")
-	req.Header.Set("User-Agent", "Kindria/0.1 (contact: "+contact+")")
-
-	client := &http.Client{Timeout: 5 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return "", err
-	}
-
-	defer resp.Body.Close()
-
-	file, err := os.Create(finalPath)
-	if err != nil {
-		log.Printf("Err creating file for the cover: %v", err)
-		return "", err
+// fetchCover asks the cover providers for the book's cover and stores the
+// first one found. The returned cover has an empty Path when there is none.
+func (h *Handler) fetchCover(ctx context.Context, row db.Book) (StoredCover, error) {
+	rc, source, err := h.providers().Cover(ctx, h.bookLookup(row))
+	if errors.Is(err, ErrNotFound) {
+		return StoredCover{}, nil
 	}
-
-	_, err = io.Copy(file, resp.Body)
 	if err != nil {
-		log.Printf("Error saving cover: %v", err)
-		return "", err
+		log.Printf("Err fetching the cover of %s: %v", row.FileName, err)
+		return StoredCover{}, err
 	}
-	return finalPath, nil
+	defer rc.Close()
+	return h.CM.storeCover(rc, source)
 }
@@ -0,0 +1,166 @@
+package metadata
+
+import (
+	"Kindria/internal/core/db"
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	FieldDescription = "Description"
+	FieldSubjects    = "Subjects"
+	FieldPublishYear = "First published"
+	FieldPageCount   = "Pages"
+	FieldISBNs       = "ISBNs"
+	FieldSeries      = "Series"
+)
+
+// maxSubjects caps how many subjects become genres; Open Library works often
+// carry dozens of them.
+const maxSubjects = 8
+
+// FieldChange is one proposed value. Accept starts true and decides whether
+// ApplyEnrichment stores it.
+type FieldChange struct {
+	Field  string
+	Old    string
+	New    string
+	Accept bool
+}
+
+// Enrichment holds what the metadata providers know about a book that the
+// library does not. Only fields that are empty in the database are proposed.
+type Enrichment struct {
+	BookID   int64
+	FileName string
+	Title    string
+	Work     string
+	Changes  []FieldChange
+
+	description string
+	genres      []string
+	publishYear int64
+	pageCount   int64
+	isbns       []string
+	series      string
+	seriesIndex float64
+}
+
+// EnrichBook asks the metadata providers about a book. An enrichment without
+// Changes means there was no match or nothing new.
+func (h *Handler) EnrichBook(ctx context.Context, fileName string) (*Enrichment, error) {
+	row, err := h.Queries.SelectBookByFileName(ctx, fileName)
+	if err != nil {
+		return nil, err
+	}
+	e := &Enrichment{BookID: row.ID, FileName: row.FileName, Title: row.Title}
+	md, err := h.providers().Metadata(ctx, h.bookLookup(row))
+	if errors.Is(err, ErrNotFound) {
+		return e, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	e.Work = md.Work
+
+	propose := func(field, value string) {
+		e.Changes = append(e.Changes, FieldChange{Field: field, New: value, Accept: true})
+	}
+	if row.Description == "" && md.Description != "" {
+		e.description = md.Description
+		propose(FieldDescription, md.Description)
+	}
+	if row.Genres == "" {
+		if s := cleanSubjects(md.Subjects); len(s) > 0 {
+			e.genres = s
+			propose(FieldSubjects, strings.Join(s, ", "))
+		}
+	}
+	if row.PublishYear == 0 && md.PublishYear > 0 {
+		e.publishYear = int64(md.PublishYear)
+		propose(FieldPublishYear, strconv.Itoa(md.PublishYear))
+	}
+	if row.PageCount == 0 && md.PageCount > 0 {
+		e.pageCount = int64(md.PageCount)
+		propose(FieldPageCount, strconv.Itoa(md.PageCount))
+	}
+	if row.Isbns == "" && len(md.ISBNs) > 0 {
+		e.isbns = md.ISBNs
+		propose(FieldISBNs, strings.Join(md.ISBNs, ", "))
+	}
+	if row.Series.String == "" && md.Series != "" {
+		e.series, e.seriesIndex = md.Series, md.SeriesIndex
+		propose(FieldSeries, MetaData{Series: md.Series, SeriesIndex: md.SeriesIndex}.SeriesLabel())
+	}
+	return e, nil
+}
+
+// cleanSubjects drops the machine-oriented subjects ("nyt:...=...",
+// "series:...") and ones with commas, which would split when stored as genres.
+func cleanSubjects(subjects []string) []string {
+	var out []string
+	for _, s := range subjects {
+		if strings.ContainsAny(s, ":=,") {
+			continue
+		}
+		out = append(out, s)
+	}
+	out = normalizeGenres(out)
+	if len(out) > maxSubjects {
+		out = out[:maxSubjects]
+	}
+	return out
+}
+
+// ApplyEnrichment stores the accepted changes. The book is stamped as
+// enriched even when every change was rejected, so batch runs do not offer it
+// again.
+func (h *Handler) ApplyEnrichment(e *Enrichment) error {
+	ctx := context.Background()
+	row, err := h.Queries.SelectBookByFileName(ctx, e.FileName)
+	if err != nil {
+		return err
+	}
+	params := db.UpdateBookEnrichmentParams{
+		Description: row.Description,
+		Genres:      row.Genres,
+		PublishYear: row.PublishYear,
+		PageCount:   row.PageCount,
+		Isbns:       row.Isbns,
+		Series:      row.Series,
+		SeriesIndex: row.SeriesIndex,
+		OlWork:      e.Work,
+		EnrichedAt:  time.Now().Format("2006-01-02 15:04:05"),
+		ID:          row.ID,
+	}
+	for _, c := range e.Changes {
+		if !c.Accept {
+			continue
+		}
+		switch c.Field {
+		case FieldDescription:
+			params.Description = e.description
+		case FieldSubjects:
+			params.Genres = strings.Join(e.genres, ",")
+		case FieldPublishYear:
+			params.PublishYear = e.publishYear
+		case FieldPageCount:
+			params.PageCount = e.pageCount
+		case FieldISBNs:
+			params.Isbns = strings.Join(e.isbns, ",")
+		case FieldSeries:
+			params.Series = sql.NullString{String: e.series, Valid: true}
+			params.SeriesIndex = e.seriesIndex
+		}
+	}
+	return h.Queries.UpdateBookEnrichment(ctx, params)
+}
+
+// BooksToEnrich lists the books a batch run has not looked up yet.
+func (h *Handler) BooksToEnrich() ([]string, error) {
+	return h.Queries.SelectBooksToEnrich(context.Background())
+}
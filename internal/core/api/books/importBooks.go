@@ -0,0 +1,154 @@
+package metadata
+
+import (
+	"Kindria/internal/core/db"
+	"Kindria/internal/utils"
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+type ImportResult struct {
+	Inserted   []string
+	Replaced   []string
+	Failed     []string
+	Duplicated int
+	Conflicts  []Conflict
+	Refreshed  []*Package
+}
+
+// ImportFiles copies the given EPUBs into the library. Files whose content is
+// already in the library are skipped; files that only share the title and
+// author of a book are handled according to policy. A different book whose
+// filename is taken is stored under a numbered name instead. Title and author
+// are matched against the library and against the files imported before in
+// the same batch.
+func (h *Handler) ImportFiles(files []string, policy DuplicatePolicy) (ImportResult, error) {
+	var result ImportResult
+	ctx := context.Background()
+	booksFolder, err := os.ReadDir(h.LibraryDir)
+	if err != nil {
+		return result, err
+	}
+	existingNames := make(map[string]struct{}, len(booksFolder))
+	for _, b := range booksFolder {
+		existingNames[b.Name()] = struct{}{}
+	}
+	fileNames, err := h.Queries.SelectFileNames(ctx)
+	if err != nil {
+		return result, err
+	}
+	for _, f := range fileNames {
+		existingNames[f] = struct{}{}
+	}
+
+	result.Inserted = make([]string, 0, len(files))
+	result.Failed = make([]string, 0)
+	seenHashes := make(map[string]struct{}, len(files))
+	// seenKeys holds the files of this batch copied into the library, which
+	// are only inserted once the whole batch is done.
+	type copiedFile struct {
+		src, name, title string
+	}
+	seenKeys := make(map[string]copiedFile, len(files))
+	for _, book := range files {
+		hash, err := FileHash(book)
+		if err != nil {
+			result.Failed = append(result.Failed, book)
+			continue
+		}
+		if _, ok := seenHashes[hash]; ok {
+			result.Duplicated++
+			continue
+		}
+		seenHashes[hash] = struct{}{}
+		if _, err := h.Queries.SelectBookByContentHash(ctx, hash); err == nil {
+			result.Duplicated++
+			continue
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			result.Failed = append(result.Failed, book)
+			continue
+		}
+
+		bookData, err := extractMetadata(filepath.Dir(book), filepath.Base(book))
+		if err != nil {
+			result.Failed = append(result.Failed, book)
+			continue
+		}
+		key := DedupKey(bookData.Metadata.Title, bookData.Metadata.PrimaryAuthor())
+		if key != "" {
+			existing, err := h.Queries.SelectBookByDedupKey(ctx, key)
+			earlier, inBatch := seenKeys[key]
+			if errors.Is(err, sql.ErrNoRows) && inBatch {
+				existing, err = db.Book{FileName: earlier.name, Title: earlier.title}, nil
+			} else {
+				inBatch = false
+			}
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+			case err != nil:
+				result.Failed = append(result.Failed, book)
+				continue
+			case policy == DuplicateAsk:
+				result.Conflicts = append(result.Conflicts, Conflict{
+					Path:          book,
+					Title:         bookData.Metadata.Title,
+					ExistingFile:  existing.FileName,
+					ExistingTitle: existing.Title,
+				})
+				continue
+			case policy == DuplicateReplace && inBatch:
+				// The earlier file is not in the database yet, so it is
+				// dropped and this one is imported in its place.
+				if err := os.Remove(filepath.Join(h.LibraryDir, earlier.name)); err != nil {
+					result.Failed = append(result.Failed, book)
+					continue
+				}
+				delete(existingNames, earlier.name)
+				delete(seenKeys, key)
+				result.Inserted = slices.DeleteFunc(result.Inserted, func(f string) bool { return f == earlier.src })
+				result.Duplicated++
+			case policy == DuplicateReplace:
+				if err := h.replaceBook(existing, book, hash, existingNames); err != nil {
+					result.Failed = append(result.Failed, book)
+					continue
+				}
+				result.Replaced = append(result.Replaced, book)
+				continue
+			case policy != DuplicateKeep:
+				result.Duplicated++
+				continue
+			}
+		}
+
+		filename := uniqueFileName(existingNames, filepath.Base(book))
+		if err := utils.CopyFile(book, filepath.Join(h.LibraryDir, filename)); err != nil {
+			result.Failed = append(result.Failed, book)
+			continue
+		}
+		existingNames[filename] = struct{}{}
+		if key != "" {
+			if _, ok := seenKeys[key]; !ok {
+				seenKeys[key] = copiedFile{src: book, name: filename, title: bookData.Metadata.Title}
+			}
+		}
+		result.Inserted = append(result.Inserted, book)
+	}
+
+	if len(result.Inserted) == 0 && len(result.Replaced) == 0 {
+		return result, nil
+	}
+
+	if _, err := h.InsertBooks(); err != nil {
+		return result, err
+	}
+	refreshed, err := h.SelectBooks()
+	if err != nil {
+		return result, err
+	}
+	result.Refreshed = refreshed
+	return result, nil
+}
@@ -0,0 +1,342 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrNotFound is returned by providers that have nothing for a book.
+var ErrNotFound = errors.New("not found")
+
+// OpenLibrary is a small client for the Open Library search, works, editions
+// and covers endpoints. The URLs and the HTTP client can be swapped, e.g. for
+// an httptest server.
+type OpenLibrary struct {
+	BaseURL   string
+	CoversURL string
+	UserAgent string
+	Client    *http.Client
+}
+
+func NewOpenLibrary() *OpenLibrary {
+	return &OpenLibrary{
+		BaseURL:   "https://openlibrary.org",
+		CoversURL: "https://covers.openlibrary.org",
+		UserAgent: "Kindria/0.1 (contact: " + os.Getenv("OLContact") + ")",
+		Client:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// OLDoc is one result of /search.json.
+type OLDoc struct {
+	Key              string   `json:"key"`
+	Title            string   `json:"title"`
+	AuthorName       []string `json:"author_name"`
+	CoverI           int      `json:"cover_i"`
+	FirstPublishYear int      `json:"first_publish_year"`
+	PagesMedian      int      `json:"number_of_pages_median"`
+	ISBN             []string `json:"isbn"`
+	CoverEditionKey  string   `json:"cover_edition_key"`
+	EditionKey       []string `json:"edition_key"`
+}
+
+type olSearch struct {
+	NumFound int     `json:"numFound"`
+	Docs     []OLDoc `json:"docs"`
+}
+
+// olText is a field Open Library returns either as a string or as
+// {"type": "/type/text", "value": "..."}.
+type olText string
+
+func (t *olText) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*t = olText(s)
+		return nil
+	}
+	var v struct {
+		Value string `json:"value"`
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*t = olText(v.Value)
+	return nil
+}
+
+type OLWork struct {
+	Key         string   `json:"key"`
+	Title       string   `json:"title"`
+	Description olText   `json:"description"`
+	Subjects    []string `json:"subjects"`
+}
+
+type OLEdition struct {
+	Key           string   `json:"key"`
+	NumberOfPages int      `json:"number_of_pages"`
+	ISBN10        []string `json:"isbn_10"`
+	ISBN13        []string `json:"isbn_13"`
+	Series        []string `json:"series"`
+}
+
+func (ol *OpenLibrary) get(ctx context.Context, u string, v any) error {
+	resp, err := ol.do(ctx, u)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func (ol *OpenLibrary) do(ctx context.Context, u string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", ol.UserAgent)
+	client := ol.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		resp.Body.Close()
+		return nil, ErrNotFound
+	case resp.StatusCode != http.StatusOK:
+		resp.Body.Close()
+		return nil, fmt.Errorf("open library: %s", resp.Status)
+	}
+	return resp, nil
+}
+
+// Search looks a book up by ISBN when one is given, otherwise by title and
+// author.
+func (ol *OpenLibrary) Search(ctx context.Context, title, author, isbn string) ([]OLDoc, error) {
+	q := url.Values{}
+	if isbn != "" {
+		q.Set("isbn", isbn)
+	} else {
+		q.Set("title", title)
+		if author != "" {
+			q.Set("author", author)
+		}
+	}
+	q.Set("limit", "5")
+	q.Set("fields", "key,title,author_name,cover_i,first_publish_year,number_of_pages_median,isbn,cover_edition_key,edition_key")
+	var res olSearch
+	if err := ol.get(ctx, ol.BaseURL+"/search.json?"+q.Encode(), &res); err != nil {
+		return nil, err
+	}
+	return res.Docs, nil
+}
+
+// Work fetches a work by its key, e.g. "/works/OL27448W".
+func (ol *OpenLibrary) Work(ctx context.Context, key string) (OLWork, error) {
+	var w OLWork
+	err := ol.get(ctx, ol.BaseURL+olPath("/works/", key)+".json", &w)
+	return w, err
+}
+
+// Edition fetches an edition by its key, e.g. "OL7353617M".
+func (ol *OpenLibrary) Edition(ctx context.Context, key string) (OLEdition, error) {
+	var e OLEdition
+	err := ol.get(ctx, ol.BaseURL+olPath("/books/", key)+".json", &e)
+	return e, err
+}
+
+func olPath(prefix, key string) string {
+	key = strings.TrimPrefix(strings.TrimPrefix(key, "/works/"), "/books/")
+	return prefix + url.PathEscape(key)
+}
+
+// CoverImage downloads the large cover with the given cover id. ErrNotFound
+// means Open Library has no image for it.
+func (ol *OpenLibrary) CoverImage(ctx context.Context, coverID int) (io.ReadCloser, error) {
+	resp, err := ol.do(ctx, ol.CoversURL+"/b/id/"+strconv.Itoa(coverID)+"-L.jpg?default=false")
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// bestMatch prefers the first result whose title matches the book's.
+func bestMatch(docs []OLDoc, title string) (OLDoc, bool) {
+	if len(docs) == 0 {
+		return OLDoc{}, false
+	}
+	want := strings.Join(keyWords(title), " ")
+	for _, d := range docs {
+		if strings.Join(keyWords(d.Title), " ") == want {
+			return d, true
+		}
+	}
+	return docs[0], true
+}
+
+func (ol *OpenLibrary) Name() string { return ProviderOpenLibrary }
+
+// search tries each ISBN and falls back to title and author. The ISBN that
+// matched, if any, is returned with the result.
+func (ol *OpenLibrary) search(ctx context.Context, l Lookup) (OLDoc, string, error) {
+	for _, isbn := range l.ISBNs {
+		docs, err := ol.Search(ctx, "", "", isbn)
+		if err != nil {
+			return OLDoc{}, "", err
+		}
+		if len(docs) > 0 {
+			return docs[0], isbn, nil
+		}
+	}
+	docs, err := ol.Search(ctx, l.Title, l.Author, "")
+	if err != nil {
+		return OLDoc{}, "", err
+	}
+	doc, ok := bestMatch(docs, l.Title)
+	if !ok {
+		return OLDoc{}, "", ErrNotFound
+	}
+	return doc, "", nil
+}
+
+// Metadata reads the best match's work (description, subjects) and edition
+// (pages, ISBNs, series).
+func (ol *OpenLibrary) Metadata(ctx context.Context, l Lookup) (ProviderMetadata, error) {
+	doc, isbn, err := ol.search(ctx, l)
+	if err != nil {
+		return ProviderMetadata{}, err
+	}
+	md := ProviderMetadata{Work: doc.Key, PublishYear: doc.FirstPublishYear, PageCount: doc.PagesMedian}
+	if doc.Key != "" {
+		work, err := ol.Work(ctx, doc.Key)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return ProviderMetadata{}, err
+		}
+		md.Description = strings.TrimSpace(string(work.Description))
+		md.Subjects = work.Subjects
+	}
+	if key := editionKey(doc); key != "" {
+		edition, err := ol.Edition(ctx, key)
+		if err != nil && !errors.Is(err, ErrNotFound) {
+			return ProviderMetadata{}, err
+		}
+		if edition.NumberOfPages > 0 {
+			md.PageCount = edition.NumberOfPages
+		}
+		md.ISBNs = append(append(md.ISBNs, edition.ISBN13...), edition.ISBN10...)
+		if len(edition.Series) > 0 {
+			md.Series, md.SeriesIndex = parseOLSeries(edition.Series[0])
+		}
+	}
+	if len(md.ISBNs) == 0 && isbn != "" {
+		md.ISBNs = []string{isbn}
+	}
+	return md, nil
+}
+
+// Cover downloads the cover of the best match.
+func (ol *OpenLibrary) Cover(ctx context.Context, l Lookup) (io.ReadCloser, error) {
+	doc, _, err := ol.search(ctx, l)
+	if err != nil {
+		return nil, err
+	}
+	if doc.CoverI == 0 {
+		return nil, ErrNotFound
+	}
+	return ol.CoverImage(ctx, doc.CoverI)
+}
+
+// maxCoverOptions caps how many Open Library covers the cover picker offers.
+const maxCoverOptions = 8
+
+// CoverOptions offers the covers of the search results, ISBN matches first.
+func (ol *OpenLibrary) CoverOptions(ctx context.Context, l Lookup) ([]CoverOption, error) {
+	var docs []OLDoc
+	for _, isbn := range l.ISBNs {
+		found, err := ol.Search(ctx, "", "", isbn)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, found...)
+	}
+	found, err := ol.Search(ctx, l.Title, l.Author, "")
+	if err != nil {
+		return nil, err
+	}
+	docs = append(docs, found...)
+
+	var out []CoverOption
+	seen := make(map[int]bool)
+	for _, d := range docs {
+		if d.CoverI == 0 || seen[d.CoverI] {
+			continue
+		}
+		seen[d.CoverI] = true
+		label := d.Title
+		if len(d.AuthorName) > 0 {
+			label += " by " + d.AuthorName[0]
+		}
+		if d.FirstPublishYear > 0 {
+			label += " (" + strconv.Itoa(d.FirstPublishYear) + ")"
+		}
+		out = append(out, CoverOption{Ref: strconv.Itoa(d.CoverI), Label: label})
+		if len(out) == maxCoverOptions {
+			break
+		}
+	}
+	if len(out) == 0 {
+		return nil, ErrNotFound
+	}
+	return out, nil
+}
+
+// OpenCover downloads the cover with the id returned by CoverOptions.
+func (ol *OpenLibrary) OpenCover(ctx context.Context, ref string) (io.ReadCloser, error) {
+	id, err := strconv.Atoi(ref)
+	if err != nil {
+		return nil, fmt.Errorf("bad cover id %q", ref)
+	}
+	return ol.CoverImage(ctx, id)
+}
+
+// olSeriesIndex matches the position Open Library editions append to a series
+// name: "Discworld (5)", "The Expanse, #1", "Mistborn ; 2", "Dune -- 3".
+var olSeriesIndex = regexp.MustCompile(`(?i)^(.+?)[\s,;:-]*(?:\((?:#|no\.?|book|vol\.?)?\s*(\d+(?:\.\d+)?)\)|(?:#|no\.\s?|book\s|vol\.\s?|--\s*|[,;]\s*)(\d+(?:\.\d+)?))$`)
+
+func parseOLSeries(s string) (string, float64) {
+	s = strings.Join(strings.Fields(s), " ")
+	m := olSeriesIndex.FindStringSubmatch(s)
+	if m == nil {
+		return s, 0
+	}
+	index := m[2]
+	if index == "" {
+		index = m[3]
+	}
+	v, _ := strconv.ParseFloat(index, 64)
+	return m[1], v
+}
+
+func editionKey(doc OLDoc) string {
+	if doc.CoverEditionKey != "" {
+		return doc.CoverEditionKey
+	}
+	if len(doc.EditionKey) > 0 {
+		return doc.EditionKey[0]
+	}
+	return ""
+}
@@ -0,0 +1,138 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// newOpenLibraryStub serves canned search, works and editions responses.
+// Searches for an unknown title find nothing and ISBN 0000000000 is a 404.
+func newOpenLibraryStub(t *testing.T) *OpenLibrary {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/search.json", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch {
+		case q.Get("isbn") == "0000000000":
+			http.NotFound(w, r)
+		case q.Get("title") == "Dune":
+			fmt.Fprint(w, `{"numFound": 2, "docs": [
+				{"key": "/works/OL9W", "title": "Dune Messiah", "first_publish_year": 1969},
+				{"key": "/works/OL1W", "title": "Dune", "author_name": ["Frank Herbert"], "first_publish_year": 1965,
+				 "number_of_pages_median": 400, "cover_edition_key": "OL2M"}]}`)
+		case q.Get("title") == "Lonely":
+			fmt.Fprint(w, `{"numFound": 1, "docs": [{"key": "/works/OL404W", "title": "Lonely", "first_publish_year": 2001}]}`)
+		default:
+			fmt.Fprint(w, `{"numFound": 0, "docs": []}`)
+		}
+	})
+	mux.HandleFunc("/works/OL1W.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"key": "/works/OL1W", "title": "Dune",
+			"description": {"type": "/type/text", "value": " A desert planet. "},
+			"subjects": ["Science fiction", "nyt:hardcover=1965", "Deserts", "Science fiction"]}`)
+	})
+	mux.HandleFunc("/books/OL2M.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"key": "/books/OL2M", "number_of_pages": 412, "isbn_13": ["9780441013593"], "isbn_10": ["0441013597"],
+			"series": ["Dune Chronicles (1)"]}`)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	ol := NewOpenLibrary()
+	ol.BaseURL = server.URL
+	ol.CoversURL = server.URL
+	ol.Client = server.Client()
+	return ol
+}
+
+func TestEnrichBook(t *testing.T) {
+	tests := []struct {
+		name  string
+		title string
+		setup string
+		want  []FieldChange
+	}{
+		{"full match", "Dune", "", []FieldChange{
+			{Field: FieldDescription, New: "A desert planet.", Accept: true},
+			{Field: FieldSubjects, New: "Science fiction, Deserts", Accept: true},
+			{Field: FieldPublishYear, New: "1965", Accept: true},
+			{Field: FieldPageCount, New: "412", Accept: true},
+			{Field: FieldISBNs, New: "9780441013593, 0441013597", Accept: true},
+			{Field: FieldSeries, New: "Dune Chronicles #1", Accept: true},
+		}},
+		{"known fields are kept", "Dune", "UPDATE books SET description = 'Mine', page_count = 500, genres = 'SF'", []FieldChange{
+			{Field: FieldPublishYear, New: "1965", Accept: true},
+			{Field: FieldISBNs, New: "9780441013593, 0441013597", Accept: true},
+			{Field: FieldSeries, New: "Dune Chronicles #1", Accept: true},
+		}},
+		{"missing work and edition", "Lonely", "", []FieldChange{
+			{Field: FieldPublishYear, New: "2001", Accept: true},
+		}},
+		{"empty search", "Nobody Wrote This", "", nil},
+		{"isbn not found", "Dune", "UPDATE books SET isbns = '0000000000'", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newTestHandler(t)
+			providers, err := NewProviders([]ProviderSpec{{Name: ProviderOpenLibrary}}, newOpenLibraryStub(t))
+			if err != nil {
+				t.Fatal(err)
+			}
+			h.Providers = providers
+			src := writeTestEPUB(t, t.TempDir(), "book.epub", tt.title, "Frank Herbert", "text")
+			if _, err := h.ImportFiles([]string{src}, DuplicateSkip); err != nil {
+				t.Fatal(err)
+			}
+			if tt.setup != "" {
+				if _, err := h.DB.Exec(tt.setup); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			e, err := h.EnrichBook(context.Background(), "book.epub")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(e.Changes, tt.want) {
+				t.Errorf("changes = %+v\nwant %+v", e.Changes, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyEnrichmentSkipsRejectedFields(t *testing.T) {
+	h := newTestHandler(t)
+	providers, err := NewProviders([]ProviderSpec{{Name: ProviderOpenLibrary}}, newOpenLibraryStub(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Providers = providers
+	src := writeTestEPUB(t, t.TempDir(), "book.epub", "Dune", "Frank Herbert", "text")
+	if _, err := h.ImportFiles([]string{src}, DuplicateSkip); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	e, err := h.EnrichBook(ctx, "book.epub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range e.Changes {
+		e.Changes[i].Accept = e.Changes[i].Field == FieldPageCount || e.Changes[i].Field == FieldSeries
+	}
+	if err := h.ApplyEnrichment(e); err != nil {
+		t.Fatal(err)
+	}
+	row, err := h.Queries.SelectBookByFileName(ctx, "book.epub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if row.PageCount != 412 || row.Series.String != "Dune Chronicles" || row.SeriesIndex != 1 {
+		t.Errorf("accepted fields = pages %d series %q #%v, want 412 Dune Chronicles #1", row.PageCount, row.Series.String, row.SeriesIndex)
+	}
+	if row.Description != "" || row.PublishYear != 0 || row.Isbns != "" || row.OlWork != "/works/OL1W" || row.EnrichedAt == "" {
+		t.Errorf("rejected fields were stored: %+v", row)
+	}
+}
@@ -0,0 +1,334 @@
+package metadata
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	dcNamespace  = "http://purl.org/dc/elements/1.1/"
+	opfNamespace = "http://www.idpf.org/2007/opf"
+)
+
+var (
+	dcPrefixRe  = regexp.MustCompile(`xmlns:([A-Za-z_][\w.-]*)\s*=\s*["']` + regexp.QuoteMeta(dcNamespace) + `["']`)
+	opfPrefixRe = regexp.MustCompile(`xmlns:([A-Za-z_][\w.-]*)\s*=\s*["']` + regexp.QuoteMeta(opfNamespace) + `["']`)
+)
+
+// writeEPUBMetadata writes a copy of an .epub with the edited fields in its
+// OPF next to the original and returns its path. The caller renames it over
+// the original once the edit is saved, so a failure never leaves a
+// half-written book behind.
+func writeEPUBMetadata(epubPath string, edit MetadataEdit, authors []Credit) (tmpPath string, err error) {
+	r, err := zip.OpenReader(epubPath)
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	var opf *zip.File
+	for _, f := range r.File {
+		if strings.HasSuffix(f.Name, ".opf") {
+			opf = f
+			break
+		}
+	}
+	if opf == nil {
+		return "", errors.New("no .opf file in " + filepath.Base(epubPath))
+	}
+	rc, err := opf.Open()
+	if err != nil {
+		return "", err
+	}
+	data, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		return "", err
+	}
+	data, err = editOPF(data, edit, authors)
+	if err != nil {
+		return "", err
+	}
+
+	info, err := os.Stat(epubPath)
+	if err != nil {
+		return "", err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(epubPath), ".kindria-*.epub")
+	if err != nil {
+		return "", err
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	zw := zip.NewWriter(tmp)
+	for _, f := range r.File {
+		if f != opf {
+			if err := zw.Copy(f); err != nil {
+				return "", err
+			}
+			continue
+		}
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: f.Name, Method: f.Method, Modified: time.Now()})
+		if err != nil {
+			return "", err
+		}
+		if _, err := w.Write(data); err != nil {
+			return "", err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return "", err
+	}
+	if err := tmp.Chmod(info.Mode().Perm()); err != nil {
+		return "", err
+	}
+	if err := tmp.Sync(); err != nil {
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	return tmp.Name(), nil
+}
+
+type opfSpan struct {
+	start, end int64
+}
+
+// editOPF replaces the edited elements of the <metadata> block and leaves
+// every other byte of the document untouched. Elements are located with the
+// decoder offsets instead of re-encoding the XML, which would rewrite the
+// namespace prefixes.
+func editOPF(data []byte, edit MetadataEdit, authors []Credit) ([]byte, error) {
+	var pkg Package
+	if err := xml.Unmarshal(data, &pkg); err != nil {
+		return nil, err
+	}
+	refined := pkg.Metadata.refinements()
+
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	var (
+		drop      []opfSpan
+		refines   = make(map[string][]opfSpan)
+		dropped   = make(map[string]bool)
+		version   string
+		indent    = "    "
+		firstKid  = true
+		inside    bool
+		closeAt   int64 = -1
+		dropElems       = map[string]bool{"title": true, "subject": true, "description": true}
+	)
+	if edit.Language != "" {
+		dropElems["language"] = true
+	}
+	for closeAt < 0 {
+		start := dec.InputOffset()
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if !inside {
+				switch t.Name.Local {
+				case "package":
+					version = xmlAttr(t, "version")
+				case "metadata":
+					inside = true
+				}
+				continue
+			}
+			if err := dec.Skip(); err != nil {
+				return nil, err
+			}
+			span := opfSpan{start, dec.InputOffset()}
+			if firstKid {
+				firstKid = false
+				if ws := lineIndent(data, start); ws != "" {
+					indent = ws
+				}
+			}
+			id := xmlAttr(t, "id")
+			remove := false
+			switch {
+			case t.Name.Space == dcNamespace && dropElems[t.Name.Local]:
+				remove = true
+			case t.Name.Space == dcNamespace && t.Name.Local == "creator":
+				role := xmlAttr(t, "role")
+				if role == "" {
+					role = refined[id]["role"]
+				}
+				role = strings.ToLower(strings.TrimSpace(role))
+				remove = role == "" || role == RoleAuthor
+			case t.Name.Local == "meta":
+				name, property, target := xmlAttr(t, "name"), xmlAttr(t, "property"), xmlAttr(t, "refines")
+				switch {
+				case name == "calibre:series" || name == "calibre:series_index":
+					remove = true
+				case property == "belongs-to-collection" && target == "":
+					kind := refined[id]["collection-type"]
+					remove = kind == "" || kind == "series"
+				case target != "":
+					target = strings.TrimPrefix(target, "#")
+					refines[target] = append(refines[target], span)
+				}
+			}
+			if remove {
+				drop = append(drop, span)
+				if id != "" {
+					dropped[id] = true
+				}
+			}
+		case xml.EndElement:
+			if inside && t.Name.Local == "metadata" {
+				closeAt = start
+			}
+		}
+	}
+	if closeAt < 0 {
+		return nil, errors.New("opf has no <metadata> element")
+	}
+	for id, spans := range refines {
+		if dropped[id] {
+			drop = append(drop, spans...)
+		}
+	}
+	sort.Slice(drop, func(i, j int) bool { return drop[i].start < drop[j].start })
+
+	var out bytes.Buffer
+	pos := int64(0)
+	for _, s := range drop {
+		start := trimIndentBefore(data, s.start)
+		if start < pos {
+			start = pos
+		}
+		out.Write(data[pos:start])
+		pos = s.end
+	}
+	insertAt := trimIndentBefore(data, closeAt)
+	if insertAt < pos {
+		insertAt = pos
+	}
+	out.Write(data[pos:insertAt])
+	out.WriteString(opfElements(data, edit, authors, strings.HasPrefix(version, "3"), indent))
+	out.Write(data[insertAt:])
+	return out.Bytes(), nil
+}
+
+func opfElements(data []byte, edit MetadataEdit, authors []Credit, epub3 bool, indent string) string {
+	dc, dcDecl := "dc", ` xmlns:dc="`+dcNamespace+`"`
+	if m := dcPrefixRe.FindSubmatch(data); m != nil {
+		dc, dcDecl = string(m[1]), ""
+	}
+	opf, opfDecl := "opf", ` xmlns:opf="`+opfNamespace+`"`
+	if m := opfPrefixRe.FindSubmatch(data); m != nil {
+		opf, opfDecl = string(m[1]), ""
+	}
+
+	var b strings.Builder
+	element := func(name, attrs, text string) {
+		b.WriteString("\n" + indent + "<" + name + attrs + ">" + xmlEscape(text) + "</" + name + ">")
+	}
+	element(dc+":title", dcDecl, edit.Title)
+	for i, a := range authors {
+		if epub3 {
+			id := "kindria-creator-" + strconv.Itoa(i+1)
+			element(dc+":creator", dcDecl+` id="`+id+`"`, a.Name)
+			element("meta", ` refines="#`+id+`" property="role" scheme="marc:relators"`, RoleAuthor)
+			if a.FileAs != "" {
+				element("meta", ` refines="#`+id+`" property="file-as"`, a.FileAs)
+			}
+			continue
+		}
+		attrs := dcDecl + opfDecl + ` ` + opf + `:role="` + RoleAuthor + `"`
+		if a.FileAs != "" {
+			attrs += ` ` + opf + `:file-as="` + xmlEscape(a.FileAs) + `"`
+		}
+		element(dc+":creator", attrs, a.Name)
+	}
+	if edit.Language != "" {
+		element(dc+":language", dcDecl, edit.Language)
+	}
+	if edit.Description != "" {
+		element(dc+":description", dcDecl, edit.Description)
+	}
+	for _, g := range edit.Genres {
+		element(dc+":subject", dcDecl, g)
+	}
+	if edit.Series != "" {
+		index := FormatSeriesIndex(edit.SeriesIndex)
+		if epub3 {
+			element("meta", ` property="belongs-to-collection" id="kindria-series"`, edit.Series)
+			element("meta", ` refines="#kindria-series" property="collection-type"`, "series")
+			if edit.SeriesIndex > 0 {
+				element("meta", ` refines="#kindria-series" property="group-position"`, index)
+			}
+		}
+		b.WriteString("\n" + indent + `<meta name="calibre:series" content="` + xmlEscape(edit.Series) + `"/>`)
+		b.WriteString("\n" + indent + `<meta name="calibre:series_index" content="` + index + `"/>`)
+	}
+	return b.String()
+}
+
+func xmlAttr(e xml.StartElement, local string) string {
+	for _, a := range e.Attr {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func xmlEscape(s string) string {
+	var b bytes.Buffer
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// lineIndent returns the whitespace between the start of the line and offset,
+// or "" when something else precedes offset on that line.
+func lineIndent(data []byte, offset int64) string {
+	i := offset
+	for i > 0 && (data[i-1] == ' ' || data[i-1] == '\t') {
+		i--
+	}
+	if i > 0 && data[i-1] != '\n' {
+		return ""
+	}
+	return string(data[i:offset])
+}
+
+// trimIndentBefore moves offset back over the indentation and line break in
+// front of it, so removing an element does not leave an empty line.
+func trimIndentBefore(data []byte, offset int64) int64 {
+	i := offset
+	for i > 0 && (data[i-1] == ' ' || data[i-1] == '\t') {
+		i--
+	}
+	if i == 0 || data[i-1] != '\n' {
+		return offset
+	}
+	i--
+	if i > 0 && data[i-1] == '\r' {
+		i--
+	}
+	return i
+}
@@ -0,0 +1,373 @@
+package metadata
+
+import (
+	"Kindria/internal/core/db"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Lookup identifies a book for the providers. ISBNs are bare digits, best
+// first.
+type Lookup struct {
+	Title  string
+	Author string
+	ISBNs  []string
+}
+
+// ProviderMetadata is what a provider knows about a book. Zero values mean
+// unknown. Work is the provider's own ID for the book, if it has one.
+type ProviderMetadata struct {
+	Work        string
+	Description string
+	Subjects    []string
+	PublishYear int
+	PageCount   int
+	ISBNs       []string
+	Series      string
+	SeriesIndex float64
+}
+
+// MetadataProvider looks up book details. It returns ErrNotFound when it has
+// no match.
+type MetadataProvider interface {
+	Name() string
+	Metadata(ctx context.Context, l Lookup) (ProviderMetadata, error)
+}
+
+// CoverProvider finds a cover image. It returns ErrNotFound when it has none.
+// Name is stored as the cover's source.
+type CoverProvider interface {
+	Name() string
+	Cover(ctx context.Context, l Lookup) (io.ReadCloser, error)
+}
+
+// CoverOption is an image a cover provider offers for the cover picker. Ref
+// identifies it to the provider's OpenCover.
+type CoverOption struct {
+	Source string
+	Ref    string
+	Label  string
+}
+
+// CoverLister is implemented by cover providers that can offer more than one
+// image for a book.
+type CoverLister interface {
+	CoverOptions(ctx context.Context, l Lookup) ([]CoverOption, error)
+	OpenCover(ctx context.Context, ref string) (io.ReadCloser, error)
+}
+
+const (
+	ProviderOpenLibrary = CoverSourceOpenLibrary
+	ProviderLocal       = CoverSourceLocal
+)
+
+// ProviderSpec configures one provider. Providers are asked in the order
+// given; RateLimit is the minimum time between two lookups.
+type ProviderSpec struct {
+	Name      string
+	Dir       string
+	RateLimit time.Duration
+}
+
+// DefaultProviders looks for local covers in <libraryDir>/covers before asking
+// Open Library.
+func DefaultProviders(libraryDir string) []ProviderSpec {
+	return []ProviderSpec{
+		{Name: ProviderLocal, Dir: filepath.Join(libraryDir, "covers")},
+		{Name: ProviderOpenLibrary, RateLimit: 3 * time.Second},
+	}
+}
+
+type provider struct {
+	name  string
+	limit *rateLimit
+	meta  MetadataProvider
+	cover CoverProvider
+}
+
+// Providers asks metadata and cover providers in priority order. It is safe
+// for concurrent use.
+type Providers struct {
+	list []provider
+}
+
+// NewProviders builds the providers in specs. ol is used for Open Library, so
+// its client and URLs can be replaced.
+func NewProviders(specs []ProviderSpec, ol *OpenLibrary) (*Providers, error) {
+	p := &Providers{}
+	for _, s := range specs {
+		entry := provider{name: s.Name, limit: &rateLimit{gap: s.RateLimit}}
+		switch s.Name {
+		case ProviderOpenLibrary:
+			entry.meta, entry.cover = ol, ol
+		case ProviderLocal:
+			if s.Dir == "" {
+				return nil, errors.New("local provider needs a dir")
+			}
+			entry.cover = LocalCovers{Dir: s.Dir}
+		default:
+			return nil, fmt.Errorf("unknown metadata provider %q", s.Name)
+		}
+		p.list = append(p.list, entry)
+	}
+	return p, nil
+}
+
+var defaultProviders, _ = NewProviders([]ProviderSpec{{Name: ProviderOpenLibrary, RateLimit: 3 * time.Second}}, NewOpenLibrary())
+
+func (h *Handler) providers() *Providers {
+	if h.Providers != nil {
+		return h.Providers
+	}
+	return defaultProviders
+}
+
+// Metadata merges what every metadata provider knows; earlier providers win
+// field by field. A provider error is only returned when nobody had a match.
+func (p *Providers) Metadata(ctx context.Context, l Lookup) (ProviderMetadata, error) {
+	var out ProviderMetadata
+	found := false
+	var firstErr error
+	for _, entry := range p.list {
+		if entry.meta == nil {
+			continue
+		}
+		if err := entry.limit.wait(ctx); err != nil {
+			return out, err
+		}
+		md, err := entry.meta.Metadata(ctx, l)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			log.Printf("Err looking up %q on %s: %v", l.Title, entry.name, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		found = true
+		out.merge(md)
+	}
+	if !found {
+		if firstErr != nil {
+			return out, firstErr
+		}
+		return out, ErrNotFound
+	}
+	return out, nil
+}
+
+func (md *ProviderMetadata) merge(o ProviderMetadata) {
+	if md.Work == "" {
+		md.Work = o.Work
+	}
+	if md.Description == "" {
+		md.Description = o.Description
+	}
+	if len(md.Subjects) == 0 {
+		md.Subjects = o.Subjects
+	}
+	if md.PublishYear == 0 {
+		md.PublishYear = o.PublishYear
+	}
+	if md.PageCount == 0 {
+		md.PageCount = o.PageCount
+	}
+	if len(md.ISBNs) == 0 {
+		md.ISBNs = o.ISBNs
+	}
+	if md.Series == "" {
+		md.Series, md.SeriesIndex = o.Series, o.SeriesIndex
+	}
+}
+
+// Cover returns the first cover found and the name of the provider that had
+// it. When no provider has one, a provider error is preferred over
+// ErrNotFound so the lookup is retried.
+func (p *Providers) Cover(ctx context.Context, l Lookup) (io.ReadCloser, string, error) {
+	var firstErr error
+	for _, entry := range p.list {
+		if entry.cover == nil {
+			continue
+		}
+		if err := entry.limit.wait(ctx); err != nil {
+			return nil, "", err
+		}
+		rc, err := entry.cover.Cover(ctx, l)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s: %w", entry.name, err)
+			}
+			continue
+		}
+		return rc, entry.cover.Name(), nil
+	}
+	if firstErr != nil {
+		return nil, "", firstErr
+	}
+	return nil, "", ErrNotFound
+}
+
+// CoverOptions collects what every cover provider offers, in priority order.
+// A provider error is returned with the options the others had.
+func (p *Providers) CoverOptions(ctx context.Context, l Lookup) ([]CoverOption, error) {
+	var out []CoverOption
+	var firstErr error
+	for _, entry := range p.list {
+		lister, ok := entry.cover.(CoverLister)
+		if !ok {
+			continue
+		}
+		if err := entry.limit.wait(ctx); err != nil {
+			return out, err
+		}
+		options, err := lister.CoverOptions(ctx, l)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s: %w", entry.name, err)
+			}
+			continue
+		}
+		for _, o := range options {
+			o.Source = entry.cover.Name()
+			out = append(out, o)
+		}
+	}
+	return out, firstErr
+}
+
+// OpenCover opens an option returned by CoverOptions.
+func (p *Providers) OpenCover(ctx context.Context, source, ref string) (io.ReadCloser, error) {
+	for _, entry := range p.list {
+		lister, ok := entry.cover.(CoverLister)
+		if !ok || entry.cover.Name() != source {
+			continue
+		}
+		if err := entry.limit.wait(ctx); err != nil {
+			return nil, err
+		}
+		return lister.OpenCover(ctx, ref)
+	}
+	return nil, fmt.Errorf("no cover provider %q", source)
+}
+
+// rateLimit spaces calls at least gap apart.
+type rateLimit struct {
+	mu   sync.Mutex
+	gap  time.Duration
+	next time.Time
+}
+
+func (r *rateLimit) wait(ctx context.Context) error {
+	if r.gap <= 0 {
+		return nil
+	}
+	r.mu.Lock()
+	at := r.next
+	if now := time.Now(); at.Before(now) {
+		at = now
+	}
+	r.next = at.Add(r.gap)
+	r.mu.Unlock()
+	d := time.Until(at)
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// LocalCovers serves covers from a folder of images named after an ISBN,
+// e.g. covers/9780316129077.jpg.
+type LocalCovers struct {
+	Dir string
+}
+
+var localCoverExts = []string{".jpg", ".jpeg", ".png", ".gif", ".webp"}
+
+func (LocalCovers) Name() string { return CoverSourceLocal }
+
+func (c LocalCovers) Cover(ctx context.Context, l Lookup) (io.ReadCloser, error) {
+	for _, isbn := range l.ISBNs {
+		for _, ext := range localCoverExts {
+			f, err := os.Open(filepath.Join(c.Dir, isbn+ext))
+			if err == nil {
+				return f, nil
+			}
+			if !errors.Is(err, os.ErrNotExist) {
+				return nil, err
+			}
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// CoverOptions offers every image named after one of the book's ISBNs.
+func (c LocalCovers) CoverOptions(ctx context.Context, l Lookup) ([]CoverOption, error) {
+	var out []CoverOption
+	for _, isbn := range l.ISBNs {
+		for _, ext := range localCoverExts {
+			name := isbn + ext
+			if _, err := os.Stat(filepath.Join(c.Dir, name)); err == nil {
+				out = append(out, CoverOption{Ref: name, Label: name})
+			}
+		}
+	}
+	if len(out) == 0 {
+		return nil, ErrNotFound
+	}
+	return out, nil
+}
+
+func (c LocalCovers) OpenCover(ctx context.Context, ref string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(c.Dir, filepath.Base(ref)))
+}
+
+// bookLookup builds the provider lookup for a book: its stored ISBNs, then
+// the ones in the EPUB.
+func (h *Handler) bookLookup(row db.Book) Lookup {
+	author := storedPrimaryAuthor(context.Background(), h.Queries, row.ID, row.Author)
+	l := Lookup{Title: row.Title, Author: author}
+	seen := make(map[string]bool)
+	add := func(isbn string) {
+		isbn = strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(isbn))
+		if isbn != "" && !seen[isbn] {
+			seen[isbn] = true
+			l.ISBNs = append(l.ISBNs, isbn)
+		}
+	}
+	if row.Isbns != "" {
+		for _, isbn := range strings.Split(row.Isbns, ",") {
+			add(isbn)
+		}
+	}
+	if bookData, err := extractMetadata(h.LibraryDir, row.FileName); err == nil {
+		for _, id := range bookData.Metadata.Identifiers {
+			if id.Scheme == "ISBN" && looksLikeISBN(id.Value) {
+				add(id.Value)
+			}
+		}
+	}
+	return l
+}
@@ -0,0 +1,320 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// A shelf query is a list of terms that must all match, such as
+//
+//	status:unread rating>=4 genre:fantasy lang:es added<30d
+//
+// Each term is a field, an operator (: = != < <= > >=) and a value. Values
+// with spaces go in double quotes, commas list alternatives (lang:es,en) and
+// a leading "-" negates the term. A word without a field matches the title or
+// the author.
+type Query struct {
+	Terms []Term
+}
+
+type Term struct {
+	Field  string
+	Op     string
+	Values []string
+	Negate bool
+}
+
+type fieldKind int
+
+const (
+	textField fieldKind = iota
+	statusField
+	languageField
+	numberField
+	dateField
+)
+
+type queryField struct {
+	kind   fieldKind
+	column string
+}
+
+var queryFields = map[string]queryField{
+	"title":    {textField, "title"},
+	"author":   {textField, "author"},
+	"genre":    {textField, "genres"},
+	"genres":   {textField, "genres"},
+	"series":   {textField, "COALESCE(series, '')"},
+	"status":   {statusField, "status"},
+	"lang":     {languageField, "language"},
+	"language": {languageField, "language"},
+	"rating":   {numberField, "COALESCE(rating, 0)"},
+	"pages":    {numberField, "page_count"},
+	"added":    {dateField, "substr(added_at, 1, 10)"},
+	"read":     {dateField, "reading_date"},
+}
+
+// queryStatuses maps the spellings accepted after status: to the statuses
+// they stand for; "unread" also takes books whose status was never set.
+var queryStatuses = map[string][]string{
+	"unread":           {"Unread", "Not defined yet"},
+	"new":              {"Not defined yet"},
+	"notdefinedyet":    {"Not defined yet"},
+	"read":             {"Read"},
+	"tbr":              {"To Be Read"},
+	"toberead":         {"To Be Read"},
+	"reading":          {"Currently Reading"},
+	"currentlyreading": {"Currently Reading"},
+	"archived":         {"Archived"},
+}
+
+var queryOperators = []string{">=", "<=", "!=", ":", "=", ">", "<"}
+
+// ParseQuery reads a shelf query and checks every value against its field.
+func ParseQuery(s string) (Query, error) {
+	words, err := splitQuery(s)
+	if err != nil {
+		return Query{}, err
+	}
+	if len(words) == 0 {
+		return Query{}, errors.New("empty query")
+	}
+	var q Query
+	for _, w := range words {
+		t, err := parseTerm(w)
+		if err != nil {
+			return Query{}, err
+		}
+		q.Terms = append(q.Terms, t)
+	}
+	return q, nil
+}
+
+// splitQuery breaks a query on spaces outside double quotes and drops the
+// quotes.
+func splitQuery(s string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	quoted, inWord := false, false
+	for _, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+			inWord = true
+		case unicode.IsSpace(r) && !quoted:
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quoted {
+		return nil, errors.New("unclosed quote in query")
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+func parseTerm(w string) (Term, error) {
+	t := Term{}
+	if strings.HasPrefix(w, "-") && len(w) > 1 {
+		t.Negate = true
+		w = w[1:]
+	}
+	end := strings.IndexFunc(w, func(r rune) bool { return !unicode.IsLetter(r) })
+	if end <= 0 {
+		return Term{Op: ":", Values: []string{w}, Negate: t.Negate}, nil
+	}
+	for _, op := range queryOperators {
+		if strings.HasPrefix(w[end:], op) {
+			t.Field, t.Op = strings.ToLower(w[:end]), op
+			break
+		}
+	}
+	if t.Op == "" {
+		return Term{Op: ":", Values: []string{w}, Negate: t.Negate}, nil
+	}
+	field, ok := queryFields[t.Field]
+	if !ok {
+		return Term{}, fmt.Errorf("unknown field %q in %q", t.Field, w)
+	}
+	value := w[end+len(t.Op):]
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			t.Values = append(t.Values, v)
+		}
+	}
+	if len(t.Values) == 0 {
+		return Term{}, fmt.Errorf("missing value in %q", w)
+	}
+	if field.kind != numberField && field.kind != dateField && t.Op != ":" && t.Op != "=" && t.Op != "!=" {
+		return Term{}, fmt.Errorf("%s only takes : = or != in %q", t.Field, w)
+	}
+	if t.Op == "!=" {
+		t.Op, t.Negate = "=", !t.Negate
+	}
+	for _, v := range t.Values {
+		switch field.kind {
+		case statusField:
+			if _, ok := queryStatuses[foldStatus(v)]; !ok {
+				return Term{}, fmt.Errorf("unknown status %q: expected unread, read, tbr, reading or archived", v)
+			}
+		case numberField:
+			if _, err := strconv.ParseFloat(v, 64); err != nil {
+				return Term{}, fmt.Errorf("%s needs a number in %q", t.Field, w)
+			}
+		case dateField:
+			if _, _, ok := parseQueryDate(v); !ok {
+				return Term{}, fmt.Errorf("%s needs a date (2024, 2024-05, 2024-05-01) or an age (30d, 2w, 6m, 1y) in %q", t.Field, w)
+			}
+		}
+	}
+	return t, nil
+}
+
+func foldStatus(s string) string {
+	return strings.NewReplacer(" ", "", "-", "", "_", "").Replace(strings.ToLower(s))
+}
+
+// parseQueryDate reads an age such as 30d (days, weeks, months or years ago),
+// or a year, month or day written as a prefix of YYYY-MM-DD.
+func parseQueryDate(v string) (age [3]int, prefix string, ok bool) {
+	if len(v) == 0 {
+		return age, "", false
+	}
+	if n, err := strconv.Atoi(v[:len(v)-1]); err == nil && n >= 0 {
+		switch v[len(v)-1] {
+		case 'd':
+			return [3]int{0, 0, n}, "", true
+		case 'w':
+			return [3]int{0, 0, 7 * n}, "", true
+		case 'm':
+			return [3]int{0, n, 0}, "", true
+		case 'y':
+			return [3]int{n, 0, 0}, "", true
+		}
+	}
+	for _, layout := range []string{"2006", "2006-01", "2006-01-02"} {
+		if _, err := time.Parse(layout, v); err == nil {
+			return age, v, true
+		}
+	}
+	return age, "", false
+}
+
+// SQL compiles the query into a WHERE clause on books, with relative dates
+// counted back from now. Archived books only match when the query asks for
+// them with status:archived.
+func (q Query) SQL(now time.Time) (string, []any) {
+	var clauses []string
+	var args []any
+	archived := false
+	for _, t := range q.Terms {
+		field := queryFields[t.Field]
+		for _, v := range t.Values {
+			archived = archived || field.kind == statusField && !t.Negate && foldStatus(v) == "archived"
+		}
+		alternatives := make([]string, 0, len(t.Values))
+		for _, v := range t.Values {
+			clause, values := termSQL(t, field, v, now)
+			alternatives = append(alternatives, clause)
+			args = append(args, values...)
+		}
+		clause := strings.Join(alternatives, " OR ")
+		if len(alternatives) > 1 {
+			clause = "(" + clause + ")"
+		}
+		if t.Negate {
+			clause = "NOT " + clause
+		}
+		clauses = append(clauses, clause)
+	}
+	if !archived {
+		clauses = append(clauses, "status <> 'Archived'")
+	}
+	return strings.Join(clauses, " AND "), args
+}
+
+func termSQL(t Term, field queryField, v string, now time.Time) (string, []any) {
+	if t.Field == "" {
+		pattern := "%" + escapeLike(v) + "%"
+		return `(title LIKE ? ESCAPE '\' OR author LIKE ? ESCAPE '\')`, []any{pattern, pattern}
+	}
+	switch field.kind {
+	case statusField:
+		statuses := queryStatuses[foldStatus(v)]
+		args := make([]any, 0, len(statuses))
+		for _, s := range statuses {
+			args = append(args, s)
+		}
+		return "status IN (?" + strings.Repeat(", ?", len(statuses)-1) + ")", args
+	case languageField:
+		v = strings.ToLower(v)
+		return "(lower(language) = ? OR lower(language) LIKE ? ESCAPE '\\')", []any{v, escapeLike(v) + "-%"}
+	case numberField:
+		n, _ := strconv.ParseFloat(v, 64)
+		op := t.Op
+		if op == ":" {
+			op = "="
+		}
+		return "(" + field.column + " " + op + " ?)", []any{n}
+	case dateField:
+		age, prefix, _ := parseQueryDate(v)
+		column := field.column
+		if prefix != "" {
+			switch t.Op {
+			case ":", "=":
+				return "(" + column + " LIKE ?)", []any{prefix + "%"}
+			case ">", "<=":
+				// "~" sorts after every digit, so the whole year or month
+				// is on the lower side.
+				prefix += "~"
+			}
+			return "(" + column + " <> '' AND " + column + " " + t.Op + " ?)", []any{prefix}
+		}
+		// An age turns the comparison around: added<30d is a date after
+		// the one 30 days ago.
+		cutoff := now.AddDate(-age[0], -age[1], -age[2]).Format("2006-01-02")
+		op := map[string]string{":": ">=", "=": "=", "<": ">", "<=": ">=", ">": "<", ">=": "<="}[t.Op]
+		return "(" + column + " <> '' AND " + column + " " + op + " ?)", []any{cutoff}
+	}
+	return "(" + field.column + " LIKE ? ESCAPE '\\')", []any{"%" + escapeLike(v) + "%"}
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// QueryBooks returns the books matching a shelf query, by title.
+func (h *Handler) QueryBooks(query string) ([]ShelfBook, error) {
+	q, err := ParseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	where, args := q.SQL(time.Now())
+	rows, err := h.DB.QueryContext(context.Background(), "SELECT file_name, title FROM books WHERE "+where+" ORDER BY title", args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var books []ShelfBook
+	for rows.Next() {
+		var b ShelfBook
+		if err := rows.Scan(&b.FileName, &b.Title); err != nil {
+			return nil, err
+		}
+		books = append(books, b)
+	}
+	return books, rows.Err()
+}
@@ -0,0 +1,183 @@
+package metadata
+
+import (
+	"Kindria/internal/core/db"
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []Term
+	}{
+		{"status:unread", []Term{{Field: "status", Op: ":", Values: []string{"unread"}}}},
+		{"rating>=4 pages<300", []Term{
+			{Field: "rating", Op: ">=", Values: []string{"4"}},
+			{Field: "pages", Op: "<", Values: []string{"300"}},
+		}},
+		{`title:"the name of the wind"`, []Term{{Field: "title", Op: ":", Values: []string{"the name of the wind"}}}},
+		{`"blood rose"`, []Term{{Op: ":", Values: []string{"blood rose"}}}},
+		{"lang:es,en", []Term{{Field: "lang", Op: ":", Values: []string{"es", "en"}}}},
+		{"-genre:horror", []Term{{Field: "genre", Op: ":", Values: []string{"horror"}, Negate: true}}},
+		{"status!=read", []Term{{Field: "status", Op: "=", Values: []string{"read"}, Negate: true}}},
+		{"-status!=read", []Term{{Field: "status", Op: "=", Values: []string{"read"}}}},
+		{"Rating>4", []Term{{Field: "rating", Op: ">", Values: []string{"4"}}}},
+		{"added<30d read:2024-05", []Term{
+			{Field: "added", Op: "<", Values: []string{"30d"}},
+			{Field: "read", Op: ":", Values: []string{"2024-05"}},
+		}},
+		{"dune", []Term{{Op: ":", Values: []string{"dune"}}}},
+		{"-", []Term{{Op: ":", Values: []string{"-"}}}},
+	}
+	for _, tt := range tests {
+		q, err := ParseQuery(tt.query)
+		if err != nil {
+			t.Errorf("ParseQuery(%q): %v", tt.query, err)
+			continue
+		}
+		if !reflect.DeepEqual(q.Terms, tt.want) {
+			t.Errorf("ParseQuery(%q) = %+v, want %+v", tt.query, q.Terms, tt.want)
+		}
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"", "empty query"},
+		{"   ", "empty query"},
+		{`title:"dune`, "unclosed quote"},
+		{"colour:red", `unknown field "colour"`},
+		{"status:finished", `unknown status "finished"`},
+		{"rating>=high", "rating needs a number"},
+		{"pages:", "missing value"},
+		{"lang:,", "missing value"},
+		{"title>dune", "title only takes : = or !="},
+		{"status<=read", "status only takes : = or !="},
+		{"added>yesterday", "added needs a date"},
+		{"read:2024-13", "read needs a date"},
+	}
+	for _, tt := range tests {
+		_, err := ParseQuery(tt.query)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParseQuery(%q) error = %v, want one containing %q", tt.query, err, tt.want)
+		}
+	}
+}
+
+func TestQuerySQL(t *testing.T) {
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		query     string
+		wantWhere string
+		wantArgs  []any
+	}{
+		{"status:archived", "status IN (?)", []any{"Archived"}},
+		{"status:read,archived", "(status IN (?) OR status IN (?))", []any{"Read", "Archived"}},
+		{"-status:archived", "NOT status IN (?) AND status <> 'Archived'", []any{"Archived"}},
+		{"status:unread", "status IN (?, ?) AND status <> 'Archived'", []any{"Unread", "Not defined yet"}},
+		{"rating>=4", "(COALESCE(rating, 0) >= ?) AND status <> 'Archived'", []any{4.0}},
+		{"pages:300", "(page_count = ?) AND status <> 'Archived'", []any{300.0}},
+		{"genre:50%", `(genres LIKE ? ESCAPE '\') AND status <> 'Archived'`, []any{`%50\%%`}},
+		{"lang:ES", `(lower(language) = ? OR lower(language) LIKE ? ESCAPE '\') AND status <> 'Archived'`, []any{"es", "es-%"}},
+		{"read:2024", "(reading_date LIKE ?) AND status <> 'Archived'", []any{"2024%"}},
+		{"read>2024", "(reading_date <> '' AND reading_date > ?) AND status <> 'Archived'", []any{"2024~"}},
+		{"read>=2024-05", "(reading_date <> '' AND reading_date >= ?) AND status <> 'Archived'", []any{"2024-05"}},
+		{"added<30d", "(substr(added_at, 1, 10) <> '' AND substr(added_at, 1, 10) > ?) AND status <> 'Archived'", []any{"2026-03-01"}},
+		{"added>1y", "(substr(added_at, 1, 10) <> '' AND substr(added_at, 1, 10) < ?) AND status <> 'Archived'", []any{"2025-03-31"}},
+		{"dune", `(title LIKE ? ESCAPE '\' OR author LIKE ? ESCAPE '\') AND status <> 'Archived'`, []any{"%dune%", "%dune%"}},
+	}
+	for _, tt := range tests {
+		q, err := ParseQuery(tt.query)
+		if err != nil {
+			t.Fatalf("ParseQuery(%q): %v", tt.query, err)
+		}
+		where, args := q.SQL(now)
+		if where != tt.wantWhere {
+			t.Errorf("%q: where = %s, want %s", tt.query, where, tt.wantWhere)
+		}
+		if !reflect.DeepEqual(args, tt.wantArgs) {
+			t.Errorf("%q: args = %v, want %v", tt.query, args, tt.wantArgs)
+		}
+	}
+}
+
+func TestQuerySQLEmptyDate(t *testing.T) {
+	// Queries built without ParseQuery may carry values it would reject.
+	now := time.Date(2026, 3, 31, 12, 0, 0, 0, time.UTC)
+	q := Query{Terms: []Term{{Field: "added", Op: ":", Values: []string{""}}}}
+	where, args := q.SQL(now)
+	if want := "(substr(added_at, 1, 10) <> '' AND substr(added_at, 1, 10) >= ?) AND status <> 'Archived'"; where != want {
+		t.Errorf("where = %s, want %s", where, want)
+	}
+	if !reflect.DeepEqual(args, []any{"2026-03-31"}) {
+		t.Errorf("args = %v, want [2026-03-31]", args)
+	}
+}
+
+func TestQueryBooks(t *testing.T) {
+	h := newTestHandler(t)
+	ctx := context.Background()
+	books := []struct {
+		title, author, status, language string
+		rating                          float64
+	}{
+		{"Dune", "Frank Herbert", "Unread", "en", 4.5},
+		{"El nombre del viento", "Patrick Rothfuss", "Read", "es-ES", 5},
+		{"Old News", "Frank Herbert", "Archived", "en", 2},
+		{"Fresh", "Somebody", "Not defined yet", "en", 0},
+	}
+	for i, b := range books {
+		_, err := h.Queries.InsertBooks(ctx, db.InsertBooksParams{
+			Title:    b.title,
+			Author:   b.author,
+			Language: b.language,
+			FileName: fmt.Sprintf("%d.epub", i),
+			Rating:   sql.NullFloat64{Float64: b.rating, Valid: true},
+			AddedAt:  time.Now().Format("2006-01-02 15:04:05"),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := h.Queries.UpdateStatusKeepDate(ctx, db.UpdateStatusKeepDateParams{Status: b.status, FileName: fmt.Sprintf("%d.epub", i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"status:unread", []string{"Dune", "Fresh"}},
+		{"status:unread rating>=4", []string{"Dune"}},
+		{"lang:es", []string{"El nombre del viento"}},
+		{"herbert", []string{"Dune"}},
+		{"herbert status:archived", []string{"Old News"}},
+		{"-status:read", []string{"Dune", "Fresh"}},
+		{`"nombre del"`, []string{"El nombre del viento"}},
+		{"added<1d", []string{"Dune", "El nombre del viento", "Fresh"}},
+		{"rating>5", nil},
+	}
+	for _, tt := range tests {
+		got, err := h.QueryBooks(tt.query)
+		if err != nil {
+			t.Errorf("QueryBooks(%q): %v", tt.query, err)
+			continue
+		}
+		var titles []string
+		for _, b := range got {
+			titles = append(titles, b.Title)
+		}
+		if !reflect.DeepEqual(titles, tt.want) {
+			t.Errorf("QueryBooks(%q) = %v, want %v", tt.query, titles, tt.want)
+		}
+	}
+}
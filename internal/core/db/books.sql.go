@@ -21,8 +21,44 @@ func (q *Queries) CheckBookExists(ctx context.Context, fileName string) (int64,
 	return count, err
 }
 
+const countBooksByCover = `-- name: CountBooksByCover :one
+SELECT COUNT(*) FROM books WHERE bookPath = ?
+`
+
+func (q *Queries) CountBooksByCover(ctx context.Context, bookpath string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countBooksByCover, bookpath)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countBooksByTitleAuthor = `-- name: CountBooksByTitleAuthor :one
+SELECT COUNT(*) FROM books WHERE title = ? AND author = ?
+`
+
+type CountBooksByTitleAuthorParams struct {
+	Title  string
+	Author string
+}
+
+func (q *Queries) CountBooksByTitleAuthor(ctx context.Context, arg CountBooksByTitleAuthorParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countBooksByTitleAuthor, arg.Title, arg.Author)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const deleteBook = `-- name: DeleteBook :exec
+DELETE FROM books WHERE id = ?
+`
+
+func (q *Queries) DeleteBook(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deleteBook, id)
+	return err
+}
+
 const insertBooks = `-- name: InsertBooks :many
-INSERT INTO books (title, author, description, genres, language, file_name, bookPath, rating) VALUES (?, ?, ?, ?, ?, ?, ?, ?) RETURNING id, title, author, description, genres, language, file_name, bookpath, rating, status, reading_date
+INSERT INTO books (title, author, description, genres, language, file_name, bookPath, rating, added_at, series, series_index, content_hash, dedup_key) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id, title, author, description, genres, language, file_name, bookpath, rating, status, reading_date, added_at, series, series_index, content_hash, dedup_key, publish_year, page_count, isbns, ol_work, enriched_at
 `
 
 type InsertBooksParams struct {
@@ -34,6 +70,11 @@ type InsertBooksParams struct {
 	FileName    string
 	Bookpath    string
 	Rating      sql.NullFloat64
+	AddedAt     string
+	Series      sql.NullString
+	SeriesIndex float64
+	ContentHash string
+	DedupKey    string
 }
 
 func (q *Queries) InsertBooks(ctx context.Context, arg InsertBooksParams) ([]Book, error) {
@@ -46,6 +87,11 @@ func (q *Queries) InsertBooks(ctx context.Context, arg InsertBooksParams) ([]Boo
 		arg.FileName,
 		arg.Bookpath,
 		arg.Rating,
+		arg.AddedAt,
+		arg.Series,
+		arg.SeriesIndex,
+		arg.ContentHash,
+		arg.DedupKey,
 	)
 	if err != nil {
 		return nil, err
@@ -66,6 +112,16 @@ func (q *Queries) InsertBooks(ctx context.Context, arg InsertBooksParams) ([]Boo
 			&i.Rating,
 			&i.Status,
 			&i.ReadingDate,
+			&i.AddedAt,
+			&i.Series,
+			&i.SeriesIndex,
+			&i.ContentHash,
+			&i.DedupKey,
+			&i.PublishYear,
+			&i.PageCount,
+			&i.Isbns,
+			&i.OlWork,
+			&i.EnrichedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -81,7 +137,7 @@ func (q *Queries) InsertBooks(ctx context.Context, arg InsertBooksParams) ([]Boo
 }
 
 const listBooks = `-- name: ListBooks :many
-SELECT title, author, file_name, bookPath, rating, genres, status, reading_date FROM books ORDER BY title
+SELECT title, author, file_name, bookPath, rating, genres, status, reading_date, series, series_index FROM books ORDER BY title
 `
 
 type ListBooksRow struct {
@@ -93,6 +149,8 @@ type ListBooksRow struct {
 	Genres      string
 	Status      string
 	ReadingDate string
+	Series      sql.NullString
+	SeriesIndex float64
 }
 
 func (q *Queries) ListBooks(ctx context.Context) ([]ListBooksRow, error) {
@@ -113,6 +171,8 @@ func (q *Queries) ListBooks(ctx context.Context) ([]ListBooksRow, error) {
 			&i.Genres,
 			&i.Status,
 			&i.ReadingDate,
+			&i.Series,
+			&i.SeriesIndex,
 		); err != nil {
 			return nil, err
 		}
@@ -127,8 +187,72 @@ func (q *Queries) ListBooks(ctx context.Context) ([]ListBooksRow, error) {
 	return items, nil
 }
 
+const replaceBookFile = `-- name: ReplaceBookFile :exec
+UPDATE books SET title = ?, author = ?, description = ?, genres = ?, language = ?, file_name = ?, bookPath = ?, series = ?, series_index = ?, content_hash = ?, dedup_key = ? WHERE id = ?
+`
+
+type ReplaceBookFileParams struct {
+	Title       string
+	Author      string
+	Description string
+	Genres      string
+	Language    string
+	FileName    string
+	Bookpath    string
+	Series      sql.NullString
+	SeriesIndex float64
+	ContentHash string
+	DedupKey    string
+	ID          int64
+}
+
+func (q *Queries) ReplaceBookFile(ctx context.Context, arg ReplaceBookFileParams) error {
+	_, err := q.db.ExecContext(ctx, replaceBookFile,
+		arg.Title,
+		arg.Author,
+		arg.Description,
+		arg.Genres,
+		arg.Language,
+		arg.FileName,
+		arg.Bookpath,
+		arg.Series,
+		arg.SeriesIndex,
+		arg.ContentHash,
+		arg.DedupKey,
+		arg.ID,
+	)
+	return err
+}
+
+const searchBooks = `-- name: SearchBooks :many
+SELECT books.file_name FROM books_fts JOIN books ON books.id = books_fts.rowid WHERE books_fts MATCH CAST(? AS TEXT) ORDER BY bm25(books_fts, 10.0, 5.0, 2.0, 1.0)
+`
+
+func (q *Queries) SearchBooks(ctx context.Context, query string) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, searchBooks, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var file_name string
+		if err := rows.Scan(&file_name); err != nil {
+			return nil, err
+		}
+		items = append(items, file_name)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const selectAllBooks = `-- name: SelectAllBooks :many
-SELECT id, title, author, description, genres, language, file_name, bookpath, rating, status, reading_date FROM books ORDER BY title
+SELECT id, title, author, description, genres, language, file_name, bookpath, rating, status, reading_date, added_at, series, series_index, content_hash, dedup_key, publish_year, page_count, isbns, ol_work, enriched_at FROM books ORDER BY title
 `
 
 func (q *Queries) SelectAllBooks(ctx context.Context) ([]Book, error) {
@@ -152,6 +276,16 @@ func (q *Queries) SelectAllBooks(ctx context.Context) ([]Book, error) {
 			&i.Rating,
 			&i.Status,
 			&i.ReadingDate,
+			&i.AddedAt,
+			&i.Series,
+			&i.SeriesIndex,
+			&i.ContentHash,
+			&i.DedupKey,
+			&i.PublishYear,
+			&i.PageCount,
+			&i.Isbns,
+			&i.OlWork,
+			&i.EnrichedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -166,6 +300,105 @@ func (q *Queries) SelectAllBooks(ctx context.Context) ([]Book, error) {
 	return items, nil
 }
 
+const selectBookByContentHash = `-- name: SelectBookByContentHash :one
+SELECT id, title, author, description, genres, language, file_name, bookpath, rating, status, reading_date, added_at, series, series_index, content_hash, dedup_key, publish_year, page_count, isbns, ol_work, enriched_at FROM books WHERE content_hash = ? LIMIT 1
+`
+
+func (q *Queries) SelectBookByContentHash(ctx context.Context, contentHash string) (Book, error) {
+	row := q.db.QueryRowContext(ctx, selectBookByContentHash, contentHash)
+	var i Book
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Author,
+		&i.Description,
+		&i.Genres,
+		&i.Language,
+		&i.FileName,
+		&i.Bookpath,
+		&i.Rating,
+		&i.Status,
+		&i.ReadingDate,
+		&i.AddedAt,
+		&i.Series,
+		&i.SeriesIndex,
+		&i.ContentHash,
+		&i.DedupKey,
+		&i.PublishYear,
+		&i.PageCount,
+		&i.Isbns,
+		&i.OlWork,
+		&i.EnrichedAt,
+	)
+	return i, err
+}
+
+const selectBookByDedupKey = `-- name: SelectBookByDedupKey :one
+SELECT id, title, author, description, genres, language, file_name, bookpath, rating, status, reading_date, added_at, series, series_index, content_hash, dedup_key, publish_year, page_count, isbns, ol_work, enriched_at FROM books WHERE dedup_key = ? ORDER BY id LIMIT 1
+`
+
+func (q *Queries) SelectBookByDedupKey(ctx context.Context, dedupKey string) (Book, error) {
+	row := q.db.QueryRowContext(ctx, selectBookByDedupKey, dedupKey)
+	var i Book
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Author,
+		&i.Description,
+		&i.Genres,
+		&i.Language,
+		&i.FileName,
+		&i.Bookpath,
+		&i.Rating,
+		&i.Status,
+		&i.ReadingDate,
+		&i.AddedAt,
+		&i.Series,
+		&i.SeriesIndex,
+		&i.ContentHash,
+		&i.DedupKey,
+		&i.PublishYear,
+		&i.PageCount,
+		&i.Isbns,
+		&i.OlWork,
+		&i.EnrichedAt,
+	)
+	return i, err
+}
+
+const selectBookByFileName = `-- name: SelectBookByFileName :one
+SELECT id, title, author, description, genres, language, file_name, bookpath, rating, status, reading_date, added_at, series, series_index, content_hash, dedup_key, publish_year, page_count, isbns, ol_work, enriched_at FROM books WHERE file_name = ?
+`
+
+func (q *Queries) SelectBookByFileName(ctx context.Context, fileName string) (Book, error) {
+	row := q.db.QueryRowContext(ctx, selectBookByFileName, fileName)
+	var i Book
+	err := row.Scan(
+		&i.ID,
+		&i.Title,
+		&i.Author,
+		&i.Description,
+		&i.Genres,
+		&i.Language,
+		&i.FileName,
+		&i.Bookpath,
+		&i.Rating,
+		&i.Status,
+		&i.ReadingDate,
+		&i.AddedAt,
+		&i.Series,
+		&i.SeriesIndex,
+		&i.ContentHash,
+		&i.DedupKey,
+		&i.PublishYear,
+		&i.PageCount,
+		&i.Isbns,
+		&i.OlWork,
+		&i.EnrichedAt,
+	)
+	return i, err
+}
+
 const selectBookPath = `-- name: SelectBookPath :one
 SELECT bookPath FROM books WHERE file_name = ?
 `
@@ -177,6 +410,104 @@ func (q *Queries) SelectBookPath(ctx context.Context, fileName string) (string,
 	return bookpath, err
 }
 
+const selectBooksToEnrich = `-- name: SelectBooksToEnrich :many
+SELECT file_name FROM books WHERE enriched_at = '' AND status <> 'Archived' ORDER BY title
+`
+
+func (q *Queries) SelectBooksToEnrich(ctx context.Context) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, selectBooksToEnrich)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var file_name string
+		if err := rows.Scan(&file_name); err != nil {
+			return nil, err
+		}
+		items = append(items, file_name)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectBooksWithoutHash = `-- name: SelectBooksWithoutHash :many
+SELECT id, title, author, file_name FROM books WHERE content_hash = ''
+`
+
+type SelectBooksWithoutHashRow struct {
+	ID       int64
+	Title    string
+	Author   string
+	FileName string
+}
+
+func (q *Queries) SelectBooksWithoutHash(ctx context.Context) ([]SelectBooksWithoutHashRow, error) {
+	rows, err := q.db.QueryContext(ctx, selectBooksWithoutHash)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SelectBooksWithoutHashRow
+	for rows.Next() {
+		var i SelectBooksWithoutHashRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Author,
+			&i.FileName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const selectBooksWithoutSeries = `-- name: SelectBooksWithoutSeries :many
+SELECT id, file_name FROM books WHERE series IS NULL
+`
+
+type SelectBooksWithoutSeriesRow struct {
+	ID       int64
+	FileName string
+}
+
+func (q *Queries) SelectBooksWithoutSeries(ctx context.Context) ([]SelectBooksWithoutSeriesRow, error) {
+	rows, err := q.db.QueryContext(ctx, selectBooksWithoutSeries)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SelectBooksWithoutSeriesRow
+	for rows.Next() {
+		var i SelectBooksWithoutSeriesRow
+		if err := rows.Scan(&i.ID, &i.FileName); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const selectFileNames = `-- name: SelectFileNames :many
 SELECT file_name FROM books
 `
@@ -204,6 +535,156 @@ func (q *Queries) SelectFileNames(ctx context.Context) ([]string, error) {
 	return items, nil
 }
 
+const updateBookAuthor = `-- name: UpdateBookAuthor :exec
+UPDATE books SET author = ? WHERE id = ?
+`
+
+type UpdateBookAuthorParams struct {
+	Author string
+	ID     int64
+}
+
+func (q *Queries) UpdateBookAuthor(ctx context.Context, arg UpdateBookAuthorParams) error {
+	_, err := q.db.ExecContext(ctx, updateBookAuthor, arg.Author, arg.ID)
+	return err
+}
+
+const updateBookCover = `-- name: UpdateBookCover :exec
+UPDATE books SET bookPath = ? WHERE id = ?
+`
+
+type UpdateBookCoverParams struct {
+	Bookpath string
+	ID       int64
+}
+
+func (q *Queries) UpdateBookCover(ctx context.Context, arg UpdateBookCoverParams) error {
+	_, err := q.db.ExecContext(ctx, updateBookCover, arg.Bookpath, arg.ID)
+	return err
+}
+
+const updateBookEnrichedAt = `-- name: UpdateBookEnrichedAt :exec
+UPDATE books SET enriched_at = ? WHERE id = ?
+`
+
+type UpdateBookEnrichedAtParams struct {
+	EnrichedAt string
+	ID         int64
+}
+
+func (q *Queries) UpdateBookEnrichedAt(ctx context.Context, arg UpdateBookEnrichedAtParams) error {
+	_, err := q.db.ExecContext(ctx, updateBookEnrichedAt, arg.EnrichedAt, arg.ID)
+	return err
+}
+
+const updateBookEnrichment = `-- name: UpdateBookEnrichment :exec
+UPDATE books SET description = ?, genres = ?, publish_year = ?, page_count = ?, isbns = ?, series = ?, series_index = ?, ol_work = ?, enriched_at = ? WHERE id = ?
+`
+
+type UpdateBookEnrichmentParams struct {
+	Description string
+	Genres      string
+	PublishYear int64
+	PageCount   int64
+	Isbns       string
+	Series      sql.NullString
+	SeriesIndex float64
+	OlWork      string
+	EnrichedAt  string
+	ID          int64
+}
+
+func (q *Queries) UpdateBookEnrichment(ctx context.Context, arg UpdateBookEnrichmentParams) error {
+	_, err := q.db.ExecContext(ctx, updateBookEnrichment,
+		arg.Description,
+		arg.Genres,
+		arg.PublishYear,
+		arg.PageCount,
+		arg.Isbns,
+		arg.Series,
+		arg.SeriesIndex,
+		arg.OlWork,
+		arg.EnrichedAt,
+		arg.ID,
+	)
+	return err
+}
+
+const updateBookFileName = `-- name: UpdateBookFileName :exec
+UPDATE books SET file_name = ? WHERE id = ?
+`
+
+type UpdateBookFileNameParams struct {
+	FileName string
+	ID       int64
+}
+
+func (q *Queries) UpdateBookFileName(ctx context.Context, arg UpdateBookFileNameParams) error {
+	_, err := q.db.ExecContext(ctx, updateBookFileName, arg.FileName, arg.ID)
+	return err
+}
+
+const updateBookFingerprint = `-- name: UpdateBookFingerprint :exec
+UPDATE books SET content_hash = ?, dedup_key = ? WHERE id = ?
+`
+
+type UpdateBookFingerprintParams struct {
+	ContentHash string
+	DedupKey    string
+	ID          int64
+}
+
+func (q *Queries) UpdateBookFingerprint(ctx context.Context, arg UpdateBookFingerprintParams) error {
+	_, err := q.db.ExecContext(ctx, updateBookFingerprint, arg.ContentHash, arg.DedupKey, arg.ID)
+	return err
+}
+
+const updateBookMetadata = `-- name: UpdateBookMetadata :exec
+UPDATE books SET title = ?, author = ?, description = ?, genres = ?, language = ?, series = ?, series_index = ?, dedup_key = ? WHERE id = ?
+`
+
+type UpdateBookMetadataParams struct {
+	Title       string
+	Author      string
+	Description string
+	Genres      string
+	Language    string
+	Series      sql.NullString
+	SeriesIndex float64
+	DedupKey    string
+	ID          int64
+}
+
+func (q *Queries) UpdateBookMetadata(ctx context.Context, arg UpdateBookMetadataParams) error {
+	_, err := q.db.ExecContext(ctx, updateBookMetadata,
+		arg.Title,
+		arg.Author,
+		arg.Description,
+		arg.Genres,
+		arg.Language,
+		arg.Series,
+		arg.SeriesIndex,
+		arg.DedupKey,
+		arg.ID,
+	)
+	return err
+}
+
+const updateBookSeries = `-- name: UpdateBookSeries :exec
+UPDATE books SET series = ?, series_index = ? WHERE id = ?
+`
+
+type UpdateBookSeriesParams struct {
+	Series      sql.NullString
+	SeriesIndex float64
+	ID          int64
+}
+
+func (q *Queries) UpdateBookSeries(ctx context.Context, arg UpdateBookSeriesParams) error {
+	_, err := q.db.ExecContext(ctx, updateBookSeries, arg.Series, arg.SeriesIndex, arg.ID)
+	return err
+}
+
 const updateRating = `-- name: UpdateRating :exec
 UPDATE books SET rating = ? WHERE file_name = ?
 `
@@ -232,3 +713,17 @@ func (q *Queries) UpdateStatus(ctx context.Context, arg UpdateStatusParams) erro
 	_, err := q.db.ExecContext(ctx, updateStatus, arg.Status, arg.ReadingDate, arg.FileName)
 	return err
 }
+
+const updateStatusKeepDate = `-- name: UpdateStatusKeepDate :exec
+UPDATE books SET status = ? WHERE file_name = ?
+`
+
+type UpdateStatusKeepDateParams struct {
+	Status   string
+	FileName string
+}
+
+func (q *Queries) UpdateStatusKeepDate(ctx context.Context, arg UpdateStatusKeepDateParams) error {
+	_, err := q.db.ExecContext(ctx, updateStatusKeepDate, arg.Status, arg.FileName)
+	return err
+}
@@ -8,6 +8,12 @@ import (
 	"database/sql"
 )
 
+type Author struct {
+	ID     int64
+	Name   string
+	FileAs string
+}
+
 type Book struct {
 	ID          int64
 	Title       string
@@ -20,4 +26,86 @@ type Book struct {
 	Rating      sql.NullFloat64
 	Status      string
 	ReadingDate string
+	AddedAt     string
+	Series      sql.NullString
+	SeriesIndex float64
+	ContentHash string
+	DedupKey    string
+	PublishYear int64
+	PageCount   int64
+	Isbns       string
+	OlWork      string
+	EnrichedAt  string
+}
+
+type BookAuthor struct {
+	BookID   int64
+	AuthorID int64
+	Role     string
+	Position int64
+}
+
+type Cover struct {
+	BookID    int64
+	Hash      string
+	Path      string
+	Format    string
+	Source    string
+	Width     int64
+	Height    int64
+	UpdatedAt string
+	Pinned    int64
+}
+
+type CoverJob struct {
+	BookID        int64
+	Status        string
+	Attempts      int64
+	NextAttemptAt string
+	LastError     string
+	UpdatedAt     string
+}
+
+type ReadingProgress struct {
+	BookID      int64
+	SpineIndex  int64
+	SpineOffset float64
+	Percent     float64
+	OpenedAt    string
+}
+
+type ReadingSession struct {
+	ID         int64
+	BookID     int64
+	StartedAt  string
+	FinishedAt string
+	Rating     float64
+	Notes      string
+}
+
+type WordCount struct {
+	BookID      int64
+	ContentHash string
+	Words       int64
+}
+
+type Goal struct {
+	ID       int64
+	Year     int64
+	Name     string
+	Unit     string
+	Target   int64
+	Language string
+	Genre    string
+}
+
+type Shelf struct {
+	ID    int64
+	Name  string
+	Query string
+}
+
+type BookShelf struct {
+	BookID  int64
+	ShelfID int64
 }
@@ -0,0 +1,272 @@
+package storage
+
+import (
+	"Kindria/internal/core/platform/storage/migrations"
+	"bufio"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Version   int64
+	Name      string
+	AppliedAt string
+}
+
+func (s MigrationStatus) Applied() bool {
+	return s.AppliedAt != ""
+}
+
+const createSchemaVersion = `CREATE TABLE IF NOT EXISTS schema_version (
+    version INTEGER PRIMARY KEY,
+    name TEXT NOT NULL,
+    applied_at TEXT NOT NULL
+)`
+
+// Databases created by the old Makefile bootstrap have no schema_version
+// table; these columns tell which of the early migrations they already ran.
+var legacyMarkers = []struct {
+	version int64
+	table   string
+	column  string
+}{
+	{version: 1, table: "books", column: "id"},
+	{version: 2, table: "books", column: "status"},
+	{version: 3, table: "books", column: "reading_date"},
+}
+
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Migration, 0, len(entries))
+	seen := make(map[int64]string)
+	for _, e := range entries {
+		if e.IsDir() || path.Ext(e.Name()) != ".sql" {
+			continue
+		}
+		prefix, name, ok := strings.Cut(strings.TrimSuffix(e.Name(), ".sql"), "_")
+		if !ok {
+			return nil, fmt.Errorf("migration %s: expected <version>_<name>.sql", e.Name())
+		}
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: invalid version: %w", e.Name(), err)
+		}
+		if other, ok := seen[version]; ok {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", other, e.Name(), version)
+		}
+		seen[version] = e.Name()
+		data, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, err
+		}
+		up, down := splitGooseSections(string(data))
+		if strings.TrimSpace(up) == "" {
+			return nil, fmt.Errorf("migration %s: empty '-- +goose Up' section", e.Name())
+		}
+		out = append(out, Migration{Version: version, Name: name, Up: up, Down: down})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out, nil
+}
+
+func splitGooseSections(src string) (string, string) {
+	var up, down strings.Builder
+	var current *strings.Builder
+	sc := bufio.NewScanner(strings.NewReader(src))
+	for sc.Scan() {
+		line := sc.Text()
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "-- +goose Up"):
+			current = &up
+			continue
+		case strings.HasPrefix(trimmed, "-- +goose Down"):
+			current = &down
+			continue
+		case strings.HasPrefix(trimmed, "-- +goose"):
+			continue
+		}
+		if current != nil {
+			current.WriteString(line)
+			current.WriteString("\n")
+		}
+	}
+	return up.String(), down.String()
+}
+
+// Migrate applies every pending embedded migration, each in its own
+// transaction, and returns the ones that ran.
+func Migrate(db *sql.DB) ([]Migration, error) {
+	all, err := LoadMigrations(migrations.FS)
+	if err != nil {
+		return nil, err
+	}
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+	ran := make([]Migration, 0)
+	for _, m := range all {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		if err := runMigration(db, m, true); err != nil {
+			return ran, err
+		}
+		ran = append(ran, m)
+	}
+	return ran, nil
+}
+
+// Rollback reverts the most recently applied migration. It returns nil when
+// nothing is applied.
+func Rollback(db *sql.DB) (*Migration, error) {
+	all, err := LoadMigrations(migrations.FS)
+	if err != nil {
+		return nil, err
+	}
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(all) - 1; i >= 0; i-- {
+		m := all[i]
+		if _, ok := applied[m.Version]; !ok {
+			continue
+		}
+		if strings.TrimSpace(m.Down) == "" {
+			return nil, fmt.Errorf("migration %05d_%s has no '-- +goose Down' section", m.Version, m.Name)
+		}
+		if err := runMigration(db, m, false); err != nil {
+			return nil, err
+		}
+		return &m, nil
+	}
+	return nil, nil
+}
+
+func Status(db *sql.DB) ([]MigrationStatus, error) {
+	all, err := LoadMigrations(migrations.FS)
+	if err != nil {
+		return nil, err
+	}
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]MigrationStatus, 0, len(all))
+	for _, m := range all {
+		out = append(out, MigrationStatus{Version: m.Version, Name: m.Name, AppliedAt: applied[m.Version]})
+	}
+	return out, nil
+}
+
+func runMigration(db *sql.DB, m Migration, up bool) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	direction, script := "up", m.Up
+	if !up {
+		direction, script = "down", m.Down
+	}
+	if _, err := tx.Exec(script); err != nil {
+		return fmt.Errorf("migration %05d_%s (%s): %w", m.Version, m.Name, direction, err)
+	}
+	if up {
+		_, err = tx.Exec("INSERT INTO schema_version (version, name, applied_at) VALUES (?, ?, ?)",
+			m.Version, m.Name, time.Now().UTC().Format(time.RFC3339))
+	} else {
+		_, err = tx.Exec("DELETE FROM schema_version WHERE version = ?", m.Version)
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func appliedVersions(db *sql.DB) (map[int64]string, error) {
+	if _, err := db.Exec(createSchemaVersion); err != nil {
+		return nil, err
+	}
+	if err := baselineLegacySchema(db); err != nil {
+		return nil, err
+	}
+	rows, err := db.Query("SELECT version, applied_at FROM schema_version")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	applied := make(map[int64]string)
+	for rows.Next() {
+		var (
+			version   int64
+			appliedAt string
+		)
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+	return applied, rows.Err()
+}
+
+func baselineLegacySchema(db *sql.DB) error {
+	var count int
+	if err := db.QueryRow("SELECT COUNT(*) FROM schema_version").Scan(&count); err != nil {
+		return err
+	}
+	if count > 0 {
+		return nil
+	}
+	all, err := LoadMigrations(migrations.FS)
+	if err != nil {
+		return err
+	}
+	names := make(map[int64]string, len(all))
+	for _, m := range all {
+		names[m.Version] = m.Name
+	}
+	now := time.Now().UTC().Format(time.RFC3339)
+	for _, marker := range legacyMarkers {
+		ok, err := hasColumn(db, marker.table, marker.column)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+		if _, err := db.Exec("INSERT INTO schema_version (version, name, applied_at) VALUES (?, ?, ?)",
+			marker.version, names[marker.version], now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func hasColumn(db *sql.DB, table, column string) (bool, error) {
+	var count int
+	err := db.QueryRow("SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?", table, column).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,41 @@
+-- +goose Up
+CREATE VIRTUAL TABLE books_fts USING fts5(
+    title,
+    author,
+    genres,
+    description,
+    content='books',
+    content_rowid='id',
+    tokenize='unicode61 remove_diacritics 2'
+);
+
+-- +goose StatementBegin
+CREATE TRIGGER books_fts_after_insert AFTER INSERT ON books BEGIN
+    INSERT INTO books_fts (rowid, title, author, genres, description)
+    VALUES (new.id, new.title, new.author, new.genres, new.description);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER books_fts_after_delete AFTER DELETE ON books BEGIN
+    INSERT INTO books_fts (books_fts, rowid, title, author, genres, description)
+    VALUES ('delete', old.id, old.title, old.author, old.genres, old.description);
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER books_fts_after_update AFTER UPDATE OF title, author, genres, description ON books BEGIN
+    INSERT INTO books_fts (books_fts, rowid, title, author, genres, description)
+    VALUES ('delete', old.id, old.title, old.author, old.genres, old.description);
+    INSERT INTO books_fts (rowid, title, author, genres, description)
+    VALUES (new.id, new.title, new.author, new.genres, new.description);
+END;
+-- +goose StatementEnd
+
+INSERT INTO books_fts (books_fts) VALUES ('rebuild');
+
+-- +goose Down
+DROP TRIGGER books_fts_after_update;
+DROP TRIGGER books_fts_after_delete;
+DROP TRIGGER books_fts_after_insert;
+DROP TABLE books_fts;
@@ -0,0 +1,6 @@
+-- +goose Up
+ALTER TABLE books ADD added_at TEXT NOT NULL DEFAULT '';
+UPDATE books SET added_at = strftime('%Y-%m-%d %H:%M:%S', 'now');
+
+-- +goose Down
+ALTER TABLE books DROP COLUMN added_at;
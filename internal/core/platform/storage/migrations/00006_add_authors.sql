@@ -0,0 +1,20 @@
+-- +goose Up
+CREATE TABLE authors (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+    file_as TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE book_authors (
+    book_id INTEGER NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    author_id INTEGER NOT NULL REFERENCES authors(id) ON DELETE CASCADE,
+    role TEXT NOT NULL DEFAULT 'aut',
+    position INTEGER NOT NULL DEFAULT 0,
+    PRIMARY KEY (book_id, author_id, role)
+);
+
+CREATE INDEX book_authors_author_id ON book_authors(author_id);
+
+-- +goose Down
+DROP TABLE book_authors;
+DROP TABLE authors;
@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE books ADD series TEXT;
+ALTER TABLE books ADD series_index REAL NOT NULL DEFAULT 0;
+CREATE INDEX books_series ON books(series);
+
+-- +goose Down
+DROP INDEX books_series;
+ALTER TABLE books DROP COLUMN series_index;
+ALTER TABLE books DROP COLUMN series;
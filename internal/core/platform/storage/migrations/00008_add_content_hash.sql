@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE books ADD content_hash TEXT NOT NULL DEFAULT '';
+ALTER TABLE books ADD dedup_key TEXT NOT NULL DEFAULT '';
+CREATE INDEX books_content_hash ON books(content_hash);
+CREATE INDEX books_dedup_key ON books(dedup_key);
+
+-- +goose Down
+DROP INDEX books_dedup_key;
+DROP INDEX books_content_hash;
+ALTER TABLE books DROP COLUMN dedup_key;
+ALTER TABLE books DROP COLUMN content_hash;
@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE covers (
+    book_id INTEGER PRIMARY KEY REFERENCES books(id) ON DELETE CASCADE,
+    hash TEXT NOT NULL,
+    path TEXT NOT NULL,
+    format TEXT NOT NULL,
+    source TEXT NOT NULL CHECK (source IN ('epub', 'openlibrary', 'manual')),
+    width INTEGER NOT NULL DEFAULT 0,
+    height INTEGER NOT NULL DEFAULT 0,
+    updated_at TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX covers_hash ON covers(hash);
+
+-- +goose Down
+DROP TABLE covers;
@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE cover_jobs (
+    book_id INTEGER PRIMARY KEY REFERENCES books(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'done', 'missing', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TEXT NOT NULL DEFAULT '',
+    last_error TEXT NOT NULL DEFAULT '',
+    updated_at TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX cover_jobs_due ON cover_jobs(status, next_attempt_at);
+
+INSERT INTO cover_jobs (book_id) SELECT id FROM books WHERE bookPath = '';
+
+-- +goose Down
+DROP TABLE cover_jobs;
@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE books ADD publish_year INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE books ADD page_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE books ADD isbns TEXT NOT NULL DEFAULT '';
+ALTER TABLE books ADD ol_work TEXT NOT NULL DEFAULT '';
+ALTER TABLE books ADD enriched_at TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE books DROP COLUMN enriched_at;
+ALTER TABLE books DROP COLUMN ol_work;
+ALTER TABLE books DROP COLUMN isbns;
+ALTER TABLE books DROP COLUMN page_count;
+ALTER TABLE books DROP COLUMN publish_year;
@@ -0,0 +1,33 @@
+-- +goose Up
+CREATE TABLE covers_new (
+    book_id INTEGER PRIMARY KEY REFERENCES books(id) ON DELETE CASCADE,
+    hash TEXT NOT NULL,
+    path TEXT NOT NULL,
+    format TEXT NOT NULL,
+    source TEXT NOT NULL CHECK (source IN ('epub', 'openlibrary', 'local', 'manual')),
+    width INTEGER NOT NULL DEFAULT 0,
+    height INTEGER NOT NULL DEFAULT 0,
+    updated_at TEXT NOT NULL DEFAULT ''
+);
+
+INSERT INTO covers_new SELECT book_id, hash, path, format, source, width, height, updated_at FROM covers;
+DROP TABLE covers;
+ALTER TABLE covers_new RENAME TO covers;
+CREATE INDEX covers_hash ON covers(hash);
+
+-- +goose Down
+CREATE TABLE covers_old (
+    book_id INTEGER PRIMARY KEY REFERENCES books(id) ON DELETE CASCADE,
+    hash TEXT NOT NULL,
+    path TEXT NOT NULL,
+    format TEXT NOT NULL,
+    source TEXT NOT NULL CHECK (source IN ('epub', 'openlibrary', 'manual')),
+    width INTEGER NOT NULL DEFAULT 0,
+    height INTEGER NOT NULL DEFAULT 0,
+    updated_at TEXT NOT NULL DEFAULT ''
+);
+
+INSERT INTO covers_old SELECT book_id, hash, path, format, CASE source WHEN 'local' THEN 'manual' ELSE source END, width, height, updated_at FROM covers;
+DROP TABLE covers;
+ALTER TABLE covers_old RENAME TO covers;
+CREATE INDEX covers_hash ON covers(hash);
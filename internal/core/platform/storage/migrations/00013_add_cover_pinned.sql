@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE covers ADD pinned INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE covers DROP COLUMN pinned;
@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE reading_progress (
+    book_id INTEGER PRIMARY KEY REFERENCES books(id) ON DELETE CASCADE,
+    spine_index INTEGER NOT NULL DEFAULT 0,
+    spine_offset REAL NOT NULL DEFAULT 0,
+    percent REAL NOT NULL DEFAULT 0,
+    opened_at TEXT NOT NULL DEFAULT ''
+);
+
+-- +goose Down
+DROP TABLE reading_progress;
@@ -0,0 +1,37 @@
+-- +goose Up
+CREATE TABLE reading_sessions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    book_id INTEGER NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    started_at TEXT NOT NULL DEFAULT '',
+    finished_at TEXT NOT NULL DEFAULT '',
+    rating REAL NOT NULL DEFAULT 0,
+    notes TEXT NOT NULL DEFAULT ''
+);
+
+CREATE INDEX reading_sessions_book_id ON reading_sessions(book_id);
+
+-- Sessions are append-only: an open session can be finished once, after that
+-- only its rating and notes change, and rows only go away with their book.
+-- +goose StatementBegin
+CREATE TRIGGER reading_sessions_before_update BEFORE UPDATE OF book_id, started_at, finished_at ON reading_sessions
+WHEN old.finished_at <> '' OR new.book_id <> old.book_id OR new.started_at <> old.started_at BEGIN
+    SELECT RAISE(ABORT, 'reading sessions are append-only');
+END;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER reading_sessions_before_delete BEFORE DELETE ON reading_sessions
+WHEN EXISTS (SELECT 1 FROM books WHERE id = old.book_id) BEGIN
+    SELECT RAISE(ABORT, 'reading sessions are append-only');
+END;
+-- +goose StatementEnd
+
+INSERT INTO reading_sessions (book_id, finished_at, rating)
+SELECT id, reading_date, COALESCE(rating, 0) FROM books WHERE reading_date <> '' ORDER BY reading_date, id;
+
+INSERT INTO reading_sessions (book_id, started_at)
+SELECT b.id, substr(p.opened_at, 1, 10) FROM books b JOIN reading_progress p ON p.book_id = b.id
+WHERE b.status = 'Currently Reading' ORDER BY p.opened_at, b.id;
+
+-- +goose Down
+DROP TABLE reading_sessions;
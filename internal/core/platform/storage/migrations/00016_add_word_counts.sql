@@ -0,0 +1,9 @@
+-- +goose Up
+CREATE TABLE word_counts (
+    book_id INTEGER PRIMARY KEY REFERENCES books(id) ON DELETE CASCADE,
+    content_hash TEXT NOT NULL DEFAULT '',
+    words INTEGER NOT NULL DEFAULT 0
+);
+
+-- +goose Down
+DROP TABLE word_counts;
@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE goals (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    year INTEGER NOT NULL,
+    name TEXT NOT NULL DEFAULT '',
+    unit TEXT NOT NULL DEFAULT 'books' CHECK (unit IN ('books', 'pages')),
+    target INTEGER NOT NULL CHECK (target > 0),
+    language TEXT NOT NULL DEFAULT '',
+    genre TEXT NOT NULL DEFAULT '',
+    progress INTEGER NOT NULL DEFAULT 0,
+    completed_at TEXT NOT NULL DEFAULT '',
+    UNIQUE (year, name)
+);
+
+-- +goose Down
+DROP TABLE goals;
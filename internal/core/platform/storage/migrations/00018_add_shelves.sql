@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE shelves (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE COLLATE NOCASE
+);
+
+CREATE TABLE book_shelves (
+    book_id INTEGER NOT NULL REFERENCES books(id) ON DELETE CASCADE,
+    shelf_id INTEGER NOT NULL REFERENCES shelves(id) ON DELETE CASCADE,
+    PRIMARY KEY (book_id, shelf_id)
+);
+
+CREATE INDEX book_shelves_shelf_id ON book_shelves(shelf_id);
+
+-- +goose Down
+DROP TABLE book_shelves;
+DROP TABLE shelves;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE shelves ADD query TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE shelves DROP COLUMN query;
@@ -0,0 +1,8 @@
+-- +goose Up
+-- Goal progress is counted from the reading log whenever goals are read.
+ALTER TABLE goals DROP COLUMN completed_at;
+ALTER TABLE goals DROP COLUMN progress;
+
+-- +goose Down
+ALTER TABLE goals ADD progress INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE goals ADD completed_at TEXT NOT NULL DEFAULT '';
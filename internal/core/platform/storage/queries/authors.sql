@@ -0,0 +1,35 @@
+-- name: UpsertAuthor :one
+INSERT INTO authors (name, file_as) VALUES (?, ?)
+ON CONFLICT (name) DO UPDATE SET file_as = CASE WHEN excluded.file_as <> '' THEN excluded.file_as ELSE authors.file_as END
+RETURNING id;
+
+-- name: InsertBookAuthor :exec
+INSERT OR IGNORE INTO book_authors (book_id, author_id, role, position) VALUES (?, ?, ?, ?);
+
+-- name: ListAuthors :many
+SELECT authors.id, authors.name, authors.file_as, COUNT(DISTINCT book_authors.book_id) AS book_count
+FROM authors JOIN book_authors ON book_authors.author_id = authors.id
+GROUP BY authors.id
+ORDER BY CASE WHEN authors.file_as <> '' THEN authors.file_as ELSE authors.name END COLLATE NOCASE;
+
+-- name: SelectAuthorBooks :many
+SELECT books.file_name, books.title, CAST(GROUP_CONCAT(book_authors.role) AS TEXT) AS roles
+FROM book_authors JOIN books ON books.id = book_authors.book_id
+WHERE book_authors.author_id = ?
+GROUP BY books.id
+ORDER BY books.title;
+
+-- name: SelectBooksWithoutAuthors :many
+SELECT id, file_name, author FROM books WHERE id NOT IN (SELECT book_id FROM book_authors);
+
+-- name: SelectBookAuthors :many
+SELECT authors.name, authors.file_as, book_authors.role
+FROM book_authors JOIN authors ON authors.id = book_authors.author_id
+WHERE book_authors.book_id = ?
+ORDER BY book_authors.position;
+
+-- name: DeleteBookAuthors :exec
+DELETE FROM book_authors WHERE book_id = ?;
+
+-- name: DeleteOrphanAuthors :exec
+DELETE FROM authors WHERE id NOT IN (SELECT author_id FROM book_authors);
@@ -1,8 +1,8 @@
 -- name: ListBooks :many
-SELECT title, author, file_name, bookPath, rating, genres, status, reading_date FROM books ORDER BY title;
+SELECT title, author, file_name, bookPath, rating, genres, status, reading_date, series, series_index FROM books ORDER BY title;
 
 -- name: InsertBooks :many
-INSERT INTO books (title, author, description, genres, language, file_name, bookPath, rating) VALUES (?, ?, ?, ?, ?, ?, ?, ?) RETURNING *;
+INSERT INTO books (title, author, description, genres, language, file_name, bookPath, rating, added_at, series, series_index, content_hash, dedup_key) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING *;
 
 -- name: UpdateRating :exec
 UPDATE books SET rating = ? WHERE file_name = ?;
@@ -21,3 +21,63 @@ SELECT COUNT(*) FROM books WHERE file_name = ?;
 
 -- name: SelectAllBooks :many 
 SELECT * FROM books ORDER BY title;
+
+-- name: SearchBooks :many
+SELECT books.file_name FROM books_fts JOIN books ON books.id = books_fts.rowid WHERE books_fts MATCH CAST(sqlc.arg(query) AS TEXT) ORDER BY bm25(books_fts, 10.0, 5.0, 2.0, 1.0);
+
+-- name: UpdateBookAuthor :exec
+UPDATE books SET author = ? WHERE id = ?;
+
+-- name: UpdateBookSeries :exec
+UPDATE books SET series = ?, series_index = ? WHERE id = ?;
+
+-- name: SelectBooksWithoutSeries :many
+SELECT id, file_name FROM books WHERE series IS NULL;
+
+-- name: SelectBookByFileName :one
+SELECT * FROM books WHERE file_name = ?;
+
+-- name: UpdateBookMetadata :exec
+UPDATE books SET title = ?, author = ?, description = ?, genres = ?, language = ?, series = ?, series_index = ?, dedup_key = ? WHERE id = ?;
+
+-- name: DeleteBook :exec
+DELETE FROM books WHERE id = ?;
+
+-- name: CountBooksByCover :one
+SELECT COUNT(*) FROM books WHERE bookPath = ?;
+
+-- name: CountBooksByTitleAuthor :one
+SELECT COUNT(*) FROM books WHERE title = ? AND author = ?;
+
+-- name: UpdateStatusKeepDate :exec
+UPDATE books SET status = ? WHERE file_name = ?;
+
+-- name: SelectBookByContentHash :one
+SELECT * FROM books WHERE content_hash = ? LIMIT 1;
+
+-- name: SelectBookByDedupKey :one
+SELECT * FROM books WHERE dedup_key = ? ORDER BY id LIMIT 1;
+
+-- name: SelectBooksWithoutHash :many
+SELECT id, title, author, file_name FROM books WHERE content_hash = '';
+
+-- name: UpdateBookFingerprint :exec
+UPDATE books SET content_hash = ?, dedup_key = ? WHERE id = ?;
+
+-- name: UpdateBookFileName :exec
+UPDATE books SET file_name = ? WHERE id = ?;
+
+-- name: ReplaceBookFile :exec
+UPDATE books SET title = ?, author = ?, description = ?, genres = ?, language = ?, file_name = ?, bookPath = ?, series = ?, series_index = ?, content_hash = ?, dedup_key = ? WHERE id = ?;
+
+-- name: UpdateBookCover :exec
+UPDATE books SET bookPath = ? WHERE id = ?;
+
+-- name: SelectBooksToEnrich :many
+SELECT file_name FROM books WHERE enriched_at = '' AND status <> 'Archived' ORDER BY title;
+
+-- name: UpdateBookEnrichment :exec
+UPDATE books SET description = ?, genres = ?, publish_year = ?, page_count = ?, isbns = ?, series = ?, series_index = ?, ol_work = ?, enriched_at = ? WHERE id = ?;
+
+-- name: UpdateBookEnrichedAt :exec
+UPDATE books SET enriched_at = ? WHERE id = ?;
@@ -0,0 +1,25 @@
+-- name: EnqueueCoverJob :exec
+INSERT INTO cover_jobs (book_id, status, attempts, next_attempt_at, last_error, updated_at) VALUES (?, 'pending', 0, ?, '', ?)
+ON CONFLICT (book_id) DO UPDATE SET status = 'pending', attempts = 0, next_attempt_at = excluded.next_attempt_at, last_error = '', updated_at = excluded.updated_at;
+
+-- name: SelectDueCoverJob :one
+SELECT cover_jobs.book_id, cover_jobs.attempts, books.title, books.author, books.file_name, books.bookPath
+FROM cover_jobs JOIN books ON books.id = cover_jobs.book_id
+WHERE cover_jobs.status = 'pending' AND cover_jobs.next_attempt_at <= ?
+ORDER BY cover_jobs.next_attempt_at, cover_jobs.book_id
+LIMIT 1;
+
+-- name: SelectNextCoverJobAt :one
+SELECT CAST(COALESCE(MIN(next_attempt_at), '') AS TEXT) FROM cover_jobs WHERE status = 'pending';
+
+-- name: UpdateCoverJob :exec
+UPDATE cover_jobs SET status = ?, attempts = ?, next_attempt_at = ?, last_error = ?, updated_at = ? WHERE book_id = ?;
+
+-- name: ResetRunningCoverJobs :exec
+UPDATE cover_jobs SET status = 'pending' WHERE status = 'running';
+
+-- name: CountCoverJobs :many
+SELECT status, COUNT(*) AS jobs FROM cover_jobs GROUP BY status ORDER BY status;
+
+-- name: RequeueFailedCoverJobs :execrows
+UPDATE cover_jobs SET status = 'pending', attempts = 0, next_attempt_at = ?, last_error = '', updated_at = ? WHERE status IN ('failed', 'missing');
@@ -0,0 +1,12 @@
+-- name: UpsertCover :exec
+INSERT INTO covers (book_id, hash, path, format, source, width, height, updated_at, pinned) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (book_id) DO UPDATE SET hash = excluded.hash, path = excluded.path, format = excluded.format, source = excluded.source, width = excluded.width, height = excluded.height, updated_at = excluded.updated_at, pinned = excluded.pinned;
+
+-- name: SelectCover :one
+SELECT * FROM covers WHERE book_id = ?;
+
+-- name: SelectUntrackedCovers :many
+SELECT id, bookPath FROM books WHERE bookPath <> '' AND id NOT IN (SELECT book_id FROM covers);
+
+-- name: DeleteCover :exec
+DELETE FROM covers WHERE book_id = ?;
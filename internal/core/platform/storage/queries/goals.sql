@@ -0,0 +1,9 @@
+-- name: UpsertGoal :exec
+INSERT INTO goals (year, name, unit, target, language, genre) VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT (year, name) DO UPDATE SET unit = excluded.unit, target = excluded.target, language = excluded.language, genre = excluded.genre;
+
+-- name: SelectGoals :many
+SELECT * FROM goals WHERE year = ? ORDER BY name <> '', name;
+
+-- name: DeleteGoal :execrows
+DELETE FROM goals WHERE year = ? AND name = ?;
@@ -0,0 +1,9 @@
+-- name: UpsertProgress :exec
+INSERT INTO reading_progress (book_id, spine_index, spine_offset, percent, opened_at) VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (book_id) DO UPDATE SET spine_index = excluded.spine_index, spine_offset = excluded.spine_offset, percent = excluded.percent, opened_at = excluded.opened_at;
+
+-- name: SelectProgress :one
+SELECT * FROM reading_progress WHERE book_id = ?;
+
+-- name: SelectAllProgress :many
+SELECT * FROM reading_progress;
@@ -0,0 +1,17 @@
+-- name: InsertSession :exec
+INSERT INTO reading_sessions (book_id, started_at, finished_at, rating, notes) VALUES (?, ?, ?, ?, ?);
+
+-- name: FinishSession :exec
+UPDATE reading_sessions SET finished_at = ? WHERE id = ? AND finished_at = '';
+
+-- name: UpdateSessionRating :exec
+UPDATE reading_sessions SET rating = ? WHERE id = ?;
+
+-- name: UpdateSessionNotes :exec
+UPDATE reading_sessions SET notes = ? WHERE id = ?;
+
+-- name: SelectLatestSession :one
+SELECT * FROM reading_sessions WHERE book_id = ? ORDER BY id DESC LIMIT 1;
+
+-- name: SelectSessions :many
+SELECT * FROM reading_sessions WHERE book_id = ? ORDER BY id;
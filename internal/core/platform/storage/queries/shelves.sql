@@ -0,0 +1,35 @@
+-- name: InsertShelf :exec
+INSERT INTO shelves (name, query) VALUES (?, ?);
+
+-- name: ListShelves :many
+SELECT shelves.id, shelves.name, shelves.query, COUNT(book_shelves.book_id) AS book_count
+FROM shelves LEFT JOIN book_shelves ON book_shelves.shelf_id = shelves.id
+GROUP BY shelves.id
+ORDER BY shelves.name COLLATE NOCASE;
+
+-- name: RenameShelf :execrows
+UPDATE shelves SET name = ? WHERE id = ?;
+
+-- name: UpdateShelfQuery :execrows
+UPDATE shelves SET query = ? WHERE id = ?;
+
+-- name: SelectShelf :one
+SELECT * FROM shelves WHERE id = ?;
+
+-- name: DeleteShelf :execrows
+DELETE FROM shelves WHERE id = ?;
+
+-- name: SelectShelfBooks :many
+SELECT books.file_name, books.title
+FROM book_shelves JOIN books ON books.id = book_shelves.book_id
+WHERE book_shelves.shelf_id = ?
+ORDER BY books.title;
+
+-- name: SelectBookShelfIDs :many
+SELECT shelf_id FROM book_shelves WHERE book_id = ?;
+
+-- name: InsertBookShelf :exec
+INSERT OR IGNORE INTO book_shelves (book_id, shelf_id) VALUES (?, ?);
+
+-- name: DeleteBookShelves :exec
+DELETE FROM book_shelves WHERE book_id = ?;
@@ -0,0 +1,11 @@
+-- name: SelectReadingLog :many
+SELECT s.id, s.started_at, s.finished_at, s.rating, b.id AS book_id, b.title, b.author, b.genres, b.language, b.file_name, b.page_count, b.content_hash,
+    COALESCE(w.words, 0) AS words, COALESCE(w.content_hash, '') AS counted_hash
+FROM reading_sessions s
+JOIN books b ON b.id = s.book_id
+LEFT JOIN word_counts w ON w.book_id = b.id
+ORDER BY s.finished_at, s.id;
+
+-- name: UpsertWordCount :exec
+INSERT INTO word_counts (book_id, content_hash, words) VALUES (?, ?, ?)
+ON CONFLICT (book_id) DO UPDATE SET content_hash = excluded.content_hash, words = excluded.words;
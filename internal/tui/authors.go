@@ -0,0 +1,192 @@
+package tui
+
+import (
+	metadata "Kindria/internal/core/api/books"
+	"fmt"
+	"log"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+func (m *MainModel) loadAuthors() {
+	authors, err := m.library.handler.ListAuthors()
+	if err != nil {
+		log.Printf("Error loading authors: %v", err)
+		m.authorsStatus = "Error loading authors: " + err.Error()
+		return
+	}
+	m.authors = authors
+	m.authorsStatus = ""
+	if m.authorCursor >= len(m.authors) {
+		m.authorCursor = 0
+	}
+	m.loadAuthorBooks()
+}
+
+func (m *MainModel) loadAuthorBooks() {
+	m.authorBooks = nil
+	if m.authorCursor >= len(m.authors) {
+		return
+	}
+	books, err := m.library.handler.AuthorBooks(m.authors[m.authorCursor].ID)
+	if err != nil {
+		log.Printf("Error loading author books: %v", err)
+		return
+	}
+	m.authorBooks = books
+}
+
+func (m *MainModel) updateAuthors(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.library.activeArea == int(sideFocus) {
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			switch keyMsg.String() {
+			case "ctrl+l":
+				m.library.activeArea = int(contentFocus)
+				return m, nil
+			case "enter":
+				return m.openMenuOption(m.library.MenuOptions[m.library.sideBarCursor])
+			}
+		}
+		newLib, cmd := m.library.Update(msg)
+		m.library = newLib.(*Model)
+		return m, cmd
+	}
+
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "q", "ctrl+c":
+			return m, tea.Quit
+		case "esc":
+			m.library.activeArea = int(sideFocus)
+			return m, nil
+		case "up", "k":
+			if m.authorCursor > 0 {
+				m.authorCursor--
+				m.loadAuthorBooks()
+			}
+			return m, nil
+		case "down", "j":
+			if m.authorCursor < len(m.authors)-1 {
+				m.authorCursor++
+				m.loadAuthorBooks()
+			}
+			return m, nil
+		case "enter":
+			if m.authorCursor >= len(m.authors) || len(m.authorBooks) == 0 {
+				return m, nil
+			}
+			files := make([]string, 0, len(m.authorBooks))
+			for _, b := range m.authorBooks {
+				files = append(files, b.FileName)
+			}
+			m.state = librayState
+			m.library.activeArea = int(contentFocus)
+			return m, m.library.SetScopedView("Author: "+m.authors[m.authorCursor].Name, files)
+		}
+	}
+	return m, nil
+}
+
+func (m *MainModel) AuthorsView() string {
+	sidebarView := m.SideBarView()
+	panelWidth := m.library.width - m.sideBarWidth - 4
+	panelHeight := m.library.height + 2
+	if panelWidth < 24 {
+		panelWidth = 24
+	}
+	if panelHeight < 12 {
+		panelHeight = 12
+	}
+	style := lipgloss.NewStyle().Border(lipgloss.RoundedBorder(), true, true, true, true).
+		BorderForeground(subtle).
+		Width(panelWidth).
+		Height(panelHeight)
+	if m.library.activeArea == int(contentFocus) {
+		style = style.BorderForeground(borders)
+	}
+
+	var s strings.Builder
+	s.WriteString("  Authors\n")
+	authorsHint := lipgloss.NewStyle().Foreground(normal).Faint(true).Render("↑/↓ (j/k): move  enter: show books  esc: sidebar")
+	s.WriteString("  " + authorsHint + "\n\n")
+
+	listHeight := panelHeight - 4
+	if m.authorsStatus != "" {
+		s.WriteString("  " + m.authorsStatus + "\n")
+		listHeight--
+	}
+	if len(m.authors) == 0 {
+		s.WriteString("    (no authors yet)\n")
+		content := truncateBlockHeight(truncateViewLines(s.String(), panelWidth-2), panelHeight)
+		return lipgloss.JoinHorizontal(lipgloss.Left, sidebarView, style.Render(content))
+	}
+
+	leftWidth := (panelWidth - 4) * 2 / 5
+	rightWidth := panelWidth - 4 - leftWidth - 2
+	start, end := listWindow(m.authorCursor, len(m.authors), listHeight)
+	left := make([]string, 0, end-start)
+	for i := start; i < end; i++ {
+		a := m.authors[i]
+		prefix := "  "
+		lineStyle := lipgloss.NewStyle().Foreground(normal)
+		if i == m.authorCursor {
+			prefix = "> "
+			lineStyle = lineStyle.Foreground(highlight)
+		}
+		line := fmt.Sprintf("%s%s (%d)", prefix, a.Name, a.BookCount)
+		left = append(left, lineStyle.Render(ansi.Truncate(line, leftWidth, "...")))
+	}
+
+	right := make([]string, 0, len(m.authorBooks)+2)
+	selected := m.authors[m.authorCursor]
+	right = append(right, lipgloss.NewStyle().Foreground(normal).Bold(true).Render(ansi.Truncate(selected.Name, rightWidth, "...")))
+	if selected.FileAs != "" && selected.FileAs != selected.Name {
+		right = append(right, lipgloss.NewStyle().Foreground(normal).Faint(true).Render(ansi.Truncate(selected.FileAs, rightWidth, "...")))
+	}
+	right = append(right, "")
+	for _, b := range m.authorBooks {
+		line := "• " + b.Title
+		roles := make([]string, 0, len(b.Roles))
+		for _, r := range b.Roles {
+			if r != metadata.RoleAuthor {
+				roles = append(roles, metadata.RoleLabel(r))
+			}
+		}
+		if len(roles) > 0 {
+			line += " (" + strings.Join(roles, ", ") + ")"
+		}
+		right = append(right, ansi.Truncate(line, rightWidth, "..."))
+	}
+
+	columns := lipgloss.JoinHorizontal(lipgloss.Top,
+		"  ",
+		lipgloss.NewStyle().Width(leftWidth).Render(strings.Join(left, "\n")),
+		"  ",
+		lipgloss.NewStyle().Width(rightWidth).Render(truncateBlockHeight(strings.Join(right, "\n"), listHeight)),
+	)
+	s.WriteString(columns)
+	content := truncateBlockHeight(truncateViewLines(s.String(), panelWidth-2), panelHeight)
+	return lipgloss.JoinHorizontal(lipgloss.Left, sidebarView, style.Render(content))
+}
+
+// listWindow returns the slice bounds of a list of total items so that cursor
+// stays visible within height lines.
+func listWindow(cursor, total, height int) (int, int) {
+	if height < 1 {
+		height = 1
+	}
+	if total <= height {
+		return 0, total
+	}
+	start := cursor - height/2
+	if start < 0 {
+		start = 0
+	}
+	if start+height > total {
+		start = total - height
+	}
+	return start, start + height
+}
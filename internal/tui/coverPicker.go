@@ -0,0 +1,202 @@
+package tui
+
+import (
+	metadata "Kindria/internal/core/api/books"
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// coverPickerRows is how many choices the picker shows at once.
+const coverPickerRows = 8
+
+type coverChoicesMsg struct {
+	file    string
+	choices []metadata.CoverChoice
+	err     error
+}
+
+type coverPickedMsg struct {
+	file   string
+	detail *metadata.BookDetail
+	err    error
+}
+
+func (m *MainModel) startCoverPicker() (tea.Model, tea.Cmd) {
+	if m.coverLoading || m.detailEnriching {
+		return m, nil
+	}
+	m.coverLoading = true
+	m.detailNotice = "Looking for covers..."
+	m.layoutDetail()
+	h, file := m.library.handler, m.detailBook.BookFile
+	return m, func() tea.Msg {
+		choices, err := h.CoverChoices(context.Background(), file)
+		return coverChoicesMsg{file: file, choices: choices, err: err}
+	}
+}
+
+func (m *MainModel) showCoverChoices(msg coverChoicesMsg) (tea.Model, tea.Cmd) {
+	if m.detail == nil || msg.file != m.detail.Book.BookFile {
+		return m, nil
+	}
+	m.coverLoading = false
+	m.detailNotice = ""
+	if msg.err != nil {
+		log.Printf("Error listing covers of %s: %v", msg.file, msg.err)
+		m.detailNotice = "Some covers could not be listed: " + msg.err.Error()
+	}
+	m.coverChoices = msg.choices
+	m.coverPicking = true
+	m.coverCursor = 0
+	m.coverTyping = false
+	m.coverPathInput = textinput.New()
+	m.coverPathInput.Prompt = "Image file: "
+	m.coverPathInput.Placeholder = "path to a .jpg, .png, .gif or .webp"
+	m.coverPathInput.CharLimit = 512
+	m.coverPathInput.Width = max(m.detailInfoWidth()-len(m.coverPathInput.Prompt)-1, 1)
+	m.layoutDetail()
+	return m, nil
+}
+
+func (m *MainModel) closeCoverPicker() {
+	m.coverPicking = false
+	m.coverTyping = false
+	m.coverChoices = nil
+	m.coverPathInput.Blur()
+}
+
+// updateCoverPicker moves through the choices; the row after the last one
+// asks for a local file.
+func (m *MainModel) updateCoverPicker(keyMsg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.coverTyping {
+		switch keyMsg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "esc":
+			m.coverTyping = false
+			m.coverPathInput.Blur()
+			m.layoutDetail()
+			return m, nil
+		case "enter":
+			path := strings.TrimSpace(m.coverPathInput.Value())
+			if path == "" {
+				return m, nil
+			}
+			return m.pickCover(metadata.CoverChoice{Source: metadata.CoverSourceManual, Ref: path})
+		}
+		var cmd tea.Cmd
+		m.coverPathInput, cmd = m.coverPathInput.Update(keyMsg)
+		return m, cmd
+	}
+
+	switch keyMsg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "up", "k":
+		if m.coverCursor > 0 {
+			m.coverCursor--
+		}
+	case "down", "j":
+		if m.coverCursor < len(m.coverChoices) {
+			m.coverCursor++
+		}
+	case "enter":
+		if m.coverCursor == len(m.coverChoices) {
+			m.coverTyping = true
+			m.layoutDetail()
+			return m, m.coverPathInput.Focus()
+		}
+		return m.pickCover(m.coverChoices[m.coverCursor])
+	case "esc":
+		m.closeCoverPicker()
+		m.detailNotice = ""
+		m.layoutDetail()
+	}
+	return m, nil
+}
+
+func (m *MainModel) pickCover(c metadata.CoverChoice) (tea.Model, tea.Cmd) {
+	m.closeCoverPicker()
+	m.detailNotice = "Saving cover..."
+	m.layoutDetail()
+	h, file := m.library.handler, m.detailBook.BookFile
+	return m, func() tea.Msg {
+		if _, err := h.PickCover(context.Background(), file, c); err != nil {
+			return coverPickedMsg{file: file, err: err}
+		}
+		detail, err := h.BookDetail(file)
+		return coverPickedMsg{file: file, detail: detail, err: err}
+	}
+}
+
+func (m *MainModel) coverPicked(msg coverPickedMsg) (tea.Model, tea.Cmd) {
+	if m.detail == nil || msg.file != m.detail.Book.BookFile {
+		return m, nil
+	}
+	if msg.err != nil {
+		log.Printf("Error saving cover of %s: %v", msg.file, msg.err)
+		m.detailNotice = "Cover not saved: " + msg.err.Error()
+		m.layoutDetail()
+		return m, nil
+	}
+	m.detailNotice = ""
+	m.detail.CoverPath = msg.detail.CoverPath
+	m.detail.Cover = msg.detail.Cover
+	m.layoutDetail()
+	return m, tea.Batch(m.detailCoverCmd(), m.library.syncVisibleWidget())
+}
+
+func (m *MainModel) coverPickerView(width int) string {
+	faint := lipgloss.NewStyle().Foreground(normal).Faint(true)
+	label := lipgloss.NewStyle().Foreground(normal).Bold(true)
+	lines := []string{label.Render("Choose a cover:")}
+
+	rows := len(m.coverChoices) + 1
+	start := 0
+	if m.coverCursor >= coverPickerRows {
+		start = m.coverCursor - coverPickerRows + 1
+	}
+	end := min(start+coverPickerRows, rows)
+	for i := start; i < end; i++ {
+		row := "Local file..."
+		if i < len(m.coverChoices) {
+			c := m.coverChoices[i]
+			row = "[" + c.Source + "] " + c.Label
+			if c.Width > 0 {
+				row += faint.Render(fmt.Sprintf("  %dx%d  score %d", c.Width, c.Height, c.Score))
+			}
+		}
+		row = ansi.Truncate(row, width, "...")
+		if i == m.coverCursor {
+			row = lipgloss.NewStyle().Foreground(highlight).Render(ansi.Strip(row))
+		}
+		lines = append(lines, row)
+	}
+	if m.coverTyping {
+		lines = append(lines, m.coverPathInput.View())
+	} else if m.coverCursor < len(m.coverChoices) {
+		if c := m.coverChoices[m.coverCursor]; len(c.Reasons) > 0 {
+			why := "Score: "
+			if c.Score == 0 {
+				why = "Ruled out: "
+			}
+			lines = append(lines, faint.Render(ansi.Truncate(why+strings.Join(c.Reasons, ", "), width, "...")))
+		}
+	}
+	if m.detailNotice != "" {
+		lines = append(lines, faint.Render(ansi.Truncate(m.detailNotice, width, "...")))
+	}
+	hint := "↑/↓: move  enter: use  esc: cancel"
+	if m.coverTyping {
+		hint = "enter: use  esc: back"
+	}
+	lines = append(lines, faint.Render(ansi.Truncate(hint, width, "...")))
+	return strings.Join(lines, "\n")
+}
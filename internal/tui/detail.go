@@ -0,0 +1,528 @@
+package tui
+
+import (
+	metadata "Kindria/internal/core/api/books"
+	"Kindria/internal/tui/filter"
+	"Kindria/internal/utils"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/blacktop/go-termimg"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+type detailCoverMsg struct {
+	file string
+	data string
+}
+
+func (m *MainModel) openDetail(b *metadata.Package) (tea.Model, tea.Cmd) {
+	detail, err := m.library.handler.BookDetail(b.BookFile)
+	if err != nil {
+		log.Printf("Error loading book detail: %v", err)
+		return m, nil
+	}
+	m.state = detailState
+	m.detail = detail
+	m.detailBook = b
+	m.detailCover = ""
+	m.detailRating = false
+	m.detailProgress = false
+	m.detailNote = false
+	m.detailStatusChanged = false
+	m.detailRatingChanged = false
+	m.detailEdited = false
+	m.detailEnriching = false
+	m.detailEnrichment = nil
+	m.detailNotice = ""
+	m.coverLoading = false
+	m.closeCoverPicker()
+	m.detailView = viewport.New(0, 0)
+	m.layoutDetail()
+	return m, tea.Batch(tea.ClearScreen, m.detailCoverCmd())
+}
+
+func (m *MainModel) closeDetail() (tea.Model, tea.Cmd) {
+	m.state = librayState
+	m.library.activeArea = int(contentFocus)
+	file := m.detailBook.BookFile
+	refresh := m.detailEdited ||
+		(m.detailStatusChanged && m.library.viewDependsOnStatus()) ||
+		(m.detailRatingChanged && (m.library.filter.MinRating > 0 || m.library.filter.SortBy == filter.SortRating || m.library.shelfQuery != ""))
+	m.detail = nil
+	m.detailBook = nil
+	m.detailCover = ""
+	if refresh {
+		return m, tea.Batch(m.library.SetView(m.library.currentView), m.library.focusBook(file))
+	}
+	return m, m.library.focusBook(file)
+}
+
+func (m *MainModel) updateDetail(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case detailCoverMsg:
+		if m.detail != nil && msg.file == m.detail.Book.BookFile {
+			m.detailCover = msg.data
+		}
+		return m, nil
+	case coverLoadedMsg, coversLoadedMsg:
+		newLib, cmd := m.library.Update(msg)
+		m.library = newLib.(*Model)
+		return m, cmd
+	case tea.WindowSizeMsg:
+		m.layoutDetail()
+		return m, tea.Batch(tea.ClearScreen, m.detailCoverCmd())
+	case enrichmentMsg:
+		return m.showEnrichment(msg)
+	case enrichAppliedMsg:
+		return m.enrichmentApplied(msg)
+	case coverChoicesMsg:
+		return m.showCoverChoices(msg)
+	case coverPickedMsg:
+		return m.coverPicked(msg)
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	if m.detailEnrichment != nil {
+		return m.updateEnrichment(keyMsg)
+	}
+	if m.coverPicking {
+		return m.updateCoverPicker(keyMsg)
+	}
+
+	if m.detailRating {
+		switch keyMsg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "esc":
+			m.detailRating = false
+			m.library.ratingInput.Blur()
+			m.library.ratingInput.Reset()
+			m.layoutDetail()
+			return m, nil
+		case "enter":
+			ratingText := strings.TrimSpace(m.library.ratingInput.Value())
+			if ratingText != "" {
+				rating, err := strconv.ParseFloat(ratingText, 64)
+				if err != nil || rating > 5.0 || rating < 0.0 {
+					m.library.ratingInput.Reset()
+					m.library.ratingInput.Placeholder = "Invalid!"
+					return m, nil
+				}
+				if err := m.library.handler.UpdateBookRating(rating, m.detailBook.BookFile); err != nil {
+					log.Printf("Error trying to update rating: %v", err)
+				} else {
+					m.detailBook.Rating = rating
+					m.detail.Book.Rating = rating
+					m.detailRatingChanged = true
+					m.reloadDetailHistory()
+				}
+			}
+			m.detailRating = false
+			m.library.ratingInput.Blur()
+			m.library.ratingInput.Reset()
+			m.library.ratingInput.Placeholder = "0.0-5.0"
+			m.layoutDetail()
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.library.ratingInput, cmd = m.library.ratingInput.Update(msg)
+		return m, cmd
+	}
+
+	if m.detailProgress {
+		switch keyMsg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "esc":
+			m.detailProgress = false
+			m.library.closeProgressInput()
+			m.layoutDetail()
+			return m, nil
+		case "enter":
+			if text := strings.TrimSpace(m.library.progressInput.Value()); text != "" {
+				percent, ok := parseProgress(text)
+				if !ok {
+					m.library.progressInput.Reset()
+					m.library.progressInput.Placeholder = "Invalid!"
+					return m, nil
+				}
+				m.setDetailProgress(percent)
+			}
+			m.detailProgress = false
+			m.library.closeProgressInput()
+			m.layoutDetail()
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.library.progressInput, cmd = m.library.progressInput.Update(msg)
+		return m, cmd
+	}
+
+	if m.detailNote {
+		switch keyMsg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "esc":
+			m.closeDetailNote()
+			return m, nil
+		case "enter":
+			if err := m.library.handler.AddSessionNote(m.detailBook.BookFile, m.detailNoteInput.Value()); err != nil {
+				log.Printf("Error trying to add a note: %v", err)
+				m.detailNotice = "Could not add the note: " + err.Error()
+			}
+			m.reloadDetailHistory()
+			m.closeDetailNote()
+			return m, nil
+		}
+		var cmd tea.Cmd
+		m.detailNoteInput, cmd = m.detailNoteInput.Update(msg)
+		return m, cmd
+	}
+
+	switch keyMsg.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	case "esc", "backspace":
+		return m.closeDetail()
+	case "r", "R":
+		m.setDetailStatus("Read")
+		return m, nil
+	case "u", "U":
+		m.setDetailStatus("Unread")
+		return m, nil
+	case "t", "T":
+		m.setDetailStatus("To Be Read")
+		return m, nil
+	case "e":
+		return m.openEditor()
+	case "o":
+		return m.startEnrichment()
+	case "c":
+		return m.startCoverPicker()
+	case "enter":
+		return m.openReader()
+	case "s":
+		m.detailRating = true
+		m.library.ratingInput.Reset()
+		m.layoutDetail()
+		return m, m.library.ratingInput.Focus()
+	case "p":
+		m.detailProgress = true
+		m.library.progressInput.Reset()
+		m.layoutDetail()
+		return m, m.library.progressInput.Focus()
+	case "n":
+		m.detailNote = true
+		m.detailNoteInput = textinput.New()
+		m.detailNoteInput.Placeholder = "added to the latest read"
+		m.detailNoteInput.CharLimit = 500
+		m.detailNoteInput.Width = max(m.detailInfoWidth()-len("Enter note: ")-2, 1)
+		m.layoutDetail()
+		return m, m.detailNoteInput.Focus()
+	}
+	var cmd tea.Cmd
+	m.detailView, cmd = m.detailView.Update(msg)
+	return m, cmd
+}
+
+func (m *MainModel) setDetailStatus(status string) {
+	status, readingDate, err := m.library.handler.UpdateBookStatus(status, m.detailBook.BookFile)
+	if err != nil {
+		log.Printf("Error trying to update status: %v", err)
+		return
+	}
+	for _, b := range []*metadata.Package{m.detailBook, m.detail.Book} {
+		b.Status = status
+		b.ReadingDate = readingDate
+	}
+	m.detailStatusChanged = true
+	m.reloadDetailHistory()
+	m.layoutDetail()
+}
+
+func (m *MainModel) closeDetailNote() {
+	m.detailNote = false
+	m.detailNoteInput.Blur()
+	m.layoutDetail()
+}
+
+// reloadDetailHistory reads the reading history again after a status, rating
+// or note change.
+func (m *MainModel) reloadDetailHistory() {
+	sessions, err := m.library.handler.Sessions(m.detailBook.BookFile)
+	if err != nil {
+		log.Printf("Error loading reading history: %v", err)
+		return
+	}
+	m.detail.Sessions = sessions
+}
+
+func (m *MainModel) setDetailProgress(percent float64) {
+	if m.library.setProgress(m.detailBook, percent) {
+		m.detailStatusChanged = true
+	}
+	m.detail.Book.Status = m.detailBook.Status
+	m.detail.Book.ReadingDate = m.detailBook.ReadingDate
+	m.detail.Book.Progress = percent
+	if detail, err := m.library.handler.BookDetail(m.detailBook.BookFile); err == nil {
+		m.detail.Progress = detail.Progress
+		m.detail.Sessions = detail.Sessions
+	}
+}
+
+func (m *MainModel) detailPanelSize() (int, int) {
+	panelWidth := m.library.width - m.sideBarWidth - 4
+	panelHeight := m.library.height + 2
+	if panelWidth < 24 {
+		panelWidth = 24
+	}
+	if panelHeight < 12 {
+		panelHeight = 12
+	}
+	return panelWidth, panelHeight
+}
+
+// detailCoverSize keeps the cover close to a 2:3 page given that terminal
+// cells are roughly twice as tall as they are wide.
+func (m *MainModel) detailCoverSize() (int, int) {
+	panelWidth, panelHeight := m.detailPanelSize()
+	rows := panelHeight - 2
+	cols := rows * 4 / 3
+	if maxCols := panelWidth * 2 / 5; cols > maxCols {
+		cols = maxCols
+		rows = cols * 3 / 4
+	}
+	if cols < 1 {
+		cols = 1
+	}
+	if rows < 1 {
+		rows = 1
+	}
+	return cols, rows
+}
+
+func (m *MainModel) detailInfoWidth() int {
+	panelWidth, _ := m.detailPanelSize()
+	coverCols, _ := m.detailCoverSize()
+	w := panelWidth - coverCols - 6
+	if w < 10 {
+		w = 10
+	}
+	return w
+}
+
+func (m *MainModel) layoutDetail() {
+	if m.detail == nil {
+		return
+	}
+	_, panelHeight := m.detailPanelSize()
+	width := m.detailInfoWidth()
+	infoLines := lipgloss.Height(m.detailInfoView(width))
+	height := panelHeight - infoLines - 4
+	if height < 3 {
+		height = 3
+	}
+	offset := m.detailView.YOffset
+	m.detailView.Width = width
+	m.detailView.Height = height
+	description := utils.StripHTML(m.detail.Book.Metadata.Description)
+	if description == "" {
+		description = lipgloss.NewStyle().Foreground(subtle).Render("No description")
+	}
+	m.detailView.SetContent(lipgloss.NewStyle().Width(width).Render(description))
+	m.detailView.SetYOffset(offset)
+}
+
+func (m *MainModel) detailCoverCmd() tea.Cmd {
+	if m.detail == nil {
+		return nil
+	}
+	cols, rows := m.detailCoverSize()
+	pixelWidth, pixelHeight := m.library.coverPixelSize(cols, rows)
+	protocol := termimg.DetectProtocol()
+	coverPath, file := m.detail.CoverPath, m.detail.Book.BookFile
+	cm, md, style := m.library.handler.CM, m.detail.Book.Metadata, placeholderStyle(coverPalette)
+	return func() tea.Msg {
+		if coverPath == "" {
+			coverPath = placeholderCover(cm, md.Title, md.Author, style)
+		}
+		return detailCoverMsg{file: file, data: renderCover(coverPath, cols, rows, pixelWidth, pixelHeight, protocol)}
+	}
+}
+
+func (m *MainModel) detailInfoView(width int) string {
+	d := m.detail
+	book := d.Book
+	label := lipgloss.NewStyle().Foreground(normal).Bold(true)
+	faint := lipgloss.NewStyle().Foreground(normal).Faint(true)
+	line := func(name, value string) string {
+		if strings.TrimSpace(value) == "" {
+			value = faint.Render("—")
+		}
+		return ansi.Truncate(label.Render(name+":")+" "+value, width, "...")
+	}
+
+	lines := []string{
+		lipgloss.NewStyle().Foreground(highlight).Bold(true).Width(width).Render(book.Metadata.Title),
+		"",
+	}
+	if len(d.Credits) == 0 {
+		lines = append(lines, line("Author", book.Metadata.Author))
+	}
+	for _, c := range d.Credits {
+		lines = append(lines, line(metadata.RoleLabel(c.Role), c.Name))
+	}
+	if book.Metadata.Series != "" {
+		lines = append(lines, line("Series", book.Metadata.SeriesLabel()))
+	}
+	readingDate := book.ReadingDate
+	if strings.TrimSpace(readingDate) == "" {
+		readingDate = "Not read yet"
+	}
+	lines = append(lines,
+		line("Status", book.Status),
+		line("Rating", strconv.FormatFloat(book.Rating, 'f', 1, 64)+" "+utils.GetStarRating(book.Rating)),
+		line("Reading date", readingDate),
+		line("Progress", progressSummary(d.Progress, width-10)),
+	)
+	if len(d.Sessions) == 0 {
+		lines = append(lines, line("History", ""))
+	}
+	for i, s := range d.Sessions {
+		lines = append(lines, line("Read #"+strconv.Itoa(i+1), sessionSummary(s)))
+	}
+	lines = append(lines,
+		line("Language", book.Metadata.Language),
+		line("Genres", strings.Join(book.Metadata.Genres, ", ")),
+	)
+	for _, id := range d.Identifiers {
+		lines = append(lines, line(id.Scheme, id.Value))
+	}
+	size := ""
+	if d.FileSize > 0 {
+		size = utils.HumanSize(d.FileSize)
+	}
+	cover := ""
+	if c := d.Cover; c.Path != "" {
+		cover = fmt.Sprintf("%dx%d %s (%s)", c.Width, c.Height, c.Format, c.Source)
+		if c.Pinned {
+			cover += ", pinned"
+		}
+	}
+	year, pages := "", ""
+	if d.PublishYear > 0 {
+		year = strconv.FormatInt(d.PublishYear, 10)
+	}
+	if d.PageCount > 0 {
+		pages = strconv.FormatInt(d.PageCount, 10)
+	}
+	lines = append(lines,
+		line("First published", year),
+		line("Pages", pages),
+	)
+	if len(d.ISBNs) > 0 {
+		lines = append(lines, line("ISBNs", strings.Join(d.ISBNs, ", ")))
+	}
+	lines = append(lines,
+		line("Cover", cover),
+		line("File size", size),
+		line("Path", d.FilePath),
+		line("Added", book.AddedAt),
+	)
+	if m.detailRating {
+		lines = append(lines, "", "Enter rating: "+m.library.ratingInput.View())
+	}
+	if m.detailProgress {
+		lines = append(lines, "", "Enter progress (%): "+m.library.progressInput.View())
+	}
+	if m.detailNote {
+		lines = append(lines, "", "Enter note: "+m.detailNoteInput.View())
+	}
+	switch {
+	case m.coverPicking:
+		lines = append(lines, "", m.coverPickerView(width))
+	case m.detailEnrichment != nil:
+		lines = append(lines, "", m.enrichmentView(width))
+	case m.detailNotice != "":
+		lines = append(lines, "", faint.Render(ansi.Truncate(m.detailNotice, width, "...")))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m *MainModel) DetailView() string {
+	sidebarView := m.SideBarView()
+	if m.detail == nil {
+		return sidebarView
+	}
+	panelWidth, panelHeight := m.detailPanelSize()
+	style := lipgloss.NewStyle().Border(lipgloss.RoundedBorder(), true, true, true, true).
+		BorderForeground(borders).
+		Width(panelWidth).
+		Height(panelHeight)
+
+	coverCols, coverRows := m.detailCoverSize()
+	coverBox := lipgloss.NewStyle().Width(coverCols).Height(coverRows).Render("")
+	if m.detailCover == "" {
+		coverBox = lipgloss.Place(coverCols, coverRows, lipgloss.Center, lipgloss.Center,
+			lipgloss.NewStyle().Foreground(subtle).Render("No cover"))
+	}
+
+	width := m.detailInfoWidth()
+	descriptionTitle := lipgloss.NewStyle().Foreground(normal).Bold(true).Render("Description")
+	if m.detailView.TotalLineCount() > m.detailView.Height {
+		descriptionTitle += lipgloss.NewStyle().Foreground(normal).Faint(true).
+			Render("  " + strconv.Itoa(int(m.detailView.ScrollPercent()*100)) + "%")
+	}
+	hint := lipgloss.NewStyle().Foreground(normal).Faint(true).
+		Render(ansi.Truncate("↑/↓ (j/k): scroll  enter: read  r/u/t: status  s: rate  p: progress  n: note  e: edit  o: look up  c: cover  esc: back", width, "..."))
+	info := lipgloss.JoinVertical(lipgloss.Left,
+		m.detailInfoView(width),
+		"",
+		descriptionTitle,
+		m.detailView.View(),
+		hint,
+	)
+
+	content := lipgloss.JoinHorizontal(lipgloss.Top, " ", coverBox, "   ", info)
+	rendered := lipgloss.JoinHorizontal(lipgloss.Left, sidebarView,
+		style.Render(truncateBlockHeight(content, panelHeight)))
+	if m.detailCover == "" {
+		return rendered
+	}
+	row := 2
+	col := lipgloss.Width(sidebarView) + 3
+	return rendered + "\x1b[" + strconv.Itoa(row) + ";" + strconv.Itoa(col) + "H" + m.detailCover
+}
+
+// sessionSummary is one line of the reading history: the dates of the read,
+// its rating and notes.
+func sessionSummary(s metadata.Session) string {
+	var dates string
+	switch {
+	case s.FinishedAt == "":
+		dates = "started " + s.StartedAt + ", reading"
+	case s.StartedAt == "":
+		dates = "finished " + s.FinishedAt
+	default:
+		dates = s.StartedAt + " → " + s.FinishedAt
+	}
+	parts := []string{dates}
+	if s.Rating > 0 {
+		parts = append(parts, utils.GetStarRating(s.Rating))
+	}
+	if s.Notes != "" {
+		parts = append(parts, strings.ReplaceAll(s.Notes, "\n", "; "))
+	}
+	return strings.Join(parts, " · ")
+}
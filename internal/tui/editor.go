@@ -0,0 +1,277 @@
+package tui
+
+import (
+	metadata "Kindria/internal/core/api/books"
+	"errors"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+const (
+	editTitle = iota
+	editAuthors
+	editGenres
+	editLanguage
+	editSeries
+	editSeriesIndex
+	editDescription
+	editWriteEPUB
+	editFieldCount
+)
+
+var editLabels = []string{"Title", "Authors", "Genres", "Language", "Series", "Series #", "Description", "EPUB file"}
+
+const editLabelWidth = 13
+
+type editSavedMsg struct {
+	detail *metadata.BookDetail
+	err    error
+}
+
+func (m *MainModel) openEditor() (tea.Model, tea.Cmd) {
+	md := m.detail.Book.Metadata
+	md.Credits = m.detail.Credits
+	edit := md.Edit()
+	index := ""
+	if edit.SeriesIndex > 0 {
+		index = metadata.FormatSeriesIndex(edit.SeriesIndex)
+	}
+	values := []string{
+		edit.Title,
+		strings.Join(edit.Authors, ", "),
+		strings.Join(edit.Genres, ", "),
+		edit.Language,
+		edit.Series,
+		index,
+	}
+	m.editInputs = make([]textinput.Model, len(values))
+	for i, v := range values {
+		ti := textinput.New()
+		ti.Prompt = ""
+		ti.CharLimit = 512
+		ti.SetValue(v)
+		m.editInputs[i] = ti
+	}
+	m.editInputs[editAuthors].Placeholder = "comma-separated"
+	m.editInputs[editGenres].Placeholder = "comma-separated"
+	m.editInputs[editSeriesIndex].Placeholder = "e.g. 2 or 2.5"
+
+	m.editDescription = textarea.New()
+	m.editDescription.Prompt = ""
+	m.editDescription.ShowLineNumbers = false
+	m.editDescription.CharLimit = 0
+	m.editDescription.SetValue(edit.Description)
+	m.editDescription.CursorStart()
+
+	m.editFocus = editTitle
+	m.editWriteEPUB = false
+	m.editSaving = false
+	m.editStatus = ""
+	m.state = editState
+	m.layoutEditor()
+	return m, tea.Batch(tea.ClearScreen, m.focusEditField())
+}
+
+func (m *MainModel) focusEditField() tea.Cmd {
+	for i := range m.editInputs {
+		m.editInputs[i].Blur()
+	}
+	m.editDescription.Blur()
+	switch {
+	case m.editFocus < len(m.editInputs):
+		return m.editInputs[m.editFocus].Focus()
+	case m.editFocus == editDescription:
+		return m.editDescription.Focus()
+	}
+	return nil
+}
+
+func (m *MainModel) moveEditFocus(delta int) tea.Cmd {
+	m.editFocus = (m.editFocus + delta + editFieldCount) % editFieldCount
+	return m.focusEditField()
+}
+
+func (m *MainModel) updateEditor(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case editSavedMsg:
+		m.editSaving = false
+		if msg.err != nil {
+			log.Printf("Error saving metadata: %v", msg.err)
+			m.editStatus = "Save failed: " + msg.err.Error()
+			return m, nil
+		}
+		m.detailBook.Metadata = msg.detail.Book.Metadata
+		m.detail = msg.detail
+		m.detailEdited = true
+		m.state = detailState
+		m.layoutDetail()
+		return m, tea.ClearScreen
+	case coverLoadedMsg, coversLoadedMsg:
+		newLib, cmd := m.library.Update(msg)
+		m.library = newLib.(*Model)
+		return m, cmd
+	case tea.WindowSizeMsg:
+		m.layoutEditor()
+		return m, tea.ClearScreen
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || m.editSaving {
+		return m, nil
+	}
+	switch keyMsg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		m.state = detailState
+		return m, tea.ClearScreen
+	case "ctrl+s":
+		return m.saveEditor()
+	case "tab":
+		return m, m.moveEditFocus(1)
+	case "shift+tab":
+		return m, m.moveEditFocus(-1)
+	case "down", "enter":
+		if m.editFocus != editDescription {
+			if m.editFocus == editWriteEPUB && keyMsg.String() == "enter" {
+				m.editWriteEPUB = !m.editWriteEPUB
+				return m, nil
+			}
+			return m, m.moveEditFocus(1)
+		}
+	case "up":
+		if m.editFocus != editDescription {
+			return m, m.moveEditFocus(-1)
+		}
+	case " ":
+		if m.editFocus == editWriteEPUB {
+			m.editWriteEPUB = !m.editWriteEPUB
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	switch {
+	case m.editFocus < len(m.editInputs):
+		m.editInputs[m.editFocus], cmd = m.editInputs[m.editFocus].Update(msg)
+	case m.editFocus == editDescription:
+		m.editDescription, cmd = m.editDescription.Update(msg)
+	}
+	return m, cmd
+}
+
+func (m *MainModel) editValues() (metadata.MetadataEdit, error) {
+	split := func(s string) []string {
+		var out []string
+		for _, v := range strings.Split(s, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				out = append(out, v)
+			}
+		}
+		return out
+	}
+	edit := metadata.MetadataEdit{
+		Title:       strings.TrimSpace(m.editInputs[editTitle].Value()),
+		Authors:     split(m.editInputs[editAuthors].Value()),
+		Genres:      split(m.editInputs[editGenres].Value()),
+		Language:    strings.TrimSpace(m.editInputs[editLanguage].Value()),
+		Description: m.editDescription.Value(),
+		Series:      strings.TrimSpace(m.editInputs[editSeries].Value()),
+	}
+	if edit.Title == "" {
+		return edit, errors.New("title cannot be empty")
+	}
+	if index := strings.TrimSpace(m.editInputs[editSeriesIndex].Value()); index != "" {
+		v, err := strconv.ParseFloat(index, 64)
+		if err != nil || v < 0 {
+			return edit, errors.New("invalid series number: " + index)
+		}
+		edit.SeriesIndex = v
+	}
+	return edit, nil
+}
+
+func (m *MainModel) saveEditor() (tea.Model, tea.Cmd) {
+	edit, err := m.editValues()
+	if err != nil {
+		m.editStatus = err.Error()
+		return m, nil
+	}
+	m.editSaving = true
+	m.editStatus = "Saving..."
+	h, file, writeEPUB := m.library.handler, m.detailBook.BookFile, m.editWriteEPUB
+	return m, func() tea.Msg {
+		if err := h.UpdateMetadata(file, edit, writeEPUB); err != nil {
+			return editSavedMsg{err: err}
+		}
+		detail, err := h.BookDetail(file)
+		return editSavedMsg{detail: detail, err: err}
+	}
+}
+
+func (m *MainModel) layoutEditor() {
+	panelWidth, panelHeight := m.detailPanelSize()
+	inputWidth := panelWidth - editLabelWidth - 6
+	if inputWidth < 10 {
+		inputWidth = 10
+	}
+	for i := range m.editInputs {
+		m.editInputs[i].Width = inputWidth
+	}
+	m.editDescription.SetWidth(inputWidth)
+	height := panelHeight - len(m.editInputs) - 6
+	if height < 3 {
+		height = 3
+	}
+	m.editDescription.SetHeight(height)
+}
+
+func (m *MainModel) EditorView() string {
+	sidebarView := m.SideBarView()
+	panelWidth, panelHeight := m.detailPanelSize()
+	style := lipgloss.NewStyle().Border(lipgloss.RoundedBorder(), true, true, true, true).
+		BorderForeground(borders).
+		Width(panelWidth).
+		Height(panelHeight)
+
+	label := func(field int) string {
+		s := lipgloss.NewStyle().Foreground(normal).Bold(true).Width(editLabelWidth)
+		if field == m.editFocus {
+			s = s.Foreground(highlight)
+		}
+		return s.Render(editLabels[field] + ":")
+	}
+
+	var s strings.Builder
+	s.WriteString("  Edit metadata\n")
+	hint := lipgloss.NewStyle().Foreground(normal).Faint(true).
+		Render(ansi.Truncate("tab/shift+tab: move  ctrl+s: save  esc: cancel", panelWidth-4, "..."))
+	s.WriteString("  " + hint + "\n\n")
+	for i := range m.editInputs {
+		s.WriteString("  " + label(i) + " " + m.editInputs[i].View() + "\n")
+	}
+	s.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, "  ", label(editDescription), " ", m.editDescription.View()) + "\n")
+
+	box := "[ ]"
+	if m.editWriteEPUB {
+		box = "[x]"
+	}
+	checkbox := box + " also write the changes into the .epub"
+	if m.editFocus == editWriteEPUB {
+		checkbox = lipgloss.NewStyle().Foreground(highlight).Render(checkbox)
+	}
+	s.WriteString("  " + label(editWriteEPUB) + " " + checkbox + "\n")
+	if m.editStatus != "" {
+		s.WriteString("\n  " + m.editStatus + "\n")
+	}
+
+	content := truncateBlockHeight(truncateViewLines(s.String(), panelWidth-2), panelHeight)
+	return lipgloss.JoinHorizontal(lipgloss.Left, sidebarView, style.Render(content))
+}
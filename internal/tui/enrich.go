@@ -0,0 +1,146 @@
+package tui
+
+import (
+	metadata "Kindria/internal/core/api/books"
+	"context"
+	"log"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+type enrichmentMsg struct {
+	file       string
+	enrichment *metadata.Enrichment
+	err        error
+}
+
+type enrichAppliedMsg struct {
+	file   string
+	detail *metadata.BookDetail
+	err    error
+}
+
+func (m *MainModel) startEnrichment() (tea.Model, tea.Cmd) {
+	if m.detailEnriching || m.coverLoading {
+		return m, nil
+	}
+	m.detailEnriching = true
+	m.detailNotice = "Looking the book up..."
+	m.layoutDetail()
+	h, file := m.library.handler, m.detailBook.BookFile
+	return m, func() tea.Msg {
+		e, err := h.EnrichBook(context.Background(), file)
+		return enrichmentMsg{file: file, enrichment: e, err: err}
+	}
+}
+
+func (m *MainModel) showEnrichment(msg enrichmentMsg) (tea.Model, tea.Cmd) {
+	if m.detail == nil || msg.file != m.detail.Book.BookFile {
+		return m, nil
+	}
+	m.detailEnriching = false
+	switch {
+	case msg.err != nil:
+		log.Printf("Error enriching %s: %v", msg.file, msg.err)
+		m.detailNotice = "Lookup failed: " + msg.err.Error()
+	case len(msg.enrichment.Changes) == 0:
+		m.detailNotice = "The metadata providers have nothing to add."
+		if err := m.library.handler.ApplyEnrichment(msg.enrichment); err != nil {
+			log.Printf("Error marking %s as enriched: %v", msg.file, err)
+		}
+	default:
+		m.detailNotice = ""
+		m.detailEnrichment = msg.enrichment
+		m.enrichCursor = 0
+	}
+	m.layoutDetail()
+	return m, nil
+}
+
+func (m *MainModel) updateEnrichment(keyMsg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	e := m.detailEnrichment
+	switch keyMsg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "up", "k":
+		if m.enrichCursor > 0 {
+			m.enrichCursor--
+		}
+	case "down", "j":
+		if m.enrichCursor < len(e.Changes)-1 {
+			m.enrichCursor++
+		}
+	case " ":
+		e.Changes[m.enrichCursor].Accept = !e.Changes[m.enrichCursor].Accept
+	case "enter":
+		return m.applyEnrichment(e)
+	case "esc":
+		for i := range e.Changes {
+			e.Changes[i].Accept = false
+		}
+		return m.applyEnrichment(e)
+	}
+	return m, nil
+}
+
+func (m *MainModel) applyEnrichment(e *metadata.Enrichment) (tea.Model, tea.Cmd) {
+	m.detailEnrichment = nil
+	m.detailNotice = "Saving..."
+	m.layoutDetail()
+	h := m.library.handler
+	return m, func() tea.Msg {
+		if err := h.ApplyEnrichment(e); err != nil {
+			return enrichAppliedMsg{file: e.FileName, err: err}
+		}
+		detail, err := h.BookDetail(e.FileName)
+		return enrichAppliedMsg{file: e.FileName, detail: detail, err: err}
+	}
+}
+
+func (m *MainModel) enrichmentApplied(msg enrichAppliedMsg) (tea.Model, tea.Cmd) {
+	if m.detail == nil || msg.file != m.detail.Book.BookFile {
+		return m, nil
+	}
+	if msg.err != nil {
+		log.Printf("Error saving enrichment: %v", msg.err)
+		m.detailNotice = "Save failed: " + msg.err.Error()
+		m.layoutDetail()
+		return m, nil
+	}
+	m.detailNotice = ""
+	m.detailBook.Metadata = msg.detail.Book.Metadata
+	msg.detail.CoverPath, msg.detail.Cover = m.detail.CoverPath, m.detail.Cover
+	m.detail = msg.detail
+	m.detailEdited = true
+	m.layoutDetail()
+	return m, nil
+}
+
+func (m *MainModel) enrichmentView(width int) string {
+	e := m.detailEnrichment
+	faint := lipgloss.NewStyle().Foreground(normal).Faint(true)
+	label := lipgloss.NewStyle().Foreground(normal).Bold(true)
+	lines := []string{label.Render("Found:")}
+	for i, c := range e.Changes {
+		box := "[ ] "
+		if c.Accept {
+			box = "[x] "
+		}
+		old := c.Old
+		if old == "" {
+			old = "—"
+		}
+		value := strings.Join(strings.Fields(c.New), " ")
+		row := box + c.Field + ": " + faint.Render(old) + " → " + value
+		row = ansi.Truncate(row, width, "...")
+		if i == m.enrichCursor {
+			row = lipgloss.NewStyle().Foreground(highlight).Render(ansi.Strip(row))
+		}
+		lines = append(lines, row)
+	}
+	lines = append(lines, faint.Render(ansi.Truncate("space: toggle  enter: apply  esc: reject", width, "...")))
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,196 @@
+package filter
+
+import (
+	metadata "Kindria/internal/core/api/books"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	SortTitle       = "Title"
+	SortAuthor      = "Author"
+	SortRating      = "Rating"
+	SortReadingDate = "Reading date"
+	SortAdded       = "Date added"
+	SortLanguage    = "Language"
+	SortSeries      = "Series"
+)
+
+var SortFields = []string{SortTitle, SortAuthor, SortRating, SortReadingDate, SortAdded, SortLanguage, SortSeries}
+
+var MinRatings = []float64{0, 1, 2, 3, 3.5, 4, 4.5, 5}
+
+type Settings struct {
+	SortBy    string  `json:"sort_by"`
+	SortDesc  bool    `json:"sort_desc"`
+	Status    string  `json:"status"`
+	Genre     string  `json:"genre"`
+	Language  string  `json:"language"`
+	MinRating float64 `json:"min_rating"`
+}
+
+func Default() Settings {
+	return Settings{SortBy: SortTitle}
+}
+
+func (s Settings) IsDefault() bool {
+	return s == Default()
+}
+
+// Match reports whether b passes the filters. Archived books only show up when
+// the status filter asks for them.
+func (s Settings) Match(b *metadata.Package) bool {
+	if b.Status == "Archived" && s.Status != "Archived" {
+		return false
+	}
+	if s.Status != "" && b.Status != s.Status {
+		return false
+	}
+	if s.Language != "" && !strings.EqualFold(b.Metadata.Language, s.Language) {
+		return false
+	}
+	if s.Genre != "" {
+		found := false
+		for _, g := range b.Metadata.Genres {
+			if strings.EqualFold(g, s.Genre) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return b.Rating >= s.MinRating
+}
+
+func (s Settings) Filter(books []*metadata.Package) []*metadata.Package {
+	out := make([]*metadata.Package, 0, len(books))
+	for _, b := range books {
+		if s.Match(b) {
+			out = append(out, b)
+		}
+	}
+	return out
+}
+
+// Apply returns the books matching every active filter, sorted by SortBy.
+// Books with an empty sort key always go last, whatever the direction.
+func (s Settings) Apply(books []*metadata.Package) []*metadata.Package {
+	out := s.Filter(books)
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		ka, kb := sortKey(a, s.SortBy), sortKey(b, s.SortBy)
+		if (ka == "") != (kb == "") {
+			return kb == ""
+		}
+		if ka == kb {
+			return strings.ToLower(a.Metadata.Title) < strings.ToLower(b.Metadata.Title)
+		}
+		if s.SortDesc {
+			return ka > kb
+		}
+		return ka < kb
+	})
+	return out
+}
+
+func sortKey(b *metadata.Package, field string) string {
+	switch field {
+	case SortAuthor:
+		return strings.ToLower(b.Metadata.Author)
+	case SortRating:
+		return fmt.Sprintf("%05.2f", b.Rating)
+	case SortReadingDate:
+		return b.ReadingDate
+	case SortAdded:
+		return fmt.Sprintf("%s|%020d", b.AddedAt, b.ID)
+	case SortLanguage:
+		return strings.ToLower(b.Metadata.Language)
+	case SortSeries:
+		if b.Metadata.Series == "" {
+			return ""
+		}
+		return fmt.Sprintf("%s|%012.3f", strings.ToLower(b.Metadata.Series), b.Metadata.SeriesIndex)
+	default:
+		return strings.ToLower(b.Metadata.Title)
+	}
+}
+
+func (s Settings) Summary() string {
+	parts := make([]string, 0, 5)
+	dir := "↑"
+	if s.SortDesc {
+		dir = "↓"
+	}
+	parts = append(parts, "sort: "+s.SortBy+" "+dir)
+	if s.Status != "" {
+		parts = append(parts, "status: "+s.Status)
+	}
+	if s.Genre != "" {
+		parts = append(parts, "genre: "+s.Genre)
+	}
+	if s.Language != "" {
+		parts = append(parts, "lang: "+s.Language)
+	}
+	if s.MinRating > 0 {
+		parts = append(parts, fmt.Sprintf("rating ≥ %.1f", s.MinRating))
+	}
+	return strings.Join(parts, "  ")
+}
+
+func Save(s Settings) error {
+	path, err := settingsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func Load() (Settings, error) {
+	path, err := settingsPath()
+	if err != nil {
+		return Default(), err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Default(), err
+	}
+	s := Default()
+	if err := json.Unmarshal(data, &s); err != nil {
+		return Default(), err
+	}
+	valid := false
+	for _, f := range SortFields {
+		if f == s.SortBy {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		s.SortBy = SortTitle
+	}
+	return s, nil
+}
+
+func settingsPath() (string, error) {
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, "kindria", "library_view.json"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "kindria", "library_view.json"), nil
+}
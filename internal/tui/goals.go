@@ -0,0 +1,52 @@
+package tui
+
+import (
+	metadata "Kindria/internal/core/api/books"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+type goalsLoadedMsg struct {
+	goals []metadata.Goal
+	err   error
+}
+
+// loadGoalsCmd reads this year's goals for the Home view; counting pages may
+// open EPUBs, so it runs in the background.
+func (m *MainModel) loadGoalsCmd() tea.Cmd {
+	h := m.library.handler
+	return func() tea.Msg {
+		goals, err := h.Goals(time.Now().Year())
+		return goalsLoadedMsg{goals: goals, err: err}
+	}
+}
+
+// goalsView shows each goal with a bar, the progress and the pace.
+func (m *MainModel) goalsView() string {
+	if len(m.goals) == 0 {
+		return ""
+	}
+	labelWidth := 0
+	for _, g := range m.goals {
+		labelWidth = max(labelWidth, min(lipgloss.Width(g.Label()), 28))
+	}
+	now := time.Now()
+	labelStyle := lipgloss.NewStyle().Foreground(normal).Width(labelWidth + 2)
+	faint := lipgloss.NewStyle().Foreground(normal).Faint(true)
+	rows := make([]string, 0, len(m.goals))
+	for _, g := range m.goals {
+		const width = 20
+		filled := min(int(float64(width)*float64(g.Progress)/float64(g.Target)), width)
+		bar := lipgloss.NewStyle().Foreground(highlight).Render(strings.Repeat("━", filled)) +
+			lipgloss.NewStyle().Foreground(subtle).Render(strings.Repeat("─", width-filled))
+		count := " " + strconv.FormatInt(g.Progress, 10) + "/" + strconv.FormatInt(g.Target, 10) + " " + g.Unit
+		rows = append(rows, "  "+labelStyle.Render(ansi.Truncate(g.Label(), labelWidth, "..."))+bar+
+			lipgloss.NewStyle().Foreground(normal).Render(count)+faint.Render(" · "+g.Pace(now)))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
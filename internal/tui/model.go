@@ -2,6 +2,7 @@ package tui
 
 import (
 	metadata "Kindria/internal/core/api/books"
+	"Kindria/internal/tui/filter"
 	uiTheme "Kindria/internal/tui/theme"
 	"Kindria/internal/utils"
 	kindle "Kindria/tools"
@@ -11,6 +12,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -18,7 +20,9 @@ import (
 	"github.com/blacktop/go-termimg"
 	"github.com/charmbracelet/bubbles/filepicker"
 	"github.com/charmbracelet/bubbles/paginator"
+	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/x/ansi"
@@ -35,6 +39,12 @@ const (
 	fileState
 	kindleState
 	themeState
+	authorsState
+	detailState
+	editState
+	readerState
+	statsState
+	shelvesState
 	sideFocus focusArea = iota
 	contentFocus
 )
@@ -50,35 +60,91 @@ var (
 )
 
 type MainModel struct {
-	state         sessionState
-	library       *Model
-	sideBarWidth  int
-	filePicker    filepicker.Model
-	selectedFiles map[string]struct{}
-	failedBooks   []string
-	selectedOrder []string
-	err           error
-	fileInput     textinput.Model
-	showFileInput bool
-	importing     bool
-	showLoader    bool
-	importStatus  string
-	kindleBooks   []string
-	kindleDocsURI string
-	kindleCursor  int
-	kindleSelect  bool
-	kindlePicked  map[string]struct{}
-	kindleSyncing bool
-	kindleLoader  bool
-	kindleStatus  string
-	themes        []uiTheme.Palette
-	currentTheme  uiTheme.Palette
-	themeCursor   int
+	state               sessionState
+	library             *Model
+	sideBarWidth        int
+	filePicker          filepicker.Model
+	selectedFiles       map[string]struct{}
+	failedBooks         []string
+	selectedOrder       []string
+	err                 error
+	fileInput           textinput.Model
+	showFileInput       bool
+	importing           bool
+	showLoader          bool
+	importStatus        string
+	importConflicts     []metadata.Conflict
+	conflictDir         string
+	kindleBooks         []string
+	kindleDocsURI       string
+	kindleCursor        int
+	kindleSelect        bool
+	kindlePicked        map[string]struct{}
+	kindleSyncing       bool
+	kindleLoader        bool
+	kindleStatus        string
+	themes              []uiTheme.Palette
+	currentTheme        uiTheme.Palette
+	themeCursor         int
+	authors             []metadata.AuthorSummary
+	authorCursor        int
+	authorBooks         []metadata.AuthorBook
+	authorsStatus       string
+	detail              *metadata.BookDetail
+	detailBook          *metadata.Package
+	detailView          viewport.Model
+	detailCover         string
+	detailRating        bool
+	detailProgress      bool
+	detailNote          bool
+	detailNoteInput     textinput.Model
+	detailStatusChanged bool
+	detailRatingChanged bool
+	detailEdited        bool
+	detailEnriching     bool
+	detailEnrichment    *metadata.Enrichment
+	detailNotice        string
+	enrichCursor        int
+	coverChoices        []metadata.CoverChoice
+	coverCursor         int
+	coverPicking        bool
+	coverLoading        bool
+	coverTyping         bool
+	coverPathInput      textinput.Model
+	editInputs          []textinput.Model
+	editDescription     textarea.Model
+	editFocus           int
+	editWriteEPUB       bool
+	editSaving          bool
+	editStatus          string
+	reader              *metadata.ReaderBook
+	readerChapter       int
+	readerBlocks        []metadata.Block
+	readerAnchors       map[string]int
+	readerView          viewport.Model
+	readerTOC           bool
+	readerTOCCursor     int
+	readerNotice        string
+	stats               *metadata.ReadingStats
+	statsYear           int
+	statsView           viewport.Model
+	statsLoading        bool
+	statsStatus         string
+	goals               []metadata.Goal
+	shelves             []metadata.Shelf
+	shelfCursor         int
+	shelfBooks          []metadata.ShelfBook
+	shelvesStatus       string
+	shelfInput          textinput.Model
+	shelfNaming         string
+	shelfPendingName    string
+	shelfConfirmDelete  bool
 }
 
 type Model struct {
 	books              []*metadata.Package
 	allBooks           []*metadata.Package
+	viewBooks          []*metadata.Package
 	currentView        string
 	cursor             int
 	sideBarCursor      int
@@ -104,6 +170,22 @@ type Model struct {
 	end                int
 	ratingInput        textinput.Model
 	showRatingInput    bool
+	progressInput      textinput.Model
+	showProgressInput  bool
+	searchInput        textinput.Model
+	showSearch         bool
+	filter             filter.Settings
+	showFilterBar      bool
+	filterCursor       int
+	scope              map[string]struct{}
+	confirmRemove      bool
+	notice             string
+	shelf              int64
+	shelfQuery         string
+	showShelfPicker    bool
+	shelfChoices       []metadata.Shelf
+	shelfPicked        map[int64]bool
+	shelfPickerCursor  int
 }
 
 type coversLoadedMsg map[int]string
@@ -116,10 +198,15 @@ type coverLoadedMsg struct {
 
 type importLoaderDelayMsg struct{}
 
+// coverArrivedMsg reports a cover downloaded by the background worker.
+type coverArrivedMsg metadata.CoverUpdate
+
 type importFinishedMsg struct {
 	successfulCopies []string
+	replacedBooks    []string
 	failedBooks      []string
 	duplicateCount   int
+	conflicts        []metadata.Conflict
 	refreshedBooks   []*metadata.Package
 	err              error
 }
@@ -134,8 +221,11 @@ type kindleSyncDelayMsg struct{}
 
 type kindleSyncFinishedMsg struct {
 	inserted      int
+	replaced      int
 	failed        int
 	duplicated    int
+	conflicts     []metadata.Conflict
+	conflictDir   string
 	refreshedBook []*metadata.Package
 	err           error
 }
@@ -154,6 +244,7 @@ func applyThemePalette(t uiTheme.Palette) {
 	subtle = lipgloss.AdaptiveColor{Light: t.SubtleLight, Dark: t.SubtleDark}
 	borders = lipgloss.AdaptiveColor{Light: t.BorderLight, Dark: t.BorderDark}
 	highlight = lipgloss.AdaptiveColor{Light: t.HighlightLight, Dark: t.HighlightDark}
+	coverPalette = t
 	list = lipgloss.NewStyle().
 		Border(lipgloss.NormalBorder(), true, true, true, true).
 		BorderForeground(subtle)
@@ -203,11 +294,22 @@ func InitialModel(b []*metadata.Package, h *metadata.Handler) *MainModel {
 	t.CharLimit = 10
 	t.Width = 10
 
+	si := textinput.New()
+	si.Prompt = "/ "
+	si.Placeholder = "title, author, genre, description"
+	si.CharLimit = 80
+	si.Width = 40
+
 	f := textinput.New()
 	f.Placeholder = "Introduce .epub' folder path"
 	f.CharLimit = 60
 	f.Width = 60
 
+	librarySettings, err := filter.Load()
+	if err != nil && !os.IsNotExist(err) {
+		log.Printf("Error loading library filters: %v", err)
+	}
+
 	fp := filepicker.New()
 	fp.AllowedTypes = []string{".epub"}
 	fp.CurrentDirectory = "/home/yeray/Downloads/"
@@ -215,6 +317,10 @@ func InitialModel(b []*metadata.Package, h *metadata.Handler) *MainModel {
 	fp.ShowPermissions = false
 	fp.ShowSize = false
 	applyFilePickerTheme(&fp)
+	shelves, err := h.ListShelves()
+	if err != nil {
+		log.Printf("Error loading shelves: %v", err)
+	}
 	library := &Model{
 		books:              b,
 		paginator:          p,
@@ -223,10 +329,14 @@ func InitialModel(b []*metadata.Package, h *metadata.Handler) *MainModel {
 		coverRenderPending: make(map[string]struct{}),
 		handler:            *h,
 		activeArea:         int(sideFocus),
-		MenuOptions:        []string{"Home", "Books", "To-Be Read", "Add Book", "Synchronize \nKindle", "Themes"},
+		MenuOptions:        menuOptions(shelves),
 		showRatingInput:    false,
 		ratingInput:        t,
+		progressInput:      newProgressInput(),
+		searchInput:        si,
+		filter:             librarySettings,
 		allBooks:           b,
+		viewBooks:          librarySettings.Apply(b),
 	}
 	return &MainModel{
 		state:         homeState,
@@ -242,18 +352,32 @@ func InitialModel(b []*metadata.Package, h *metadata.Handler) *MainModel {
 		themes:        themes,
 		currentTheme:  currentTheme,
 		themeCursor:   themeCursor,
+		shelves:       shelves,
+		shelfInput:    newShelfInput(),
 	}
 }
 
 func (m *MainModel) Init() tea.Cmd {
-	return m.filePicker.Init()
+	return tea.Batch(m.filePicker.Init(), waitForCover(m.library.handler.CM), m.loadGoalsCmd())
+}
+
+func waitForCover(cm *metadata.CoverManager) tea.Cmd {
+	if cm == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		return coverArrivedMsg(<-cm.Updates())
+	}
 }
 
 func (m *MainModel) View() string {
 	fig := utils.FigWithGradient(m.currentTheme.HighlightDark, m.currentTheme.BorderDark)
 	if m.state == homeState {
-		return lipgloss.Place(m.library.width, m.library.height, lipgloss.Center, lipgloss.Center,
-			fig+"\n"+m.HomeMenuView())
+		home := fig + "\n" + m.HomeMenuView()
+		if goals := m.goalsView(); goals != "" {
+			home += "\n\n" + goals
+		}
+		return lipgloss.Place(m.library.width, m.library.height, lipgloss.Center, lipgloss.Center, home)
 	}
 	if m.state == fileState {
 		return m.FilePickerView()
@@ -264,6 +388,24 @@ func (m *MainModel) View() string {
 	if m.state == themeState {
 		return m.ThemeView()
 	}
+	if m.state == authorsState {
+		return m.AuthorsView()
+	}
+	if m.state == detailState {
+		return m.DetailView()
+	}
+	if m.state == editState {
+		return m.EditorView()
+	}
+	if m.state == readerState {
+		return m.ReaderView()
+	}
+	if m.state == statsState {
+		return m.StatsView()
+	}
+	if m.state == shelvesState {
+		return m.ShelvesView()
+	}
 
 	return lipgloss.JoinHorizontal(lipgloss.Left, m.SideBarView(), m.library.View())
 }
@@ -276,6 +418,11 @@ func (m *MainModel) HomeMenuView() string {
 	items := []homeItem{
 		{label: "󱉟 Library", key: "l/L"},
 		{label: "󱉟 To-Be Read", key: "t/T"},
+		{label: "󱉟 Currently Reading", key: "r/R"},
+		{label: "󱉟 Series", key: "s/S"},
+		{label: "󱉟 Authors", key: "w/W"},
+		{label: "󱉟 Shelves", key: "b/B"},
+		{label: "󱉟 Stats", key: "i/I"},
 		{label: "󱉟 Add Book", key: "a/A"},
 		{label: "󱉟 Synchronize Kindle", key: "k/K"},
 		{label: "󱉟 Themes", key: "c/C"},
@@ -305,6 +452,27 @@ func (m *MainModel) HomeMenuView() string {
 
 func (m *MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
+	case coverArrivedMsg:
+		cmds := []tea.Cmd{waitForCover(m.library.handler.CM)}
+		// Only these states pass coverLoadedMsg on to the grid.
+		if m.state == librayState || m.state == detailState || m.state == editState || m.state == readerState {
+			cmds = append(cmds, m.library.syncVisibleWidget())
+		}
+		if m.detail != nil && m.detail.Book.ID == msg.BookID {
+			if detail, err := m.library.handler.BookDetail(m.detail.Book.BookFile); err == nil {
+				m.detail.CoverPath = detail.CoverPath
+				m.detail.Cover = detail.Cover
+				cmds = append(cmds, m.detailCoverCmd())
+			}
+		}
+		return m, tea.Batch(cmds...)
+	case goalsLoadedMsg:
+		if msg.err != nil {
+			log.Printf("Error loading reading goals: %v", msg.err)
+			return m, nil
+		}
+		m.goals = msg.goals
+		return m, nil
 	case importLoaderDelayMsg:
 		if m.importing {
 			m.showLoader = true
@@ -315,32 +483,26 @@ func (m *MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.showLoader = false
 		if msg.err != nil {
 			m.importStatus = "Insert failed: " + msg.err.Error()
+			m.removeConflictDir()
 			return m, nil
 		}
-		if len(msg.successfulCopies) > 0 {
-			for _, book := range msg.successfulCopies {
-				delete(m.selectedFiles, book)
-				index := -1
-				for i, val := range m.selectedOrder {
-					if val == book {
-						index = i
-						break
-					}
-				}
-				if index != -1 {
-					m.selectedOrder = utils.Delete_at_index(m.selectedOrder, index)
-				}
-			}
+		for _, book := range msg.successfulCopies {
+			m.deselectFile(book)
+		}
+		for _, book := range msg.replacedBooks {
+			m.deselectFile(book)
 		}
+		m.importConflicts = append(m.importConflicts, msg.conflicts...)
 		if len(msg.failedBooks) > 0 {
 			m.failedBooks = append(m.failedBooks, msg.failedBooks...)
 		}
-		if len(msg.refreshedBooks) > 0 {
-			m.library.allBooks = msg.refreshedBooks
-			m.library.books = msg.refreshedBooks
+		m.importStatus = fmt.Sprintf("Inserted: %d | Replaced: %d | Failed: %d | Duplicated: %d", len(msg.successfulCopies), len(msg.replacedBooks), len(msg.failedBooks), msg.duplicateCount)
+		m.removeConflictDir()
+		if len(msg.refreshedBooks) == 0 {
+			return m, nil
 		}
-		m.importStatus = fmt.Sprintf("Inserted: %d | Failed: %d | Duplicated: %d", len(msg.successfulCopies), len(msg.failedBooks), msg.duplicateCount)
-		return m, nil
+		m.library.allBooks = msg.refreshedBooks
+		return m, m.library.SetView(m.library.currentView)
 	case kindleBooksLoadedMsg:
 		if msg.err != nil {
 			m.kindleStatus = "Kindle error: " + msg.err.Error()
@@ -360,16 +522,21 @@ func (m *MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case kindleSyncFinishedMsg:
 		m.kindleSyncing = false
 		m.kindleLoader = false
+		if msg.conflictDir != "" {
+			m.importConflicts = append(m.importConflicts, msg.conflicts...)
+			m.conflictDir = msg.conflictDir
+			m.importStatus = ""
+		}
 		if msg.err != nil {
 			m.kindleStatus = "Sync failed: " + msg.err.Error()
 			return m, nil
 		}
-		if len(msg.refreshedBook) > 0 {
-			m.library.allBooks = msg.refreshedBook
-			m.library.books = msg.refreshedBook
+		m.kindleStatus = fmt.Sprintf("Inserted: %d | Replaced: %d | Failed: %d | Duplicated: %d", msg.inserted, msg.replaced, msg.failed, msg.duplicated)
+		if len(msg.refreshedBook) == 0 {
+			return m, nil
 		}
-		m.kindleStatus = fmt.Sprintf("Inserted: %d | Failed: %d | Duplicated: %d", msg.inserted, msg.failed, msg.duplicated)
-		return m, nil
+		m.library.allBooks = msg.refreshedBook
+		return m, m.library.SetView(m.library.currentView)
 	}
 
 	if msg, ok := msg.(tea.WindowSizeMsg); ok {
@@ -422,6 +589,12 @@ func (m *MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		pickerHeight, _ := m.filePickerLayout(panelHeight)
 		m.filePicker.SetHeight(pickerHeight)
 
+		if !m.showFileInput {
+			if cmd, ok := m.resolveConflict(msg); ok {
+				return m, cmd
+			}
+		}
+
 		if !m.showFileInput && len(m.selectedFiles) > 0 {
 			switch msg := msg.(type) {
 			case tea.KeyMsg:
@@ -434,11 +607,13 @@ func (m *MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					for book := range m.selectedFiles {
 						selected = append(selected, book)
 					}
+					m.importConflicts = nil
+					m.removeConflictDir()
 					m.importing = true
 					m.showLoader = false
 					m.importStatus = ""
 					return m, tea.Batch(
-						m.importBooksCmd(selected),
+						m.importBooksCmd(selected, metadata.DuplicateAsk),
 						tea.Tick(250*time.Millisecond, func(time.Time) tea.Msg {
 							return importLoaderDelayMsg{}
 						}),
@@ -487,28 +662,7 @@ func (m *MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.library.activeArea = int(contentFocus)
 					return m, nil
 				case "enter":
-					selectedOption := m.library.MenuOptions[m.library.sideBarCursor]
-					switch selectedOption {
-					case "Home":
-						m.state = homeState
-						return m, tea.ClearScreen
-					case "Books", "To-Be Read":
-						m.state = librayState
-						m.library.activeArea = int(contentFocus)
-						return m, m.library.SetView(selectedOption)
-					case "Add Book":
-						m.state = fileState
-						m.library.activeArea = int(contentFocus)
-						return m, tea.Batch(tea.ClearScreen, m.filePicker.Init())
-					case "Synchronize Kindle", "Synchronize \nKindle":
-						m.state = kindleState
-						m.library.activeArea = int(contentFocus)
-						return m, tea.Batch(tea.ClearScreen, m.loadKindleBooksCmd())
-					case "Themes":
-						m.state = themeState
-						m.library.activeArea = int(contentFocus)
-						return m, tea.ClearScreen
-					}
+					return m.openMenuOption(m.library.MenuOptions[m.library.sideBarCursor])
 				}
 			}
 			newLib, cmd := m.library.Update(msg)
@@ -559,28 +713,7 @@ func (m *MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.library.activeArea = int(contentFocus)
 					return m, nil
 				case "enter":
-					selectedOption := m.library.MenuOptions[m.library.sideBarCursor]
-					switch selectedOption {
-					case "Home":
-						m.state = homeState
-						return m, tea.ClearScreen
-					case "Books", "To-Be Read":
-						m.state = librayState
-						m.library.activeArea = int(contentFocus)
-						return m, m.library.SetView(selectedOption)
-					case "Add Book":
-						m.state = fileState
-						m.library.activeArea = int(contentFocus)
-						return m, tea.Batch(tea.ClearScreen, m.filePicker.Init())
-					case "Synchronize Kindle", "Synchronize \nKindle":
-						m.state = kindleState
-						m.library.activeArea = int(contentFocus)
-						return m, tea.Batch(tea.ClearScreen, m.loadKindleBooksCmd())
-					case "Themes":
-						m.state = themeState
-						m.library.activeArea = int(contentFocus)
-						return m, tea.ClearScreen
-					}
+					return m.openMenuOption(m.library.MenuOptions[m.library.sideBarCursor])
 				}
 			}
 			newLib, cmd := m.library.Update(msg)
@@ -588,6 +721,9 @@ func (m *MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, cmd
 		}
 
+		if cmd, ok := m.resolveConflict(msg); ok {
+			return m, cmd
+		}
 		if keyMsg, ok := msg.(tea.KeyMsg); ok {
 			switch keyMsg.String() {
 			case "q", "ctrl+c":
@@ -658,28 +794,7 @@ func (m *MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.library.activeArea = int(contentFocus)
 					return m, nil
 				case "enter":
-					selectedOption := m.library.MenuOptions[m.library.sideBarCursor]
-					switch selectedOption {
-					case "Home":
-						m.state = homeState
-						return m, tea.ClearScreen
-					case "Books", "To-Be Read":
-						m.state = librayState
-						m.library.activeArea = int(contentFocus)
-						return m, m.library.SetView(selectedOption)
-					case "Add Book":
-						m.state = fileState
-						m.library.activeArea = int(contentFocus)
-						return m, tea.Batch(tea.ClearScreen, m.filePicker.Init())
-					case "Synchronize Kindle", "Synchronize \nKindle":
-						m.state = kindleState
-						m.library.activeArea = int(contentFocus)
-						return m, tea.Batch(tea.ClearScreen, m.loadKindleBooksCmd())
-					case "Themes":
-						m.state = themeState
-						m.library.activeArea = int(contentFocus)
-						return m, tea.ClearScreen
-					}
+					return m.openMenuOption(m.library.MenuOptions[m.library.sideBarCursor])
 				}
 			}
 			newLib, cmd := m.library.Update(msg)
@@ -720,6 +835,38 @@ func (m *MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
+	if m.state == authorsState {
+		return m.updateAuthors(msg)
+	}
+
+	if m.state == detailState {
+		return m.updateDetail(msg)
+	}
+
+	if m.state == editState {
+		return m.updateEditor(msg)
+	}
+
+	if m.state == readerState {
+		return m.updateReader(msg)
+	}
+
+	if m.state == statsState {
+		return m.updateStats(msg)
+	}
+
+	if m.state == shelvesState {
+		return m.updateShelves(msg)
+	}
+
+	if m.state == librayState && m.library.capturingInput() {
+		if _, ok := msg.(tea.KeyMsg); ok {
+			newLib, cmd := m.library.Update(msg)
+			m.library = newLib.(*Model)
+			return m, cmd
+		}
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		switch msg.String() {
@@ -727,38 +874,43 @@ func (m *MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, tea.Quit
 		case "l", "L":
 			if m.state == homeState {
-				m.state = librayState
-				m.library.sideBarCursor = 1
-				m.library.activeArea = int(contentFocus)
-				return m, m.library.SetView("Books")
+				return m.openMenuOption("Books")
 			}
 		case "t", "T":
 			if m.state == homeState {
-				m.state = librayState
-				m.library.sideBarCursor = 2
-				m.library.activeArea = int(contentFocus)
-				return m, m.library.SetView("To-Be Read")
+				return m.openMenuOption("To-Be Read")
+			}
+		case "r", "R":
+			if m.state == homeState {
+				return m.openMenuOption("Currently Reading")
+			}
+		case "s", "S":
+			if m.state == homeState {
+				return m.openMenuOption("Series")
+			}
+		case "w", "W":
+			if m.state == homeState {
+				return m.openMenuOption("Authors")
+			}
+		case "b", "B":
+			if m.state == homeState {
+				return m.openMenuOption("Shelves")
+			}
+		case "i", "I":
+			if m.state == homeState {
+				return m.openMenuOption("Stats")
 			}
 		case "a", "A":
 			if m.state == homeState {
-				m.state = fileState
-				m.library.sideBarCursor = 3
-				m.library.activeArea = int(contentFocus)
-				return m, m.filePicker.Init()
+				return m.openMenuOption("Add Book")
 			}
 		case "k", "K":
 			if m.state == homeState {
-				m.state = kindleState
-				m.library.sideBarCursor = 4
-				m.library.activeArea = int(contentFocus)
-				return m, tea.Batch(tea.ClearScreen, m.loadKindleBooksCmd())
+				return m.openMenuOption("Synchronize \nKindle")
 			}
 		case "c", "C":
 			if m.state == homeState {
-				m.state = themeState
-				m.library.sideBarCursor = 5
-				m.library.activeArea = int(contentFocus)
-				return m, tea.ClearScreen
+				return m.openMenuOption("Themes")
 			}
 		case "ctrl+h", "esc":
 			if m.library.activeArea == int(contentFocus) {
@@ -770,28 +922,10 @@ func (m *MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		case "enter":
 			if m.library.activeArea == int(sideFocus) {
-				selectedOption := m.library.MenuOptions[m.library.sideBarCursor]
-				switch selectedOption {
-				case "Home":
-					m.state = homeState
-					return m, tea.ClearScreen
-				case "Books", "To-Be Read":
-					m.state = librayState
-					m.library.activeArea = int(contentFocus)
-					return m, m.library.SetView(selectedOption)
-				case "Add Book":
-					m.state = fileState
-					m.library.activeArea = int(contentFocus)
-					return m, tea.Batch(tea.ClearScreen, m.filePicker.Init())
-				case "Synchronize Kindle", "Synchronize \nKindle":
-					m.state = kindleState
-					m.library.activeArea = int(contentFocus)
-					return m, tea.Batch(tea.ClearScreen, m.loadKindleBooksCmd())
-				case "Themes":
-					m.state = themeState
-					m.library.activeArea = int(contentFocus)
-					return m, tea.ClearScreen
-				}
+				return m.openMenuOption(m.library.MenuOptions[m.library.sideBarCursor])
+			}
+			if m.state == librayState && m.library.cursor < len(m.library.books) {
+				return m.openDetail(m.library.books[m.library.cursor])
 			}
 		}
 	}
@@ -805,6 +939,44 @@ func (m *MainModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+func (m *MainModel) openMenuOption(option string) (tea.Model, tea.Cmd) {
+	m.library.sideBarCursor = indexOf(m.library.MenuOptions, option)
+	switch option {
+	case "Home":
+		m.state = homeState
+		return m, tea.Batch(tea.ClearScreen, m.loadGoalsCmd())
+	case "Books", "To-Be Read", "Currently Reading", "Series":
+		m.state = librayState
+		m.library.activeArea = int(contentFocus)
+		return m, m.library.SetView(option)
+	case "Authors":
+		m.state = authorsState
+		m.library.activeArea = int(contentFocus)
+		m.loadAuthors()
+		return m, tea.ClearScreen
+	case "Shelves":
+		return m.openShelves()
+	case "Stats":
+		return m.openStats()
+	case "Add Book":
+		m.state = fileState
+		m.library.activeArea = int(contentFocus)
+		return m, tea.Batch(tea.ClearScreen, m.filePicker.Init())
+	case "Synchronize Kindle", "Synchronize \nKindle":
+		m.state = kindleState
+		m.library.activeArea = int(contentFocus)
+		return m, tea.Batch(tea.ClearScreen, m.loadKindleBooksCmd())
+	case "Themes":
+		m.state = themeState
+		m.library.activeArea = int(contentFocus)
+		return m, tea.ClearScreen
+	}
+	if s, ok := m.shelfOption(option); ok {
+		return m.openShelf(s)
+	}
+	return m, nil
+}
+
 /* ----- Library ----- */
 func (m *Model) Init() tea.Cmd {
 	return nil
@@ -845,6 +1017,9 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.ratingInput.Blur()
 				m.ratingInput.Reset()
 				m.ratingInput.Placeholder = "0.0-5.0"
+				if m.filter.MinRating > 0 || m.filter.SortBy == filter.SortRating || m.shelfQuery != "" {
+					return m, m.SetView(m.currentView)
+				}
 				return m, tea.ClearScreen
 			}
 		}
@@ -852,6 +1027,87 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.ratingInput, cmdRating = m.ratingInput.Update(msg)
 		return m, cmdRating
 	}
+	if m.showProgressInput {
+		return m.updateProgressInput(msg)
+	}
+	if m.showShelfPicker {
+		return m.updateShelfPicker(msg)
+	}
+	if m.confirmRemove {
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			switch keyMsg.String() {
+			case "ctrl+c":
+				return m, tea.Quit
+			case "d", "y":
+				m.confirmRemove = false
+				return m, m.removeCurrent(false)
+			case "m":
+				m.confirmRemove = false
+				return m, m.removeCurrent(true)
+			case "esc", "n":
+				m.confirmRemove = false
+				return m, tea.ClearScreen
+			}
+		}
+		return m, nil
+	}
+	if m.showFilterBar {
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			switch keyMsg.String() {
+			case "ctrl+c":
+				return m, tea.Quit
+			case "esc", "enter", "f":
+				m.showFilterBar = false
+				return m, nil
+			case "left", "h":
+				if m.filterCursor > 0 {
+					m.filterCursor--
+				}
+				return m, nil
+			case "right", "l", "tab":
+				if m.filterCursor < len(filterBarFields)-1 {
+					m.filterCursor++
+				}
+				return m, nil
+			case "down", "j", " ":
+				return m, m.cycleFilter(1)
+			case "up", "k":
+				return m, m.cycleFilter(-1)
+			case "x":
+				m.filter = filter.Default()
+				m.saveFilter()
+				return m, m.SetView(m.currentView)
+			}
+		}
+		return m, nil
+	}
+	if m.showSearch {
+		switch msg := msg.(type) {
+		case tea.KeyMsg:
+			switch msg.String() {
+			case "ctrl+c":
+				return m, tea.Quit
+			case "esc":
+				m.showSearch = false
+				m.searchInput.Blur()
+				m.searchInput.Reset()
+				m.applySearch()
+				return m, m.resetGrid()
+			case "enter":
+				m.showSearch = false
+				m.searchInput.Blur()
+				return m, nil
+			}
+		}
+		prevQuery := m.searchInput.Value()
+		var cmdSearch tea.Cmd
+		m.searchInput, cmdSearch = m.searchInput.Update(msg)
+		if m.searchInput.Value() != prevQuery {
+			m.applySearch()
+			return m, tea.Batch(cmdSearch, m.resetGrid())
+		}
+		return m, cmdSearch
+	}
 
 	switch msg := msg.(type) {
 
@@ -859,6 +1115,7 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, tea.ClearScreen)
 
 	case tea.KeyMsg:
+		m.notice = ""
 		switch msg.String() {
 		case "ctrl+c", "q":
 			return m, tea.Quit
@@ -931,37 +1188,105 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				cmds = append(cmds, tea.ClearScreen)
 			}
 		case "r", "R":
-			readingDate, err := m.handler.UpdateBookStatus("Read", m.books[m.cursor].BookFile)
+			if m.cursor >= len(m.books) {
+				break
+			}
+			status, readingDate, err := m.handler.UpdateBookStatus("Read", m.books[m.cursor].BookFile)
 			if err != nil {
 				log.Printf("Error trying to update status: %v", err)
+				break
 			}
-			m.books[m.cursor].Status = "Read"
+			m.books[m.cursor].Status = status
 			m.books[m.cursor].ReadingDate = readingDate
-			if m.currentView == "To-Be Read" {
-				return m, m.SetView("To-Be Read")
+			if m.viewDependsOnStatus() {
+				return m, m.SetView(m.currentView)
 			}
 		case "u", "U":
-			readingDate, err := m.handler.UpdateBookStatus("Unread", m.books[m.cursor].BookFile)
+			if m.cursor >= len(m.books) {
+				break
+			}
+			status, readingDate, err := m.handler.UpdateBookStatus("Unread", m.books[m.cursor].BookFile)
 			if err != nil {
 				log.Printf("Error trying to update status: %v", err)
+				break
+			}
+			if status != "Unread" {
+				m.notice = m.books[m.cursor].Metadata.Title + " has a reading history, so it stays " + status
 			}
-			m.books[m.cursor].Status = "Unread"
+			m.books[m.cursor].Status = status
 			m.books[m.cursor].ReadingDate = readingDate
-			if m.currentView == "To-Be Read" {
-				return m, m.SetView("To-Be Read")
+			if m.viewDependsOnStatus() {
+				return m, m.SetView(m.currentView)
 			}
 		case "t", "T":
-			readingDate, err := m.handler.UpdateBookStatus("To Be Read", m.books[m.cursor].BookFile)
+			if m.cursor >= len(m.books) {
+				break
+			}
+			status, readingDate, err := m.handler.UpdateBookStatus("To Be Read", m.books[m.cursor].BookFile)
 			if err != nil {
 				log.Printf("Error trying to update status: %v", err)
+				break
 			}
-			m.books[m.cursor].Status = "To Be Read"
+			m.books[m.cursor].Status = status
 			m.books[m.cursor].ReadingDate = readingDate
+			if m.viewDependsOnStatus() {
+				return m, m.SetView(m.currentView)
+			}
+		case "a":
+			if m.activeArea != int(contentFocus) || m.cursor >= len(m.books) {
+				break
+			}
+			b := m.books[m.cursor]
+			next := m.neighbour()
+			status, err := m.handler.ArchiveBook(b.BookFile, b.Status != "Archived")
+			if err != nil {
+				log.Printf("Error trying to archive book: %v", err)
+				m.notice = "Could not archive " + b.Metadata.Title + ": " + err.Error()
+				break
+			}
+			b.Status = status
+			if status == "Archived" {
+				m.notice = "Archived " + b.Metadata.Title + " (status filter \"Archived\" shows it again)"
+			} else {
+				m.notice = "Restored " + b.Metadata.Title + " as " + status
+			}
+			return m, tea.Batch(m.SetView(m.currentView), m.focusBook(next))
+		case "d", "delete":
+			if m.activeArea != int(contentFocus) || m.cursor >= len(m.books) {
+				break
+			}
+			m.confirmRemove = true
+			return m, tea.ClearScreen
 		case "s":
+			if m.cursor >= len(m.books) {
+				break
+			}
 			m.showRatingInput = true
 			m.ratingInput.Reset()
 			m.ratingInput.Focus()
 			return m, tea.ClearScreen
+		case "p":
+			if m.cursor >= len(m.books) {
+				break
+			}
+			m.showProgressInput = true
+			m.progressInput.Reset()
+			m.progressInput.Focus()
+			return m, tea.ClearScreen
+		case "b":
+			if m.activeArea == int(contentFocus) && m.cursor < len(m.books) {
+				return m, m.openShelfPicker()
+			}
+		case "/":
+			if m.activeArea == int(contentFocus) {
+				m.showSearch = true
+				return m, m.searchInput.Focus()
+			}
+		case "f":
+			if m.activeArea == int(contentFocus) {
+				m.showFilterBar = true
+				return m, nil
+			}
 		}
 	case coversLoadedMsg:
 		m.covers = msg
@@ -1010,6 +1335,17 @@ func (m Model) View() string {
 			}
 		}
 
+		if m.confirmRemove && absoluteIndex == m.cursor {
+			popupBox := lipgloss.NewStyle().
+				Border(lipgloss.RoundedBorder()).
+				BorderForeground(highlight).
+				Padding(0, 1).
+				Render("Remove book?\nd: delete file\nm: move to trash\nesc: cancel")
+			cardContent := lipgloss.Place(m.dynamicCardWidth, m.dynamicCardHeight, lipgloss.Center, lipgloss.Center, popupBox)
+			booksCards = append(booksCards, style.Render(cardContent))
+			continue
+		}
+
 		if m.showRatingInput && absoluteIndex == m.cursor {
 			popupContext := "Enter rating:\n" + m.ratingInput.View()
 			popupBox := lipgloss.NewStyle().
@@ -1022,7 +1358,28 @@ func (m Model) View() string {
 			continue
 		}
 
-		booksCards = append(booksCards, style.Render(""))
+		if m.showShelfPicker && absoluteIndex == m.cursor {
+			cardContent := lipgloss.Place(m.dynamicCardWidth, m.dynamicCardHeight, lipgloss.Center, lipgloss.Center, m.shelfPickerView())
+			booksCards = append(booksCards, style.Render(cardContent))
+			continue
+		}
+
+		if m.showProgressInput && absoluteIndex == m.cursor {
+			popupBox := lipgloss.NewStyle().
+				Border(lipgloss.RoundedBorder()).
+				BorderForeground(highlight).
+				Padding(0, 1).
+				Render("Progress (%):\n" + m.progressInput.View())
+			cardContent := lipgloss.Place(m.dynamicCardWidth, m.dynamicCardHeight, lipgloss.Center, lipgloss.Center, popupBox)
+			booksCards = append(booksCards, style.Render(cardContent))
+			continue
+		}
+
+		card := style.Render("")
+		if b := m.books[absoluteIndex]; metadata.InProgress(b) {
+			card = progressBorder(card, b.Progress, style.GetBorderTopForeground())
+		}
+		booksCards = append(booksCards, card)
 		if cover != "" {
 			rowIdx := i / m.cols
 			colIdx := i % m.cols
@@ -1052,7 +1409,35 @@ func (m Model) View() string {
 	}
 
 	book := lipgloss.JoinVertical(lipgloss.Top, rows...)
-	libraryHint := lipgloss.NewStyle().Foreground(normal).Faint(true).Render("  ↑/↓ (j/k): move  ←/→ (h/l): page  r/u/t: status  s: rate  esc: sidebar")
+	libraryHint := lipgloss.NewStyle().Foreground(normal).Faint(true).Render("  ↑/↓ (j/k): move  ←/→ (h/l): page  enter: details  r/u/t: status  a: archive  d: remove  s: rate  p: progress  b: shelves  /: search  f: sort/filter  esc: sidebar")
+	if m.confirmRemove && m.cursor < len(m.books) {
+		libraryHint = lipgloss.NewStyle().Foreground(highlight).Render(fmt.Sprintf("  Remove %q?", m.books[m.cursor].Metadata.Title)) +
+			lipgloss.NewStyle().Foreground(normal).Faint(true).Render("  d: delete the file  m: move it to "+metadata.TrashDir+"/  esc: cancel")
+	} else if m.showShelfPicker {
+		libraryHint = lipgloss.NewStyle().Foreground(normal).Faint(true).Render("  ↑/↓ (j/k): move  space: toggle shelf  enter: save  esc: cancel")
+	} else if m.notice != "" {
+		libraryHint = lipgloss.NewStyle().Foreground(highlight).Render("  " + m.notice)
+	} else if m.showSearch {
+		libraryHint = "  " + m.searchInput.View()
+	} else if m.showFilterBar {
+		libraryHint = m.filterBarView()
+	} else {
+		active := make([]string, 0, 3)
+		if m.scope != nil && m.currentView != "Books" && m.currentView != "To-Be Read" && m.currentView != "Currently Reading" && m.currentView != "Series" {
+			active = append(active, m.currentView)
+		}
+		if query := strings.TrimSpace(m.searchInput.Value()); query != "" {
+			active = append(active, fmt.Sprintf("search: %q (%d results)", query, len(m.books)))
+		}
+		if !m.filter.IsDefault() {
+			active = append(active, m.filter.Summary())
+		}
+		if len(active) > 0 {
+			libraryHint = lipgloss.NewStyle().Foreground(highlight).Render("  "+strings.Join(active, "  |  ")) +
+				lipgloss.NewStyle().Foreground(normal).Faint(true).Render("  /: search  f: sort/filter")
+		}
+	}
+	libraryHint = ansi.Truncate(libraryHint, m.width-sideWidth-8, "...")
 	books := lipgloss.JoinVertical(lipgloss.Left, book, "", libraryHint)
 	library := libraryBorderStyle.Render(books)
 	contentSide := (lipgloss.JoinVertical(lipgloss.Bottom, library, m.lowBarView()))
@@ -1100,34 +1485,24 @@ func (m *Model) syncVisibleWidget() tea.Cmd {
 	booksToLoad := m.books[m.start:m.end]
 	curWidth := m.dynamicCardWidth
 	curHeight := m.dynamicCardHeight
-	curCellPixelWidth := m.cellPixelWidth
-	curCellPixelHeight := m.cellPixelHeight
 	protocol := termimg.DetectProtocol()
-	features := termimg.QueryTerminalFeatures()
-	targetPixelWidth := curWidth
-	targetPixelHeight := curHeight
-	if curCellPixelWidth > 0 && curCellPixelHeight > 0 {
-		targetPixelWidth = curWidth * curCellPixelWidth
-		targetPixelHeight = curHeight * curCellPixelHeight
-	} else if features != nil && features.FontWidth > 0 && features.FontHeight > 0 {
-		targetPixelWidth = curWidth * features.FontWidth
-		targetPixelHeight = curHeight * features.FontHeight
-	}
-	if targetPixelWidth <= 0 {
-		targetPixelWidth = curWidth
-	}
-	if targetPixelHeight <= 0 {
-		targetPixelHeight = curHeight
-	}
+	targetPixelWidth, targetPixelHeight := m.coverPixelSize(curWidth, curHeight)
+	style := placeholderStyle(coverPalette)
 
 	cmds := make([]tea.Cmd, 0, len(booksToLoad))
 	for i, book := range booksToLoad {
 		absoluteIndex := i + m.start
 		path, err := m.handler.SelectBookPath(book.BookFile)
-		if err != nil || path == "" {
+		if err != nil {
 			continue
 		}
-		cacheKey := fmt.Sprintf("%s|%s|%dx%d|%dx%d|%v", book.BookFile, path, curWidth, curHeight, targetPixelWidth, targetPixelHeight, protocol)
+		// Books without a cover get a generated one, redrawn when the theme
+		// or the title changes.
+		source := path
+		if path == "" {
+			source = "placeholder:" + coverPalette.Name + ":" + book.Metadata.Title + ":" + book.Metadata.Author
+		}
+		cacheKey := fmt.Sprintf("%s|%s|%dx%d|%dx%d|%v", book.BookFile, source, curWidth, curHeight, targetPixelWidth, targetPixelHeight, protocol)
 		if cached, ok := m.coverRenderCache[cacheKey]; ok {
 			m.covers[absoluteIndex] = cached
 			continue
@@ -1140,38 +1515,69 @@ func (m *Model) syncVisibleWidget() tea.Cmd {
 		idx := absoluteIndex
 		coverPath := path
 		key := cacheKey
+		cm, title, author := m.handler.CM, book.Metadata.Title, book.Metadata.Author
 		cmds = append(cmds, func() tea.Msg {
-			srcImage, err := imaging.Open(coverPath)
-			if err != nil {
-				return coverLoadedMsg{index: idx, key: key, data: ""}
+			if coverPath == "" {
+				coverPath = placeholderCover(cm, title, author, style)
 			}
+			data := renderCover(coverPath, curWidth, curHeight, targetPixelWidth, targetPixelHeight, protocol)
+			return coverLoadedMsg{index: idx, key: key, data: data}
+		})
+	}
 
-			resizedImage := imaging.Fit(srcImage, targetPixelWidth, targetPixelHeight, imaging.Lanczos)
-			if resizedImage.Bounds().Dx() != targetPixelWidth || resizedImage.Bounds().Dy() != targetPixelHeight {
-				canvas := imaging.New(targetPixelWidth, targetPixelHeight, color.NRGBA{R: 10, G: 10, B: 10, A: 255})
-				resizedImage = imaging.PasteCenter(canvas, resizedImage)
-			}
+	return tea.Batch(cmds...)
+}
 
-			img := termimg.New(resizedImage).Scale(termimg.ScaleNone)
-			if protocol == termimg.Halfblocks {
-				img = img.Dither(true).DitherMode(termimg.DitherFloydSteinberg)
-			}
+func (m *Model) coverPixelSize(cols, rows int) (int, int) {
+	features := termimg.QueryTerminalFeatures()
+	targetPixelWidth := cols
+	targetPixelHeight := rows
+	if m.cellPixelWidth > 0 && m.cellPixelHeight > 0 {
+		targetPixelWidth = cols * m.cellPixelWidth
+		targetPixelHeight = rows * m.cellPixelHeight
+	} else if features != nil && features.FontWidth > 0 && features.FontHeight > 0 {
+		targetPixelWidth = cols * features.FontWidth
+		targetPixelHeight = rows * features.FontHeight
+	}
+	if targetPixelWidth <= 0 {
+		targetPixelWidth = cols
+	}
+	if targetPixelHeight <= 0 {
+		targetPixelHeight = rows
+	}
+	return targetPixelWidth, targetPixelHeight
+}
 
-			cover := termimg.NewImageWidget(img)
-			cover.SetSize(curWidth, curHeight).SetProtocol(protocol)
-			if cover == nil {
-				return coverLoadedMsg{index: idx, key: key, data: ""}
-			}
-			coverRendered, err := cover.Render()
-			if err != nil {
-				log.Printf("Err rendering cover: %v ", err)
-				return coverLoadedMsg{index: idx, key: key, data: ""}
-			}
-			return coverLoadedMsg{index: idx, key: key, data: coverRendered}
-		})
+// renderCover letterboxes the image at coverPath into a cols x rows cell box
+// and returns the escape sequence for the terminal's graphics protocol.
+func renderCover(coverPath string, cols, rows, pixelWidth, pixelHeight int, protocol termimg.Protocol) string {
+	srcImage, err := imaging.Open(coverPath)
+	if err != nil {
+		return ""
 	}
 
-	return tea.Batch(cmds...)
+	resizedImage := imaging.Fit(srcImage, pixelWidth, pixelHeight, imaging.Lanczos)
+	if resizedImage.Bounds().Dx() != pixelWidth || resizedImage.Bounds().Dy() != pixelHeight {
+		canvas := imaging.New(pixelWidth, pixelHeight, color.NRGBA{R: 10, G: 10, B: 10, A: 255})
+		resizedImage = imaging.PasteCenter(canvas, resizedImage)
+	}
+
+	img := termimg.New(resizedImage).Scale(termimg.ScaleNone)
+	if protocol == termimg.Halfblocks {
+		img = img.Dither(true).DitherMode(termimg.DitherFloydSteinberg)
+	}
+
+	cover := termimg.NewImageWidget(img)
+	cover.SetSize(cols, rows).SetProtocol(protocol)
+	if cover == nil {
+		return ""
+	}
+	coverRendered, err := cover.Render()
+	if err != nil {
+		log.Printf("Err rendering cover: %v ", err)
+		return ""
+	}
+	return coverRendered
 }
 
 func getCellPixelSize(cols, rows int) (int, int) {
@@ -1211,7 +1617,7 @@ func (m *MainModel) SideBarView() string {
 		itemWidth = 0
 	}
 	inactiveStyle := lipgloss.NewStyle().
-		Foreground(lipgloss.Color(240)).
+		Foreground(lipgloss.Color("240")).
 		Width(itemWidth).
 		PaddingLeft(1).
 		MarginBottom(1)
@@ -1221,20 +1627,37 @@ func (m *MainModel) SideBarView() string {
 		style = style.BorderForeground(borders)
 	}
 
+	// Shelves are listed under "Shelves", indented and without the gap
+	// between entries so a long list still fits.
+	firstShelf := indexOf(m.library.MenuOptions, "Shelves") + 1
+	cursorLine, lines := 0, 0
 	for i, word := range m.library.MenuOptions {
 		prefix := "  "
 		if i == m.library.sideBarCursor {
 			prefix = "> "
 		}
-		text := prefix + utils.ToSansBold(word)
+		itemStyle := inactiveStyle
+		if i == m.library.sideBarCursor {
+			itemStyle = activeStyle
+		}
+		if i >= firstShelf && i < firstShelf+len(m.shelves) {
+			prefix = "  " + prefix
+			if i < firstShelf+len(m.shelves)-1 {
+				itemStyle = itemStyle.MarginBottom(0)
+			}
+		}
 		if i == m.library.sideBarCursor {
-			renderedOptionsList[i] = activeStyle.Render(text)
-		} else {
-			renderedOptionsList[i] = inactiveStyle.Render(text)
+			cursorLine = lines
 		}
+		renderedOptionsList[i] = itemStyle.Render(prefix + utils.ToSansBold(word))
+		lines += lipgloss.Height(renderedOptionsList[i])
 	}
 
 	items := lipgloss.JoinVertical(lipgloss.Left, renderedOptionsList...)
+	if height := m.library.height + 2; lines > height {
+		start, end := listWindow(cursorLine, lines, height)
+		items = strings.Join(strings.Split(items, "\n")[start:end], "\n")
+	}
 	options = style.Render(items)
 	return options
 }
@@ -1285,20 +1708,42 @@ func (m *Model) lowBarView() string {
 		readingDate = "Not read yet"
 	}
 	readingDateText := readingDateLabel + " " + readingDate
+	seriesText, nextText := "", ""
+	if series := m.books[m.cursor].Metadata.Series; series != "" {
+		seriesLabel := lipgloss.NewStyle().Foreground(normal).Bold(true).Render("Series:")
+		nextLabel := lipgloss.NewStyle().Foreground(normal).Bold(true).Render("Next unread:")
+		seriesText = "\n" + seriesLabel + " " + m.books[m.cursor].Metadata.SeriesLabel()
+		switch next := metadata.NextUnreadInSeries(m.allBooks, series); {
+		case next == nil:
+			nextText = "\n" + nextLabel + " series finished"
+		case next == m.books[m.cursor]:
+			nextText = "\n" + nextLabel + " this book"
+		default:
+			nextText = "\n" + nextLabel + " " + next.Metadata.Title
+			if next.Metadata.SeriesIndex > 0 {
+				nextText += " (#" + metadata.FormatSeriesIndex(next.Metadata.SeriesIndex) + ")"
+			}
+		}
+	}
 
 	innerWidth := contentWidth - 4
 	columnGap := 2
 	columnWidth := (innerWidth-columnGap)/3 + 1
 
+	progressText := ""
+	if current := m.books[m.cursor]; metadata.InProgress(current) {
+		progressLabel := lipgloss.NewStyle().Foreground(normal).Bold(true).Render("Progress:")
+		progressText = "\n" + progressLabel + " " + progressBar(current.Progress, min(columnWidth-10, 30))
+	}
 	leftCol := lipgloss.NewStyle().Width(columnWidth).Render(
-		title + "\n" + genresLabel + " " + genres,
+		title + "\n" + genresLabel + " " + genres + progressText,
 	)
 	medCol := lipgloss.NewStyle().Width(columnWidth).Render(
-		author + "\n" + status,
+		author + "\n" + status + seriesText,
 	)
 	stars := utils.GetStarRating(selectedBook.Rating)
 	rightCol := lipgloss.NewStyle().Width(columnWidth).Render(
-		ratingLabel + " " + ratingValue + " " + stars + "\n" + readingDateText,
+		ratingLabel + " " + ratingValue + " " + stars + "\n" + readingDateText + nextText,
 	)
 
 	finalString := lipgloss.JoinHorizontal(lipgloss.Top, leftCol, strings.Repeat(" ", columnGap), medCol, strings.Repeat(" ", columnGap-1), rightCol)
@@ -1306,10 +1751,17 @@ func (m *Model) lowBarView() string {
 }
 
 func (m *Model) SetView(option string) tea.Cmd {
+	if option != m.currentView {
+		m.shelf = 0
+		m.shelfQuery = ""
+		m.showSearch = false
+		m.searchInput.Blur()
+		m.searchInput.Reset()
+	}
 	m.currentView = option
 	switch option {
 	case "Books":
-		m.books = m.allBooks
+		m.viewBooks = m.filter.Apply(m.allBooks)
 	case "To-Be Read":
 		var filtered []*metadata.Package
 		for _, b := range m.allBooks {
@@ -1317,9 +1769,255 @@ func (m *Model) SetView(option string) tea.Cmd {
 				filtered = append(filtered, b)
 			}
 		}
-		m.books = filtered
+		m.viewBooks = m.filter.Apply(filtered)
+	case "Currently Reading":
+		var reading []*metadata.Package
+		for _, b := range m.allBooks {
+			if b.Status == "Currently Reading" {
+				reading = append(reading, b)
+			}
+		}
+		m.viewBooks = m.filter.Apply(reading)
+	case "Series":
+		var inSeries []*metadata.Package
+		for _, b := range m.allBooks {
+			if b.Metadata.Series != "" {
+				inSeries = append(inSeries, b)
+			}
+		}
+		m.viewBooks = m.filter.Filter(inSeries)
+		metadata.SortBySeries(m.viewBooks)
+	default:
+		if m.shelfQuery != "" {
+			m.scope = m.queryScope()
+		}
+		var scoped []*metadata.Package
+		for _, b := range m.allBooks {
+			if _, ok := m.scope[b.BookFile]; ok {
+				scoped = append(scoped, b)
+			}
+		}
+		m.viewBooks = m.filter.Apply(scoped)
+	}
+	m.applySearch()
+	return m.resetGrid()
+}
+
+// SetScopedView shows only the given files under a custom view name, keeping
+// the sort/filter bar and search working on top of it.
+func (m *Model) SetScopedView(name string, files []string) tea.Cmd {
+	m.scope = make(map[string]struct{}, len(files))
+	for _, f := range files {
+		m.scope[f] = struct{}{}
+	}
+	return m.SetView(name)
+}
+
+func (m *Model) capturingInput() bool {
+	return m.showRatingInput || m.showProgressInput || m.showSearch || m.showFilterBar || m.confirmRemove || m.showShelfPicker
+}
+
+var filterBarFields = []string{"Sort", "Order", "Status", "Genre", "Language", "Min rating"}
+
+func (m *Model) filterOptions(field int) ([]string, int) {
+	switch field {
+	case 0:
+		return filter.SortFields, indexOf(filter.SortFields, m.filter.SortBy)
+	case 1:
+		opts := []string{"Ascending", "Descending"}
+		if m.filter.SortDesc {
+			return opts, 1
+		}
+		return opts, 0
+	case 2:
+		opts := m.distinctValues(func(b *metadata.Package) []string { return []string{b.Status} }, "Read", "Unread", "To Be Read", "Currently Reading", "Archived")
+		return opts, indexOf(opts, m.filter.Status)
+	case 3:
+		opts := m.distinctValues(func(b *metadata.Package) []string { return b.Metadata.Genres })
+		return opts, indexOf(opts, m.filter.Genre)
+	case 4:
+		opts := m.distinctValues(func(b *metadata.Package) []string { return []string{b.Metadata.Language} })
+		return opts, indexOf(opts, m.filter.Language)
+	default:
+		opts := make([]string, len(filter.MinRatings))
+		current := 0
+		for i, r := range filter.MinRatings {
+			opts[i] = strconv.FormatFloat(r, 'f', 1, 64)
+			if r == m.filter.MinRating {
+				current = i
+			}
+		}
+		return opts, current
+	}
+}
+
+// distinctValues lists "" (no filter) followed by every value found in the
+// library, plus any always-offered extras.
+func (m *Model) distinctValues(values func(*metadata.Package) []string, extra ...string) []string {
+	seen := make(map[string]struct{})
+	out := make([]string, 0)
+	add := func(v string) {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			return
+		}
+		if _, ok := seen[strings.ToLower(v)]; ok {
+			return
+		}
+		seen[strings.ToLower(v)] = struct{}{}
+		out = append(out, v)
+	}
+	for _, v := range extra {
+		add(v)
+	}
+	for _, b := range m.allBooks {
+		for _, v := range values(b) {
+			add(v)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return strings.ToLower(out[i]) < strings.ToLower(out[j]) })
+	return append([]string{""}, out...)
+}
+
+func indexOf(opts []string, v string) int {
+	for i, o := range opts {
+		if strings.EqualFold(o, v) {
+			return i
+		}
+	}
+	return 0
+}
+
+func (m *Model) cycleFilter(delta int) tea.Cmd {
+	opts, current := m.filterOptions(m.filterCursor)
+	if len(opts) == 0 {
+		return nil
+	}
+	next := (current + delta + len(opts)) % len(opts)
+	switch m.filterCursor {
+	case 0:
+		m.filter.SortBy = opts[next]
+	case 1:
+		m.filter.SortDesc = next == 1
+	case 2:
+		m.filter.Status = opts[next]
+	case 3:
+		m.filter.Genre = opts[next]
+	case 4:
+		m.filter.Language = opts[next]
+	default:
+		m.filter.MinRating = filter.MinRatings[next]
+	}
+	m.saveFilter()
+	return m.SetView(m.currentView)
+}
+
+func (m *Model) saveFilter() {
+	if err := filter.Save(m.filter); err != nil {
+		log.Printf("Error saving library filters: %v", err)
+	}
+}
+
+func (m *Model) filterBarView() string {
+	labelStyle := lipgloss.NewStyle().Foreground(normal)
+	activeStyle := lipgloss.NewStyle().Foreground(highlight).Bold(true)
+	parts := make([]string, 0, len(filterBarFields))
+	for i, field := range filterBarFields {
+		opts, current := m.filterOptions(i)
+		value := "All"
+		if current < len(opts) && opts[current] != "" {
+			value = opts[current]
+		}
+		style := labelStyle
+		if i == m.filterCursor {
+			style = activeStyle
+		}
+		parts = append(parts, style.Render(field+": ["+value+"]"))
+	}
+	hint := lipgloss.NewStyle().Foreground(normal).Faint(true).Render("  ←/→ ↑/↓: edit  x: reset  esc: close")
+	return "  " + strings.Join(parts, " ") + hint
+}
+
+func (m *Model) applySearch() {
+	query := strings.TrimSpace(m.searchInput.Value())
+	if query == "" {
+		m.books = m.viewBooks
+		return
+	}
+	ranked, err := m.handler.SearchBooks(query)
+	if err != nil {
+		log.Printf("Error searching books: %v", err)
+		m.books = m.viewBooks
+		return
+	}
+	byFile := make(map[string]*metadata.Package, len(m.viewBooks))
+	for _, b := range m.viewBooks {
+		byFile[b.BookFile] = b
+	}
+	results := make([]*metadata.Package, 0, len(ranked))
+	for _, f := range ranked {
+		if b, ok := byFile[f]; ok {
+			results = append(results, b)
+		}
+	}
+	m.books = results
+}
+
+// focusBook moves the cursor, and the page, to the given file if it is still
+// in the current view.
+func (m *Model) focusBook(file string) tea.Cmd {
+	for i, b := range m.books {
+		if b.BookFile != file {
+			continue
+		}
+		m.cursor = i
+		if m.paginator.PerPage > 0 {
+			m.paginator.Page = i / m.paginator.PerPage
+		}
+		break
 	}
+	return tea.Batch(tea.ClearScreen, m.syncVisibleWidget())
+}
 
+// neighbour returns the book the cursor should land on once the current one
+// leaves the view.
+func (m *Model) neighbour() string {
+	switch {
+	case m.cursor+1 < len(m.books):
+		return m.books[m.cursor+1].BookFile
+	case m.cursor > 0:
+		return m.books[m.cursor-1].BookFile
+	}
+	return ""
+}
+
+func (m *Model) removeCurrent(trash bool) tea.Cmd {
+	if m.cursor >= len(m.books) {
+		return tea.ClearScreen
+	}
+	b := m.books[m.cursor]
+	next := m.neighbour()
+	dest, err := m.handler.RemoveBook(b.BookFile, trash)
+	if err != nil {
+		log.Printf("Error removing book: %v", err)
+		m.notice = "Could not remove " + b.Metadata.Title + ": " + err.Error()
+		return tea.ClearScreen
+	}
+	kept := make([]*metadata.Package, 0, len(m.allBooks))
+	for _, other := range m.allBooks {
+		if other != b {
+			kept = append(kept, other)
+		}
+	}
+	m.allBooks = kept
+	m.notice = "Removed " + b.Metadata.Title
+	if dest != "" {
+		m.notice += " (moved to " + dest + ")"
+	}
+	return tea.Batch(m.SetView(m.currentView), m.focusBook(next))
+}
+
+func (m *Model) resetGrid() tea.Cmd {
 	m.paginator.SetTotalPages(len(m.books))
 	m.paginator.Page = 0
 	m.cursor = 0
@@ -1392,6 +2090,7 @@ func (m *MainModel) FilePickerView() string {
 	if m.importStatus != "" {
 		s.WriteString("\n\n  " + m.importStatus)
 	}
+	s.WriteString(m.conflictView())
 	contentWidth := panelWidth - 2
 	if contentWidth < 10 {
 		contentWidth = 10
@@ -1461,6 +2160,12 @@ func (m *MainModel) KindleView() string {
 	if m.kindleStatus != "" {
 		s.WriteString("\n  " + m.kindleStatus + "\n")
 	}
+	if m.conflictDir != "" {
+		if m.importStatus != "" {
+			s.WriteString("\n  " + m.importStatus)
+		}
+		s.WriteString(m.conflictView())
+	}
 
 	content := truncateBlockHeight(truncateViewLines(s.String(), panelWidth-2), panelHeight)
 	return lipgloss.JoinHorizontal(lipgloss.Left, sidebarView, style.Render(content))
@@ -1536,6 +2241,12 @@ func (m *MainModel) filePickerLayout(panelHeight int) (int, int) {
 			selectedLines++
 		}
 	}
+	if n := len(m.importConflicts); n > 0 && !m.importing {
+		selectedLines += 4
+		if n > 1 {
+			selectedLines++
+		}
+	}
 	pickerHeight := panelHeight - headerLines - selectedLines - 4
 	if pickerHeight < minPickerHeight {
 		pickerHeight = minPickerHeight
@@ -1576,73 +2287,91 @@ func (m *MainModel) addSelectedFile(path string) {
 	m.selectedOrder = append(m.selectedOrder, path)
 }
 
-func (m *MainModel) importBooksCmd(selected []string) tea.Cmd {
-	handler := m.library.handler
-	return func() tea.Msg {
-		booksFolder, err := os.ReadDir("./books")
-		if err != nil {
-			return importFinishedMsg{err: err}
-		}
-		existingNames := make(map[string]struct{}, len(booksFolder))
-		for _, b := range booksFolder {
-			existingNames[b.Name()] = struct{}{}
+func (m *MainModel) deselectFile(path string) {
+	delete(m.selectedFiles, path)
+	for i, val := range m.selectedOrder {
+		if val == path {
+			m.selectedOrder = utils.Delete_at_index(m.selectedOrder, i)
+			return
 		}
+	}
+}
 
-		successfulCopies := make([]string, 0, len(selected))
-		failedBooks := make([]string, 0)
-		duplicateCount := 0
-		for _, book := range selected {
-			filename := filepath.Base(book)
-			exist, err := handler.CheckBookExist(filename)
-			if err != nil {
-				failedBooks = append(failedBooks, book)
-				continue
-			}
-			if exist != 0 {
-				duplicateCount++
-				continue
-			}
-			if _, exists := existingNames[filename]; exists {
-				continue
-			}
-			if err := utils.CopyFile(book, "./books/"+filename); err != nil {
-				failedBooks = append(failedBooks, book)
-				continue
-			}
-			existingNames[filename] = struct{}{}
-			successfulCopies = append(successfulCopies, book)
-		}
+// resolveConflict answers the first pending import conflict with s (skip),
+// k (keep both) or r (replace); ok is false when msg is not such an answer.
+func (m *MainModel) resolveConflict(msg tea.Msg) (tea.Cmd, bool) {
+	keyMsg, isKey := msg.(tea.KeyMsg)
+	if !isKey || m.importing || len(m.importConflicts) == 0 {
+		return nil, false
+	}
+	policy := metadata.DuplicatePolicy("")
+	switch keyMsg.String() {
+	case "s":
+		policy = metadata.DuplicateSkip
+	case "k":
+		policy = metadata.DuplicateKeep
+	case "r":
+		policy = metadata.DuplicateReplace
+	default:
+		return nil, false
+	}
+	conflict := m.importConflicts[0]
+	m.importConflicts = m.importConflicts[1:]
+	if policy == metadata.DuplicateSkip {
+		m.deselectFile(conflict.Path)
+		m.importStatus = "Skipped " + filepath.Base(conflict.Path)
+		m.removeConflictDir()
+		return nil, true
+	}
+	m.importing = true
+	m.showLoader = false
+	m.importStatus = ""
+	return m.importBooksCmd([]string{conflict.Path}, policy), true
+}
 
-		if len(successfulCopies) == 0 {
-			return importFinishedMsg{
-				successfulCopies: successfulCopies,
-				failedBooks:      failedBooks,
-				duplicateCount:   duplicateCount,
-			}
-		}
+// removeConflictDir deletes the converted Kindle books kept for the prompt
+// once every conflict is answered; the last answer stays on the Kindle screen.
+func (m *MainModel) removeConflictDir() {
+	if m.conflictDir == "" || m.importing || len(m.importConflicts) > 0 {
+		return
+	}
+	if m.state == kindleState {
+		m.kindleStatus = m.importStatus
+	}
+	if err := os.RemoveAll(m.conflictDir); err != nil {
+		log.Printf("Error removing %s: %v", m.conflictDir, err)
+	}
+	m.conflictDir = ""
+}
 
-		if _, err := handler.InsertBooks(); err != nil {
-			return importFinishedMsg{
-				successfulCopies: successfulCopies,
-				failedBooks:      failedBooks,
-				duplicateCount:   duplicateCount,
-				err:              err,
-			}
-		}
-		books, err := handler.SelectBooks()
-		if err != nil {
-			return importFinishedMsg{
-				successfulCopies: successfulCopies,
-				failedBooks:      failedBooks,
-				duplicateCount:   duplicateCount,
-				err:              err,
-			}
-		}
+func (m *MainModel) conflictView() string {
+	if len(m.importConflicts) == 0 || m.importing {
+		return ""
+	}
+	var s strings.Builder
+	c := m.importConflicts[0]
+	s.WriteString("\n\n  Possible duplicate: " + filepath.Base(c.Path) + " (" + c.Title + ")")
+	s.WriteString("\n  looks like " + c.ExistingFile + " (" + c.ExistingTitle + ")")
+	if more := len(m.importConflicts) - 1; more > 0 {
+		s.WriteString("\n  " + strconv.Itoa(more) + " more to review")
+	}
+	conflictHint := lipgloss.NewStyle().Foreground(normal).Faint(true).Render("s: skip  k: keep both  r: replace")
+	s.WriteString("\n  " + conflictHint)
+	return s.String()
+}
+
+func (m *MainModel) importBooksCmd(selected []string, policy metadata.DuplicatePolicy) tea.Cmd {
+	handler := m.library.handler
+	return func() tea.Msg {
+		res, err := handler.ImportFiles(selected, policy)
 		return importFinishedMsg{
-			successfulCopies: successfulCopies,
-			failedBooks:      failedBooks,
-			duplicateCount:   duplicateCount,
-			refreshedBooks:   books,
+			successfulCopies: res.Inserted,
+			replacedBooks:    res.Replaced,
+			failedBooks:      res.Failed,
+			duplicateCount:   res.Duplicated,
+			conflicts:        res.Conflicts,
+			refreshedBooks:   res.Refreshed,
+			err:              err,
 		}
 	}
 }
@@ -1665,11 +2394,14 @@ func (m *MainModel) kindleSyncCmd(docsURI string, selected []string) tea.Cmd {
 	return func() tea.Msg {
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
 		defer cancel()
-		res, err := kindle.KindleExtract(ctx, &handler, docsURI, selected)
+		res, err := kindle.KindleExtract(ctx, &handler, docsURI, selected, metadata.DuplicateAsk)
 		return kindleSyncFinishedMsg{
 			inserted:      res.Inserted,
+			replaced:      res.Replaced,
 			failed:        res.Failed,
 			duplicated:    res.Duplicated,
+			conflicts:     res.Conflicts,
+			conflictDir:   res.Dir,
 			refreshedBook: res.Refreshed,
 			err:           err,
 		}
@@ -0,0 +1,48 @@
+package tui
+
+import (
+	metadata "Kindria/internal/core/api/books"
+	uiTheme "Kindria/internal/tui/theme"
+	"image/color"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// coverPalette is the palette placeholder covers are drawn with; it follows
+// applyThemePalette.
+var coverPalette = uiTheme.Default()
+
+// placeholderStyle shades the border color down into the subtle one, so the
+// palette's light text stays readable, and uses the highlight as accent.
+func placeholderStyle(p uiTheme.Palette) metadata.PlaceholderStyle {
+	black := color.RGBA{A: 255}
+	return metadata.PlaceholderStyle{
+		Top:    metadata.MixColor(hexColor(p.BorderDark), black, 0.55),
+		Bottom: metadata.MixColor(hexColor(p.SubtleDark), black, 0.6),
+		Accent: hexColor(p.HighlightDark),
+		Text:   hexColor(p.Normal),
+	}
+}
+
+// placeholderCover returns the generated cover for a book without one, or ""
+// when it cannot be drawn.
+func placeholderCover(cm *metadata.CoverManager, title, author string, style metadata.PlaceholderStyle) string {
+	if cm == nil {
+		return ""
+	}
+	path, err := cm.PlaceholderCover(title, author, style)
+	if err != nil {
+		log.Printf("Err generating a cover for %q: %v", title, err)
+		return ""
+	}
+	return path
+}
+
+func hexColor(s string) color.RGBA {
+	v, err := strconv.ParseUint(strings.TrimPrefix(s, "#"), 16, 32)
+	if err != nil {
+		return color.RGBA{A: 255}
+	}
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 255}
+}
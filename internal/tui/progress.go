@@ -0,0 +1,132 @@
+package tui
+
+import (
+	metadata "Kindria/internal/core/api/books"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+func newProgressInput() textinput.Model {
+	t := textinput.New()
+	t.Placeholder = "0-100"
+	t.CharLimit = 6
+	t.Width = 10
+	return t
+}
+
+// parseProgress reads a manual progress entry, with or without a trailing %.
+func parseProgress(s string) (float64, bool) {
+	percent, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(s), "%"), 64)
+	if err != nil || percent < 0 || percent > 100 {
+		return 0, false
+	}
+	return percent, true
+}
+
+// setProgress records a manual progress entry and reports whether it moved
+// the book to another status.
+func (m *Model) setProgress(b *metadata.Package, percent float64) bool {
+	status, readingDate, err := m.handler.SaveProgress(b.BookFile, metadata.Progress{Chapter: -1, Percent: percent})
+	if err != nil {
+		log.Printf("Error trying to update progress: %v", err)
+		return false
+	}
+	changed := status != b.Status
+	b.Progress = percent
+	b.Status = status
+	b.ReadingDate = readingDate
+	return changed
+}
+
+// viewDependsOnStatus reports whether a status change can take a book in or
+// out of the current view.
+func (m *Model) viewDependsOnStatus() bool {
+	return m.currentView == "To-Be Read" || m.currentView == "Currently Reading" || m.filter.Status != "" || m.shelfQuery != ""
+}
+
+func (m *Model) updateProgressInput(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "ctrl+c", "q":
+			return m, tea.Quit
+		case "esc":
+			m.closeProgressInput()
+			return m, nil
+		case "enter":
+			text := strings.TrimSpace(m.progressInput.Value())
+			changed := false
+			if text != "" {
+				percent, ok := parseProgress(text)
+				if !ok {
+					m.progressInput.Reset()
+					m.progressInput.Placeholder = "Invalid!"
+					return m, nil
+				}
+				changed = m.setProgress(m.books[m.cursor], percent)
+			}
+			m.closeProgressInput()
+			if changed && m.viewDependsOnStatus() {
+				return m, m.SetView(m.currentView)
+			}
+			return m, tea.ClearScreen
+		}
+	}
+	var cmd tea.Cmd
+	m.progressInput, cmd = m.progressInput.Update(msg)
+	return m, cmd
+}
+
+func (m *Model) closeProgressInput() {
+	m.showProgressInput = false
+	m.progressInput.Blur()
+	m.progressInput.Reset()
+	m.progressInput.Placeholder = "0-100"
+}
+
+// progressBar draws percent as a bar of width cells followed by the number.
+func progressBar(percent float64, width int) string {
+	label := " " + strconv.Itoa(int(percent)) + "%"
+	width = max(width-len(label), 1)
+	filled := min(int(float64(width)*percent/100+0.5), width)
+	return lipgloss.NewStyle().Foreground(highlight).Render(strings.Repeat("━", filled)) +
+		lipgloss.NewStyle().Foreground(subtle).Render(strings.Repeat("─", width-filled)) +
+		lipgloss.NewStyle().Foreground(normal).Render(label)
+}
+
+// progressBorder draws the reading progress of a card into its bottom border,
+// which is the only row the cover image leaves free.
+func progressBorder(card string, percent float64, border lipgloss.TerminalColor) string {
+	lines := strings.Split(card, "\n")
+	last := len(lines) - 1
+	inner := lipgloss.Width(lines[last]) - 2
+	label := " " + strconv.Itoa(int(percent)) + "% "
+	if inner < len(label)+2 {
+		return card
+	}
+	width := inner - len(label)
+	filled := min(int(float64(width)*percent/100+0.5), width)
+	edge := lipgloss.NewStyle().Foreground(border)
+	lines[last] = edge.Render("└") +
+		lipgloss.NewStyle().Foreground(highlight).Render(strings.Repeat("━", filled)) +
+		edge.Render(strings.Repeat("─", width-filled)+label+"┘")
+	return strings.Join(lines, "\n")
+}
+
+// progressSummary is the detail screen's progress line: the bar, where the
+// reader left off and when the book was last opened.
+func progressSummary(p metadata.Progress, width int) string {
+	if p.Percent == 0 && p.OpenedAt == "" {
+		return ""
+	}
+	s := progressBar(p.Percent, min(width, 24))
+	if t, err := time.ParseInLocation("2006-01-02 15:04:05", p.OpenedAt, time.Local); err == nil {
+		s += lipgloss.NewStyle().Foreground(normal).Faint(true).Render(", opened " + t.Format("2006-01-02 15:04"))
+	}
+	return s
+}
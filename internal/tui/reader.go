@@ -0,0 +1,480 @@
+package tui
+
+import (
+	metadata "Kindria/internal/core/api/books"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// readerTextWidth caps the length of a line of text; longer lines are hard
+// to follow.
+const readerTextWidth = 72
+
+// readerStyles are the reader's text styles, taken from the current theme.
+type readerStyles struct {
+	text    lipgloss.Style
+	heading lipgloss.Style
+	marker  lipgloss.Style
+	quote   lipgloss.Style
+	faint   lipgloss.Style
+}
+
+func newReaderStyles() readerStyles {
+	return readerStyles{
+		text:    lipgloss.NewStyle().Foreground(normal),
+		heading: lipgloss.NewStyle().Foreground(highlight).Bold(true),
+		marker:  lipgloss.NewStyle().Foreground(highlight),
+		quote:   lipgloss.NewStyle().Foreground(borders),
+		faint:   lipgloss.NewStyle().Foreground(subtle).Italic(true),
+	}
+}
+
+func (m *MainModel) openReader() (tea.Model, tea.Cmd) {
+	book, err := m.library.handler.OpenBook(m.detailBook.BookFile)
+	if err != nil {
+		log.Printf("Error opening %s for reading: %v", m.detailBook.BookFile, err)
+		m.detailNotice = "Cannot read this book: " + err.Error()
+		m.layoutDetail()
+		return m, nil
+	}
+	m.reader = book
+	m.readerTOC = false
+	m.readerView = viewport.New(0, 0)
+	m.state = readerState
+
+	// Pick up where the reader left off; a manual entry only has a percentage.
+	// A finished book is a re-read and starts over.
+	p := m.detail.Progress
+	chapter, offset := p.Chapter, p.Offset
+	if chapter < 0 && p.Percent > 0 {
+		chapter, offset = book.Position(p.Percent)
+	}
+	reread := p.Percent >= 100 && m.detailBook.Status != "Archived"
+	if reread || chapter < 0 || chapter >= len(book.Chapters) {
+		chapter, offset = 0, 0
+	}
+	m.loadChapter(chapter, "")
+	m.readerView.SetYOffset(int(offset * float64(m.readerView.TotalLineCount())))
+	m.saveReaderProgress()
+	return m, tea.ClearScreen
+}
+
+// saveReaderProgress records the top of the page, to resume from, and how
+// much of the book has been on screen so far.
+func (m *MainModel) saveReaderProgress() {
+	p := metadata.Progress{
+		Chapter:  m.readerChapter,
+		Offset:   float64(m.readerView.YOffset) / float64(max(m.readerView.TotalLineCount(), 1)),
+		Percent:  m.readerPercent(),
+		OpenedAt: time.Now().Format("2006-01-02 15:04:05"),
+	}
+	status, readingDate, err := m.library.handler.SaveProgress(m.detailBook.BookFile, p)
+	if err != nil {
+		log.Printf("Error saving reading progress of %s: %v", m.detailBook.BookFile, err)
+		return
+	}
+	changed := status != m.detailBook.Status
+	for _, b := range []*metadata.Package{m.detailBook, m.detail.Book} {
+		b.Status = status
+		b.ReadingDate = readingDate
+		b.Progress = p.Percent
+	}
+	m.detail.Progress = p
+	if changed {
+		m.detailStatusChanged = true
+		m.reloadDetailHistory()
+	}
+}
+
+// readerPercent is how much of the book has been on screen, up to the bottom
+// of the page.
+func (m *MainModel) readerPercent() float64 {
+	total := float64(max(m.readerView.TotalLineCount(), 1))
+	seen := min(float64(m.readerView.YOffset+m.readerView.Height)/total, 1)
+	return m.reader.Percent(m.readerChapter, seen)
+}
+
+// turnTo shows another chapter and records the move.
+func (m *MainModel) turnTo(i int, anchor string) {
+	m.loadChapter(i, anchor)
+	m.saveReaderProgress()
+}
+
+func (m *MainModel) closeReader() (tea.Model, tea.Cmd) {
+	m.saveReaderProgress()
+	m.state = detailState
+	m.reader = nil
+	m.readerBlocks = nil
+	m.readerAnchors = nil
+	m.layoutDetail()
+	return m, tea.ClearScreen
+}
+
+// loadChapter shows a chapter from its top, or from the block an anchor
+// lands on.
+func (m *MainModel) loadChapter(i int, anchor string) {
+	blocks, err := m.reader.Chapter(i)
+	m.readerNotice = ""
+	if err != nil {
+		log.Printf("Error reading chapter %d of %s: %v", i, m.detailBook.BookFile, err)
+		m.readerNotice = "This chapter could not be read: " + err.Error()
+	}
+	m.readerChapter = i
+	m.readerBlocks = blocks
+	m.layoutReader()
+	m.readerView.GotoTop()
+	if line, ok := m.readerAnchors[anchor]; ok {
+		m.readerView.SetYOffset(line)
+	}
+}
+
+func (m *MainModel) updateReader(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case coverLoadedMsg, coversLoadedMsg:
+		newLib, cmd := m.library.Update(msg)
+		m.library = newLib.(*Model)
+		return m, cmd
+	case tea.WindowSizeMsg:
+		m.layoutReader()
+		return m, tea.ClearScreen
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	if m.readerTOC {
+		return m.updateReaderTOC(keyMsg)
+	}
+
+	last := len(m.reader.Chapters) - 1
+	switch keyMsg.String() {
+	case "q", "ctrl+c":
+		m.saveReaderProgress()
+		return m, tea.Quit
+	case "esc", "backspace":
+		return m.closeReader()
+	case "right", "l", "n":
+		if m.readerChapter < last {
+			m.turnTo(m.readerChapter+1, "")
+		}
+		return m, nil
+	case "left", "h", "p":
+		if m.readerChapter > 0 {
+			m.turnTo(m.readerChapter-1, "")
+		}
+		return m, nil
+	case " ", "pgdown":
+		// Paging past the end of a chapter turns to the next one.
+		if m.readerView.AtBottom() && m.readerChapter < last {
+			m.turnTo(m.readerChapter+1, "")
+			return m, nil
+		}
+	case "g", "home":
+		m.readerView.GotoTop()
+		return m, nil
+	case "G", "end":
+		m.readerView.GotoBottom()
+		return m, nil
+	case "t":
+		m.readerTOC = true
+		m.readerTOCCursor = m.currentTOCEntry()
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.readerView, cmd = m.readerView.Update(msg)
+	return m, cmd
+}
+
+// currentTOCEntry is the last table of contents entry at or above the top of
+// the page, or anywhere on the last page of a chapter.
+func (m *MainModel) currentTOCEntry() int {
+	top := m.readerView.YOffset
+	if m.readerView.AtBottom() {
+		top += m.readerView.Height - 1
+	}
+	current := 0
+	for i, e := range m.reader.TOC {
+		line, ok := 0, true
+		if e.Anchor != "" {
+			line, ok = m.readerAnchors[e.Anchor]
+		}
+		if e.Chapter < m.readerChapter || (e.Chapter == m.readerChapter && ok && line <= top) {
+			current = i
+		}
+	}
+	return current
+}
+
+func (m *MainModel) updateReaderTOC(keyMsg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch keyMsg.String() {
+	case "ctrl+c":
+		m.saveReaderProgress()
+		return m, tea.Quit
+	case "up", "k":
+		if m.readerTOCCursor > 0 {
+			m.readerTOCCursor--
+		}
+	case "down", "j":
+		if m.readerTOCCursor < len(m.reader.TOC)-1 {
+			m.readerTOCCursor++
+		}
+	case "g", "home":
+		m.readerTOCCursor = 0
+	case "G", "end":
+		m.readerTOCCursor = len(m.reader.TOC) - 1
+	case "enter":
+		e := m.reader.TOC[m.readerTOCCursor]
+		m.readerTOC = false
+		m.turnTo(e.Chapter, e.Anchor)
+	case "esc", "t":
+		m.readerTOC = false
+	}
+	return m, nil
+}
+
+func (m *MainModel) readerTextSize() (int, int) {
+	panelWidth, panelHeight := m.detailPanelSize()
+	return max(min(panelWidth-4, readerTextWidth), 10), max(panelHeight-5, 3)
+}
+
+func (m *MainModel) layoutReader() {
+	if m.reader == nil {
+		return
+	}
+	width, height := m.readerTextSize()
+	offset := m.readerView.YOffset
+	m.readerView.Width = width
+	m.readerView.Height = height
+	content, anchors := renderBlocks(m.readerBlocks, width, newReaderStyles())
+	if content == "" && m.readerNotice == "" {
+		content = newReaderStyles().faint.Render("This chapter has no text.")
+	}
+	m.readerAnchors = anchors
+	m.readerView.SetContent(content)
+	m.readerView.SetYOffset(offset)
+}
+
+// renderBlocks lays a chapter out in lines of at most width cells and
+// returns the line each anchor lands on.
+func renderBlocks(blocks []metadata.Block, width int, st readerStyles) (string, map[string]int) {
+	var lines []string
+	anchors := make(map[string]int)
+	for i, b := range blocks {
+		// Items of the same list follow each other without a blank line.
+		if i > 0 && !(b.List > 0 && blocks[i-1].List > 0) {
+			lines = append(lines, "")
+		}
+		for _, id := range b.IDs {
+			anchors[id] = len(lines)
+		}
+
+		prefix := ""
+		if b.Quote > 0 {
+			prefix = st.quote.Render(strings.Repeat("│ ", b.Quote))
+		}
+		if b.List > 1 {
+			prefix += strings.Repeat("  ", b.List-1)
+		}
+		first, rest := prefix, prefix
+		if b.List > 0 {
+			marker := b.Marker
+			if marker == "" {
+				marker = " "
+			}
+			pad := strings.Repeat(" ", max(2-ansi.StringWidth(marker), 0))
+			first += st.marker.Render(marker) + pad + " "
+			rest += strings.Repeat(" ", ansi.StringWidth(marker)+len(pad)+1)
+		}
+		avail := max(width-lipgloss.Width(first), 10)
+
+		var body []string
+		switch b.Kind {
+		case metadata.BlockRule:
+			body = []string{lipgloss.PlaceHorizontal(avail, lipgloss.Center, st.faint.Render("* * *"))}
+		case metadata.BlockImage:
+			label := "[image]"
+			if len(b.Spans) > 0 {
+				label = "[image: " + b.Spans[0].Text + "]"
+			}
+			body = wrapSpans([]metadata.Span{{Text: label}}, avail, func(_ metadata.Span, s string) string {
+				return st.faint.Render(s)
+			})
+		case metadata.BlockPre:
+			for _, line := range strings.Split(b.Spans[0].Text, "\n") {
+				line = strings.ReplaceAll(line, "\t", "    ")
+				body = append(body, st.text.Render(ansi.Truncate(line, avail, "…")))
+			}
+		case metadata.BlockHeading:
+			body = wrapSpans(b.Spans, avail, func(sp metadata.Span, s string) string {
+				return st.heading.Italic(sp.Italic).Render(s)
+			})
+		default:
+			body = wrapSpans(b.Spans, avail, func(sp metadata.Span, s string) string {
+				return st.text.Bold(sp.Bold).Italic(sp.Italic).Render(s)
+			})
+		}
+		for j, line := range body {
+			if j == 0 {
+				lines = append(lines, first+line)
+			} else {
+				lines = append(lines, rest+line)
+			}
+		}
+	}
+	return strings.Join(lines, "\n"), anchors
+}
+
+// wrapSpans breaks styled text into lines of at most width cells at spaces
+// and at the line breaks the text carries. Each line is styled on its own so
+// no escape sequence runs across a line break; words longer than a line are
+// cut.
+func wrapSpans(spans []metadata.Span, width int, render func(metadata.Span, string) string) []string {
+	type piece struct {
+		span metadata.Span
+		text string
+	}
+	var lines []string
+	var line strings.Builder
+	lineWidth := 0
+	var word []piece
+	wordWidth := 0
+
+	breakLine := func() {
+		lines = append(lines, line.String())
+		line.Reset()
+		lineWidth = 0
+	}
+	addWord := func() {
+		if len(word) == 0 {
+			return
+		}
+		if lineWidth > 0 && lineWidth+1+wordWidth > width {
+			breakLine()
+		}
+		if lineWidth > 0 {
+			line.WriteByte(' ')
+			lineWidth++
+		}
+		for _, p := range word {
+			for p.text != "" {
+				if lineWidth == width {
+					breakLine()
+				}
+				cut := ansi.Truncate(p.text, width-lineWidth, "")
+				if cut == "" {
+					breakLine()
+					continue
+				}
+				line.WriteString(render(p.span, cut))
+				lineWidth += ansi.StringWidth(cut)
+				p.text = p.text[len(cut):]
+			}
+		}
+		word, wordWidth = nil, 0
+	}
+
+	for _, sp := range spans {
+		start := 0
+		for i, r := range sp.Text {
+			if r != ' ' && r != '\n' {
+				continue
+			}
+			if i > start {
+				word = append(word, piece{sp, sp.Text[start:i]})
+				wordWidth += ansi.StringWidth(sp.Text[start:i])
+			}
+			addWord()
+			if r == '\n' {
+				breakLine()
+			}
+			start = i + 1
+		}
+		if start < len(sp.Text) {
+			word = append(word, piece{sp, sp.Text[start:]})
+			wordWidth += ansi.StringWidth(sp.Text[start:])
+		}
+	}
+	addWord()
+	if lineWidth > 0 || len(lines) == 0 {
+		breakLine()
+	}
+	return lines
+}
+
+func (m *MainModel) readerTOCView(width, height int) string {
+	st := newReaderStyles()
+	lines := []string{st.heading.Render("Contents"), ""}
+	rows := max(height-2, 1)
+	start := 0
+	if m.readerTOCCursor >= rows {
+		start = m.readerTOCCursor - rows + 1
+	}
+	end := min(start+rows, len(m.reader.TOC))
+	for i := start; i < end; i++ {
+		e := m.reader.TOC[i]
+		row := ansi.Truncate(strings.Repeat("  ", e.Depth)+e.Title, width-2, "...")
+		switch {
+		case i == m.readerTOCCursor:
+			row = st.marker.Render("> " + row)
+		case e.Chapter == m.readerChapter:
+			row = "  " + st.text.Bold(true).Render(row)
+		default:
+			row = "  " + st.text.Render(row)
+		}
+		lines = append(lines, row)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m *MainModel) ReaderView() string {
+	sidebarView := m.SideBarView()
+	if m.reader == nil {
+		return sidebarView
+	}
+	panelWidth, panelHeight := m.detailPanelSize()
+	style := lipgloss.NewStyle().Border(lipgloss.RoundedBorder(), true, true, true, true).
+		BorderForeground(borders).
+		Width(panelWidth).
+		Height(panelHeight)
+	st := newReaderStyles()
+	width, height := m.readerTextSize()
+
+	title := m.reader.Title
+	if chapter := m.reader.ChapterTitle(m.readerChapter); chapter != "" && chapter != title {
+		title += " · " + chapter
+	}
+	position := "Chapter " + strconv.Itoa(m.readerChapter+1) + " of " + strconv.Itoa(len(m.reader.Chapters))
+	if m.readerView.TotalLineCount() > m.readerView.Height {
+		position += "  " + strconv.Itoa(int(m.readerView.ScrollPercent()*100)) + "%"
+	}
+	position += " · " + strconv.Itoa(int(m.readerPercent())) + "% of the book"
+	hint := "↑/↓ (j/k): scroll  space: page  ←/→ (h/l): chapter  t: contents  esc: back"
+	body := m.readerView.View()
+	if m.readerTOC {
+		hint = "↑/↓ (j/k): move  enter: go  esc: close"
+		body = m.readerTOCView(width, height)
+	}
+	if m.readerNotice != "" {
+		position = m.readerNotice
+	}
+
+	margin := max((panelWidth-width)/2, 1)
+	content := lipgloss.JoinVertical(lipgloss.Left,
+		st.heading.Render(ansi.Truncate(title, width, "...")),
+		st.faint.Italic(false).Render(ansi.Truncate(position, width, "...")),
+		"",
+		lipgloss.NewStyle().Height(height).Render(body),
+		st.faint.Italic(false).Render(ansi.Truncate(hint, panelWidth-margin-1, "...")),
+	)
+	content = lipgloss.JoinHorizontal(lipgloss.Top, strings.Repeat(" ", margin), content)
+	return lipgloss.JoinHorizontal(lipgloss.Left, sidebarView,
+		style.Render(truncateBlockHeight(content, panelHeight)))
+}
@@ -0,0 +1,530 @@
+package tui
+
+import (
+	metadata "Kindria/internal/core/api/books"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// fixedMenuOptions is the sidebar without shelves; each shelf gets its own
+// entry right after "Shelves".
+var fixedMenuOptions = []string{"Home", "Books", "To-Be Read", "Currently Reading", "Series", "Authors", "Shelves", "Stats", "Add Book", "Synchronize \nKindle", "Themes"}
+
+func menuOptions(shelves []metadata.Shelf) []string {
+	options := make([]string, 0, len(fixedMenuOptions)+len(shelves))
+	for _, o := range fixedMenuOptions {
+		options = append(options, o)
+		if o == "Shelves" {
+			for _, s := range shelves {
+				options = append(options, s.Name)
+			}
+		}
+	}
+	return options
+}
+
+// reservedShelfName reports whether a shelf called name would clash with a
+// fixed sidebar entry.
+func reservedShelfName(name string) bool {
+	name = strings.Join(strings.Fields(name), " ")
+	for _, o := range fixedMenuOptions {
+		if strings.EqualFold(strings.Join(strings.Fields(o), " "), name) {
+			return true
+		}
+	}
+	return false
+}
+
+const queryPlaceholder = "status:unread rating>=4 genre:fantasy lang:es added<30d"
+
+func newShelfInput() textinput.Model {
+	t := textinput.New()
+	t.Placeholder = "shelf name"
+	t.CharLimit = 40
+	t.Width = 30
+	return t
+}
+
+// loadShelves reads the shelves and rebuilds the sidebar around them, keeping
+// the sidebar cursor on the entry it was on.
+func (m *MainModel) loadShelves() {
+	shelves, err := m.library.handler.ListShelves()
+	if err != nil {
+		log.Printf("Error loading shelves: %v", err)
+		m.shelvesStatus = "Error loading shelves: " + err.Error()
+		return
+	}
+	current := m.library.MenuOptions[m.library.sideBarCursor]
+	m.shelves = shelves
+	m.library.MenuOptions = menuOptions(shelves)
+	m.library.sideBarCursor = indexOf(m.library.MenuOptions, current)
+	if m.shelfCursor >= len(m.shelves) {
+		m.shelfCursor = max(len(m.shelves)-1, 0)
+	}
+	m.loadShelfBooks()
+}
+
+func (m *MainModel) loadShelfBooks() {
+	m.shelfBooks = nil
+	if m.shelfCursor >= len(m.shelves) {
+		return
+	}
+	books, err := m.library.handler.ShelfBooks(m.shelves[m.shelfCursor].ID)
+	if err != nil {
+		log.Printf("Error loading shelf books: %v", err)
+		return
+	}
+	m.shelfBooks = books
+}
+
+// shelfOption returns the shelf behind a sidebar entry.
+func (m *MainModel) shelfOption(option string) (metadata.Shelf, bool) {
+	for _, s := range m.shelves {
+		if s.Name == option {
+			return s, true
+		}
+	}
+	return metadata.Shelf{}, false
+}
+
+func (m *MainModel) openShelves() (tea.Model, tea.Cmd) {
+	m.state = shelvesState
+	m.library.activeArea = int(contentFocus)
+	m.shelvesStatus = ""
+	m.loadShelves()
+	return m, tea.ClearScreen
+}
+
+func (m *MainModel) openShelf(s metadata.Shelf) (tea.Model, tea.Cmd) {
+	books, err := m.library.handler.ShelfBooks(s.ID)
+	if err != nil {
+		log.Printf("Error loading shelf books: %v", err)
+	}
+	files := make([]string, 0, len(books))
+	for _, b := range books {
+		files = append(files, b.FileName)
+	}
+	m.state = librayState
+	m.library.activeArea = int(contentFocus)
+	cmd := m.library.SetScopedView("Shelf: "+s.Name, files)
+	m.library.shelf = s.ID
+	m.library.shelfQuery = s.Query
+	return m, cmd
+}
+
+func (m *MainModel) updateShelves(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.library.activeArea == int(sideFocus) {
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			switch keyMsg.String() {
+			case "ctrl+l":
+				m.library.activeArea = int(contentFocus)
+				return m, nil
+			case "enter":
+				return m.openMenuOption(m.library.MenuOptions[m.library.sideBarCursor])
+			}
+		}
+		newLib, cmd := m.library.Update(msg)
+		m.library = newLib.(*Model)
+		return m, cmd
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	if m.shelfNaming != "" {
+		return m.updateShelfName(keyMsg)
+	}
+	if m.shelfConfirmDelete {
+		switch keyMsg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+		case "y", "d":
+			m.shelfConfirmDelete = false
+			s := m.shelves[m.shelfCursor]
+			if err := m.library.handler.DeleteShelf(s.ID); err != nil {
+				log.Printf("Error deleting shelf: %v", err)
+				m.shelvesStatus = "Could not delete " + s.Name + ": " + err.Error()
+				return m, nil
+			}
+			m.shelvesStatus = "Deleted " + s.Name
+			m.loadShelves()
+			return m, tea.ClearScreen
+		case "esc", "n":
+			m.shelfConfirmDelete = false
+		}
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		m.library.activeArea = int(sideFocus)
+		return m, nil
+	case "up", "k":
+		if m.shelfCursor > 0 {
+			m.shelfCursor--
+			m.loadShelfBooks()
+		}
+	case "down", "j":
+		if m.shelfCursor < len(m.shelves)-1 {
+			m.shelfCursor++
+			m.loadShelfBooks()
+		}
+	case "n", "s":
+		m.shelfNaming = "new"
+		if keyMsg.String() == "s" {
+			m.shelfNaming = "smart"
+		}
+		return m, m.openShelfInput("")
+	case "r":
+		if m.shelfCursor < len(m.shelves) {
+			m.shelfNaming = "rename"
+			return m, m.openShelfInput(m.shelves[m.shelfCursor].Name)
+		}
+	case "e":
+		if m.shelfCursor < len(m.shelves) && m.shelves[m.shelfCursor].Query != "" {
+			m.shelfNaming = "edit"
+			return m, m.openShelfInput(m.shelves[m.shelfCursor].Query)
+		}
+	case "d", "delete":
+		if m.shelfCursor < len(m.shelves) {
+			m.shelfConfirmDelete = true
+		}
+	case "enter":
+		if m.shelfCursor < len(m.shelves) {
+			return m.openMenuOption(m.shelves[m.shelfCursor].Name)
+		}
+	}
+	return m, nil
+}
+
+// openShelfInput prepares the input for the current naming step: shelf
+// names are short, queries get more room.
+func (m *MainModel) openShelfInput(value string) tea.Cmd {
+	m.shelfInput.Reset()
+	m.shelfInput.Placeholder, m.shelfInput.CharLimit, m.shelfInput.Width = "shelf name", 40, 30
+	if m.shelfNaming == "query" || m.shelfNaming == "edit" {
+		m.shelfInput.Placeholder, m.shelfInput.CharLimit, m.shelfInput.Width = queryPlaceholder, 200, 60
+	}
+	m.shelfInput.SetValue(value)
+	m.shelfInput.CursorEnd()
+	return m.shelfInput.Focus()
+}
+
+// updateShelfName handles the shelf input. A new smart shelf takes two steps:
+// its name, then its query, which is checked before anything is saved.
+func (m *MainModel) updateShelfName(keyMsg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch keyMsg.String() {
+	case "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		m.closeShelfInput()
+		return m, nil
+	case "enter":
+		value := strings.TrimSpace(m.shelfInput.Value())
+		if value == "" {
+			m.closeShelfInput()
+			return m, nil
+		}
+		if m.shelfNaming == "query" || m.shelfNaming == "edit" {
+			if _, err := metadata.ParseQuery(value); err != nil {
+				m.shelvesStatus = "Invalid query: " + err.Error()
+				return m, nil
+			}
+		} else if reservedShelfName(value) {
+			m.shelvesStatus = fmt.Sprintf("%q is already a sidebar entry", value)
+			return m, nil
+		}
+		var err error
+		name := value
+		switch m.shelfNaming {
+		case "smart":
+			m.shelfPendingName = value
+			m.shelfNaming = "query"
+			m.shelvesStatus = ""
+			return m, m.openShelfInput("")
+		case "query":
+			name = m.shelfPendingName
+			err = m.library.handler.CreateShelf(name, value)
+		case "edit":
+			name = m.shelves[m.shelfCursor].Name
+			err = m.library.handler.SetShelfQuery(m.shelves[m.shelfCursor].ID, value)
+		case "rename":
+			err = m.library.handler.RenameShelf(m.shelves[m.shelfCursor].ID, value)
+		default:
+			err = m.library.handler.CreateShelf(value, "")
+		}
+		if err != nil {
+			m.shelvesStatus = err.Error()
+			return m, nil
+		}
+		m.shelvesStatus = ""
+		m.closeShelfInput()
+		m.loadShelves()
+		name = strings.Join(strings.Fields(name), " ")
+		for i, s := range m.shelves {
+			if s.Name == name {
+				m.shelfCursor = i
+				m.loadShelfBooks()
+			}
+		}
+		return m, tea.ClearScreen
+	}
+	var cmd tea.Cmd
+	m.shelfInput, cmd = m.shelfInput.Update(keyMsg)
+	return m, cmd
+}
+
+func (m *MainModel) closeShelfInput() {
+	m.shelfNaming = ""
+	m.shelfPendingName = ""
+	m.shelfInput.Blur()
+	m.shelfInput.Reset()
+}
+
+func (m *MainModel) ShelvesView() string {
+	sidebarView := m.SideBarView()
+	panelWidth := m.library.width - m.sideBarWidth - 4
+	panelHeight := m.library.height + 2
+	if panelWidth < 24 {
+		panelWidth = 24
+	}
+	if panelHeight < 12 {
+		panelHeight = 12
+	}
+	style := lipgloss.NewStyle().Border(lipgloss.RoundedBorder(), true, true, true, true).
+		BorderForeground(subtle).
+		Width(panelWidth).
+		Height(panelHeight)
+	if m.library.activeArea == int(contentFocus) {
+		style = style.BorderForeground(borders)
+	}
+
+	var s strings.Builder
+	s.WriteString("  Shelves\n")
+	hint := "↑/↓ (j/k): move  enter: show books  n: new  s: new smart shelf  e: edit query  r: rename  d: delete  esc: sidebar"
+	switch {
+	case m.shelfNaming != "":
+		hint = "enter: save  esc: cancel"
+	case m.shelfConfirmDelete:
+		hint = "y: delete  esc: cancel"
+	}
+	s.WriteString("  " + lipgloss.NewStyle().Foreground(normal).Faint(true).Render(hint) + "\n\n")
+
+	listHeight := panelHeight - 4
+	switch {
+	case m.shelfNaming == "new" || m.shelfNaming == "smart":
+		s.WriteString("  New shelf: " + m.shelfInput.View() + "\n")
+		listHeight--
+	case m.shelfNaming == "query":
+		s.WriteString("  Query for " + m.shelfPendingName + ": " + m.shelfInput.View() + "\n")
+		listHeight--
+	case m.shelfNaming == "edit":
+		s.WriteString("  Query: " + m.shelfInput.View() + "\n")
+		listHeight--
+	case m.shelfNaming == "rename":
+		s.WriteString("  Rename to: " + m.shelfInput.View() + "\n")
+		listHeight--
+	case m.shelfConfirmDelete:
+		s.WriteString(lipgloss.NewStyle().Foreground(highlight).
+			Render(fmt.Sprintf("  Delete the shelf %q? Its books stay in the library.", m.shelves[m.shelfCursor].Name)) + "\n")
+		listHeight--
+	}
+	if m.shelvesStatus != "" {
+		s.WriteString("  " + m.shelvesStatus + "\n")
+		listHeight--
+	}
+	if len(m.shelves) == 0 {
+		s.WriteString("    (no shelves yet: press n to create one, then b on a book to shelve it)\n")
+		content := truncateBlockHeight(truncateViewLines(s.String(), panelWidth-2), panelHeight)
+		return lipgloss.JoinHorizontal(lipgloss.Left, sidebarView, style.Render(content))
+	}
+
+	leftWidth := (panelWidth - 4) * 2 / 5
+	rightWidth := panelWidth - 4 - leftWidth - 2
+	start, end := listWindow(m.shelfCursor, len(m.shelves), listHeight)
+	left := make([]string, 0, end-start)
+	for i := start; i < end; i++ {
+		shelf := m.shelves[i]
+		prefix := "  "
+		lineStyle := lipgloss.NewStyle().Foreground(normal)
+		if i == m.shelfCursor {
+			prefix = "> "
+			lineStyle = lineStyle.Foreground(highlight)
+		}
+		line := fmt.Sprintf("%s%s (%d)", prefix, shelf.Name, shelf.BookCount)
+		if shelf.Query != "" {
+			line = fmt.Sprintf("%s%s (%d, smart)", prefix, shelf.Name, shelf.BookCount)
+		}
+		left = append(left, lineStyle.Render(ansi.Truncate(line, leftWidth, "...")))
+	}
+
+	right := make([]string, 0, len(m.shelfBooks)+3)
+	selected := m.shelves[m.shelfCursor]
+	right = append(right, lipgloss.NewStyle().Foreground(normal).Bold(true).Render(ansi.Truncate(selected.Name, rightWidth, "...")))
+	if selected.Query != "" {
+		right = append(right, lipgloss.NewStyle().Foreground(normal).Faint(true).Render(ansi.Truncate("Query: "+selected.Query, rightWidth, "...")))
+	}
+	right = append(right, "")
+	switch {
+	case len(m.shelfBooks) == 0 && selected.Query != "":
+		right = append(right, lipgloss.NewStyle().Foreground(normal).Faint(true).Render("No books match"))
+	case len(m.shelfBooks) == 0:
+		right = append(right, lipgloss.NewStyle().Foreground(normal).Faint(true).Render(ansi.Truncate("Empty: press b on a book to shelve it", rightWidth, "...")))
+	}
+	for _, b := range m.shelfBooks {
+		right = append(right, ansi.Truncate("• "+b.Title, rightWidth, "..."))
+	}
+
+	columns := lipgloss.JoinHorizontal(lipgloss.Top,
+		"  ",
+		lipgloss.NewStyle().Width(leftWidth).Render(strings.Join(left, "\n")),
+		"  ",
+		lipgloss.NewStyle().Width(rightWidth).Render(truncateBlockHeight(strings.Join(right, "\n"), listHeight)),
+	)
+	s.WriteString(columns)
+	content := truncateBlockHeight(truncateViewLines(s.String(), panelWidth-2), panelHeight)
+	return lipgloss.JoinHorizontal(lipgloss.Left, sidebarView, style.Render(content))
+}
+
+/* ----- Shelf picker ----- */
+
+// openShelfPicker shows the multi-select popup for the book under the cursor
+// with the shelves it is on already ticked.
+func (m *Model) openShelfPicker() tea.Cmd {
+	b := m.books[m.cursor]
+	all, err := m.handler.ListShelves()
+	if err != nil {
+		log.Printf("Error loading shelves: %v", err)
+		m.notice = "Could not load shelves: " + err.Error()
+		return nil
+	}
+	// Smart shelves pick their books themselves.
+	var shelves []metadata.Shelf
+	for _, s := range all {
+		if s.Query == "" {
+			shelves = append(shelves, s)
+		}
+	}
+	if len(shelves) == 0 {
+		m.notice = "No shelves yet: create one from Shelves in the sidebar"
+		return nil
+	}
+	on, err := m.handler.BookShelves(b.BookFile)
+	if err != nil {
+		log.Printf("Error loading the shelves of %s: %v", b.BookFile, err)
+	}
+	m.shelfChoices = shelves
+	m.shelfPicked = make(map[int64]bool, len(on))
+	for _, id := range on {
+		m.shelfPicked[id] = true
+	}
+	m.shelfPickerCursor = 0
+	m.showShelfPicker = true
+	return tea.ClearScreen
+}
+
+func (m *Model) updateShelfPicker(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch keyMsg.String() {
+	case "ctrl+c", "q":
+		return m, tea.Quit
+	case "esc":
+		m.showShelfPicker = false
+		return m, tea.ClearScreen
+	case "up", "k":
+		if m.shelfPickerCursor > 0 {
+			m.shelfPickerCursor--
+		}
+	case "down", "j":
+		if m.shelfPickerCursor < len(m.shelfChoices)-1 {
+			m.shelfPickerCursor++
+		}
+	case " ", "x":
+		id := m.shelfChoices[m.shelfPickerCursor].ID
+		m.shelfPicked[id] = !m.shelfPicked[id]
+	case "enter":
+		m.showShelfPicker = false
+		b := m.books[m.cursor]
+		ids := make([]int64, 0, len(m.shelfPicked))
+		for _, s := range m.shelfChoices {
+			if m.shelfPicked[s.ID] {
+				ids = append(ids, s.ID)
+			}
+		}
+		if err := m.handler.SetBookShelves(b.BookFile, ids); err != nil {
+			log.Printf("Error trying to update shelves: %v", err)
+			m.notice = "Could not update the shelves of " + b.Metadata.Title + ": " + err.Error()
+			return m, tea.ClearScreen
+		}
+		if m.shelf != 0 && !m.shelfPicked[m.shelf] {
+			return m, m.refreshShelf(m.neighbour())
+		}
+		return m, tea.ClearScreen
+	}
+	return m, nil
+}
+
+// refreshShelf reloads the shelf being shown after a book left it.
+func (m *Model) refreshShelf(focus string) tea.Cmd {
+	books, err := m.handler.ShelfBooks(m.shelf)
+	if err != nil {
+		log.Printf("Error loading shelf books: %v", err)
+		return tea.ClearScreen
+	}
+	files := make([]string, 0, len(books))
+	for _, b := range books {
+		files = append(files, b.FileName)
+	}
+	return tea.Batch(m.SetScopedView(m.currentView, files), m.focusBook(focus))
+}
+
+// queryScope runs the query of the smart shelf being shown again, so books
+// come and go as they change.
+func (m *Model) queryScope() map[string]struct{} {
+	books, err := m.handler.QueryBooks(m.shelfQuery)
+	if err != nil {
+		log.Printf("Error running shelf query: %v", err)
+		return m.scope
+	}
+	scope := make(map[string]struct{}, len(books))
+	for _, b := range books {
+		scope[b.FileName] = struct{}{}
+	}
+	return scope
+}
+
+// shelfPickerView is the popup drawn over the card: one checkbox per shelf,
+// scrolled to keep the cursor in sight.
+func (m Model) shelfPickerView() string {
+	width := max(m.dynamicCardWidth-4, 4)
+	start, end := listWindow(m.shelfPickerCursor, len(m.shelfChoices), m.dynamicCardHeight-3)
+	lines := []string{"Shelves:"}
+	for i := start; i < end; i++ {
+		s := m.shelfChoices[i]
+		box := "[ ] "
+		if m.shelfPicked[s.ID] {
+			box = "[x] "
+		}
+		line := ansi.Truncate(box+s.Name, width, "…")
+		if i == m.shelfPickerCursor {
+			line = lipgloss.NewStyle().Foreground(highlight).Render(line)
+		}
+		lines = append(lines, line)
+	}
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(highlight).
+		Render(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,328 @@
+package tui
+
+import (
+	metadata "Kindria/internal/core/api/books"
+	"Kindria/internal/utils"
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+var monthNames = []string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"}
+
+type statsLoadedMsg struct {
+	year  int
+	stats *metadata.ReadingStats
+	err   error
+}
+
+func (m *MainModel) openStats() (tea.Model, tea.Cmd) {
+	m.state = statsState
+	m.library.activeArea = int(contentFocus)
+	if m.statsYear == 0 && m.stats == nil {
+		m.statsYear = time.Now().Year()
+	}
+	m.statsView = viewport.New(0, 0)
+	return m, tea.Batch(tea.ClearScreen, m.loadStatsCmd())
+}
+
+// loadStatsCmd gathers the statistics in the background: the first time a
+// book shows up its words are counted from the EPUB.
+func (m *MainModel) loadStatsCmd() tea.Cmd {
+	m.statsLoading = true
+	h, year := m.library.handler, m.statsYear
+	return func() tea.Msg {
+		stats, err := h.ReadingStats(year)
+		return statsLoadedMsg{year: year, stats: stats, err: err}
+	}
+}
+
+// statsYears are the periods ←/→ cycle through: every year with a finished
+// read, the current one and, last, all years (0).
+func (m *MainModel) statsYears() []int {
+	seen := map[int]bool{time.Now().Year(): true, m.statsYear: true}
+	if m.stats != nil {
+		for _, y := range m.stats.Years {
+			if n, err := strconv.Atoi(y.Name); err == nil {
+				seen[n] = true
+			}
+		}
+	}
+	years := make([]int, 0, len(seen)+1)
+	for y := range seen {
+		if y != 0 {
+			years = append(years, y)
+		}
+	}
+	sort.Ints(years)
+	return append(years, 0)
+}
+
+func (m *MainModel) updateStats(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case statsLoadedMsg:
+		if msg.year != m.statsYear {
+			return m, nil
+		}
+		m.statsLoading = false
+		if msg.err != nil {
+			log.Printf("Error loading reading statistics: %v", msg.err)
+			m.statsStatus = "Error loading statistics: " + msg.err.Error()
+			return m, nil
+		}
+		m.statsStatus = ""
+		m.stats = msg.stats
+		m.statsView.SetYOffset(0)
+		m.layoutStats()
+		return m, nil
+	case tea.WindowSizeMsg:
+		m.layoutStats()
+		return m, nil
+	}
+
+	if m.library.activeArea == int(sideFocus) {
+		if keyMsg, ok := msg.(tea.KeyMsg); ok {
+			switch keyMsg.String() {
+			case "ctrl+l":
+				m.library.activeArea = int(contentFocus)
+				return m, nil
+			case "enter":
+				return m.openMenuOption(m.library.MenuOptions[m.library.sideBarCursor])
+			}
+		}
+		newLib, cmd := m.library.Update(msg)
+		m.library = newLib.(*Model)
+		return m, cmd
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch keyMsg.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	case "esc":
+		m.library.activeArea = int(sideFocus)
+		return m, nil
+	case "left", "h", "right", "l":
+		years := m.statsYears()
+		i := indexOfInt(years, m.statsYear)
+		if keyMsg.String() == "left" || keyMsg.String() == "h" {
+			i = (i - 1 + len(years)) % len(years)
+		} else {
+			i = (i + 1) % len(years)
+		}
+		m.statsYear = years[i]
+		return m, m.loadStatsCmd()
+	case "g":
+		m.statsView.GotoTop()
+		return m, nil
+	case "G":
+		m.statsView.GotoBottom()
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.statsView, cmd = m.statsView.Update(msg)
+	return m, cmd
+}
+
+func indexOfInt(values []int, v int) int {
+	for i, x := range values {
+		if x == v {
+			return i
+		}
+	}
+	return 0
+}
+
+func (m *MainModel) statsPanelSize() (int, int) {
+	panelWidth := m.library.width - m.sideBarWidth - 4
+	panelHeight := m.library.height + 2
+	if panelWidth < 24 {
+		panelWidth = 24
+	}
+	if panelHeight < 12 {
+		panelHeight = 12
+	}
+	return panelWidth, panelHeight
+}
+
+func (m *MainModel) layoutStats() {
+	panelWidth, panelHeight := m.statsPanelSize()
+	m.statsView.Width = panelWidth - 4
+	m.statsView.Height = max(panelHeight-4, 1)
+	if m.stats != nil {
+		m.statsView.SetContent(statsContent(m.stats, m.statsView.Width))
+	}
+}
+
+// statsContent lays out the dashboard: the summary, reads over time, genre
+// and language breakdowns side by side when they fit, and the reading log.
+func statsContent(s *metadata.ReadingStats, width int) string {
+	title := lipgloss.NewStyle().Foreground(normal).Bold(true)
+	label := lipgloss.NewStyle().Foreground(normal).Faint(true)
+	value := lipgloss.NewStyle().Foreground(highlight).Bold(true)
+
+	rating := "—"
+	if s.Rated > 0 {
+		rating = strconv.FormatFloat(s.AverageRating, 'f', 2, 64) + " " + utils.GetStarRating(s.AverageRating) +
+			label.Render(fmt.Sprintf(" (%d rated)", s.Rated))
+	}
+	pages := groupDigits(s.Pages)
+	if s.EstimatedPages {
+		pages += label.Render(" (partly estimated)")
+	}
+	streak := func(st metadata.Streak, unit string) string {
+		if st.Length == 0 {
+			return "—"
+		}
+		span := st.Start
+		if st.End != st.Start {
+			span += " → " + st.End
+		}
+		return value.Render(strconv.Itoa(st.Length)) + " " + unit + label.Render(" ("+span+")")
+	}
+	summary := []string{
+		label.Render("Books read      ") + value.Render(strconv.Itoa(len(s.Log))) + label.Render("   being read ") + value.Render(strconv.Itoa(s.Reading)),
+		label.Render("Average rating  ") + rating,
+		label.Render("Pages           ") + pages,
+		label.Render("Words           ") + groupDigits(s.Words),
+		label.Render("Longest streak  ") + streak(s.DayStreak, "days in a row"),
+		label.Render("                ") + streak(s.MonthStreak, "months with a finished book"),
+	}
+
+	var overTime string
+	if s.Year == 0 {
+		overTime = title.Render("Books per year") + "\n" + barChart(s.Years, width)
+	} else {
+		months := make([]metadata.Count, len(s.Months))
+		for i, n := range s.Months {
+			months[i] = metadata.Count{Name: monthNames[i], Count: n}
+		}
+		overTime = title.Render("Books per month") + "\n" + barChart(months, width)
+	}
+
+	const topCounts = 8
+	columnWidth := width
+	if width >= 70 {
+		columnWidth = (width - 4) / 2
+	}
+	genres := title.Render("Genres") + "\n" + barChart(firstCounts(s.Genres, topCounts), columnWidth)
+	languages := title.Render("Languages") + "\n" + barChart(firstCounts(s.Languages, topCounts), columnWidth)
+	breakdown := genres + "\n\n" + languages
+	if columnWidth < width {
+		breakdown = lipgloss.JoinHorizontal(lipgloss.Top,
+			lipgloss.NewStyle().Width(columnWidth+4).Render(genres), languages)
+	}
+
+	readLog := []string{title.Render("Reading log")}
+	if len(s.Log) == 0 {
+		readLog = append(readLog, label.Render("No finished books in this period"))
+	}
+	for _, r := range s.Log {
+		line := label.Render(r.FinishedAt+"  ") + r.Title + label.Render(" — "+r.Author)
+		if r.Rating > 0 {
+			line += "  " + utils.GetStarRating(r.Rating)
+		}
+		readLog = append(readLog, ansi.Truncate(line, width, "..."))
+	}
+
+	return strings.Join([]string{
+		strings.Join(summary, "\n"),
+		overTime,
+		breakdown,
+		strings.Join(readLog, "\n"),
+	}, "\n\n")
+}
+
+func firstCounts(counts []metadata.Count, n int) []metadata.Count {
+	if len(counts) > n {
+		return counts[:n]
+	}
+	return counts
+}
+
+// barChart draws one horizontal bar per count, scaled to the largest one.
+func barChart(counts []metadata.Count, width int) string {
+	if len(counts) == 0 {
+		return lipgloss.NewStyle().Foreground(normal).Faint(true).Render("Nothing yet")
+	}
+	labelWidth, most := 0, 0
+	for _, c := range counts {
+		labelWidth = max(labelWidth, min(lipgloss.Width(c.Name), 16))
+		most = max(most, c.Count)
+	}
+	barWidth := max(min(width-labelWidth-len(strconv.Itoa(most))-3, 40), 1)
+	bar := lipgloss.NewStyle().Foreground(highlight)
+	empty := lipgloss.NewStyle().Foreground(subtle)
+	lines := make([]string, 0, len(counts))
+	for _, c := range counts {
+		filled := 0
+		if most > 0 {
+			filled = (c.Count*barWidth + most - 1) / most
+		}
+		name := ansi.Truncate(c.Name, labelWidth, "…")
+		name += strings.Repeat(" ", labelWidth-lipgloss.Width(name))
+		line := lipgloss.NewStyle().Foreground(normal).Render(name) + " " + bar.Render(strings.Repeat("█", filled))
+		if c.Count == 0 {
+			line += empty.Render("·")
+		}
+		lines = append(lines, line+" "+strconv.Itoa(c.Count))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// groupDigits writes n with thousands separators.
+func groupDigits(n int64) string {
+	s := strconv.FormatInt(n, 10)
+	for i := len(s) - 3; i > 0; i -= 3 {
+		s = s[:i] + "," + s[i:]
+	}
+	return s
+}
+
+func (m *MainModel) StatsView() string {
+	sidebarView := m.SideBarView()
+	panelWidth, panelHeight := m.statsPanelSize()
+	style := lipgloss.NewStyle().Border(lipgloss.RoundedBorder(), true, true, true, true).
+		BorderForeground(subtle).
+		Width(panelWidth).
+		Height(panelHeight)
+	if m.library.activeArea == int(contentFocus) {
+		style = style.BorderForeground(borders)
+	}
+
+	period := "All years"
+	if m.statsYear != 0 {
+		period = strconv.Itoa(m.statsYear)
+	}
+	header := "  Stats · " + lipgloss.NewStyle().Foreground(highlight).Render(period)
+	if m.statsView.TotalLineCount() > m.statsView.Height {
+		header += lipgloss.NewStyle().Foreground(normal).Faint(true).
+			Render("  " + strconv.Itoa(int(m.statsView.ScrollPercent()*100)) + "%")
+	}
+	hint := lipgloss.NewStyle().Foreground(normal).Faint(true).
+		Render(ansi.Truncate("←/→ (h/l): year  ↑/↓ (j/k): scroll  g/G: top/bottom  esc: sidebar", panelWidth-4, "..."))
+
+	var body string
+	switch {
+	case m.statsStatus != "":
+		body = m.statsStatus
+	case m.stats == nil || m.statsLoading && m.stats.Year != m.statsYear:
+		body = lipgloss.NewStyle().Foreground(normal).Faint(true).Render("Counting pages and words…")
+	default:
+		body = m.statsView.View()
+	}
+	content := header + "\n  " + hint + "\n\n" + lipgloss.NewStyle().PaddingLeft(2).Render(body)
+	content = truncateBlockHeight(truncateViewLines(content, panelWidth-2), panelHeight)
+	return lipgloss.JoinHorizontal(lipgloss.Left, sidebarView, style.Render(content))
+}
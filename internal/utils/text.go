@@ -0,0 +1,65 @@
+package utils
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	htmlLineBreaks = regexp.MustCompile(`(?i)\s*<br\s*/?>\s*`)
+	htmlBreaks     = regexp.MustCompile(`(?i)</(p|div|h[1-6]|li|tr|blockquote)\s*>`)
+	htmlListItems  = regexp.MustCompile(`(?i)<li[^>]*>`)
+	htmlComments   = regexp.MustCompile(`(?s)<!--.*?-->`)
+	htmlTags       = regexp.MustCompile(`(?s)<[^>]*>`)
+	spaceRuns      = regexp.MustCompile(`[ \t\r\f\v]+`)
+	htmlScriptLike = regexp.MustCompile(`(?is)<(script|style)[^>]*>.*?</(script|style)>`)
+)
+
+// StripHTML turns an HTML fragment (EPUB descriptions usually are one) into
+// plain text, keeping paragraph breaks and list bullets.
+func StripHTML(s string) string {
+	s = htmlComments.ReplaceAllString(s, "")
+	s = htmlScriptLike.ReplaceAllString(s, "")
+	s = strings.NewReplacer("\r\n", " ", "\n", " ").Replace(s)
+	s = htmlLineBreaks.ReplaceAllString(s, "\u2028")
+	s = htmlBreaks.ReplaceAllString(s, "\n")
+	s = htmlListItems.ReplaceAllString(s, "\n• ")
+	s = htmlTags.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+	s = strings.ReplaceAll(s, "\u00a0", " ")
+
+	var b strings.Builder
+	prevBullet := false
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(spaceRuns.ReplaceAllString(line, " "))
+		if line == "" {
+			continue
+		}
+		bullet := strings.HasPrefix(line, "•")
+		if b.Len() > 0 {
+			if bullet && prevBullet {
+				b.WriteString("\n")
+			} else {
+				b.WriteString("\n\n")
+			}
+		}
+		b.WriteString(line)
+		prevBullet = bullet
+	}
+	return strings.ReplaceAll(b.String(), "\u2028", "\n")
+}
+
+func HumanSize(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := bytes / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
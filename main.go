@@ -1,8 +1,11 @@
 package main
 
 import (
+	"Kindria/internal/cli"
+	"Kindria/internal/config"
 	metadata "Kindria/internal/core/api/books"
 	"Kindria/internal/core/db"
+	"Kindria/internal/core/platform/storage"
 	"Kindria/internal/tui"
 	"database/sql"
 	"fmt"
@@ -17,7 +20,16 @@ import (
 )
 
 func main() {
-	logFile, err := os.OpenFile("kindria.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	cfg, args, err := config.Load(os.Args[1:])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "kindria: %v\n", err)
+		os.Exit(2)
+	}
+	if err := cfg.EnsureDirs(); err != nil {
+		fmt.Fprintf(os.Stderr, "kindria: %v\n", err)
+		os.Exit(1)
+	}
+	logFile, err := os.OpenFile(cfg.LogPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 	if err == nil {
 		log.SetOutput(logFile)
 		defer logFile.Close()
@@ -33,14 +45,63 @@ func main() {
 		}
 	}()
 	log.Printf("Opening DB")
-	database, err := sql.Open("sqlite", "./books.db")
+	database, err := sql.Open("sqlite", cfg.DBPath+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)")
 	if err != nil {
 		log.Printf("Error opening database:  %v", err)
 	}
-	h := &metadata.Handler{Queries: db.New(database), DB: database, CM: metadata.NewCoverManager()}
+	providers, err := metadata.NewProviders(providerSpecs(cfg), metadata.NewOpenLibrary())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "kindria: %v\n", err)
+		os.Exit(2)
+	}
+	h := &metadata.Handler{
+		Queries:    db.New(database),
+		DB:         database,
+		CM:         metadata.NewCoverManager(cfg.LibraryDir, cfg.CoversDir()),
+		Providers:  providers,
+		LibraryDir: cfg.LibraryDir,
+	}
 	log.Printf("DB Open")
-	if err := h.EnsureReadingDateColumn(); err != nil {
-		log.Printf("Error ensuring reading_date column: %v", err)
+	if len(args) == 0 || args[0] != "db" {
+		ran, err := storage.Migrate(database)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "kindria: migrating database: %v\n", err)
+			os.Exit(1)
+		}
+		for _, m := range ran {
+			log.Printf("Applied migration %05d_%s", m.Version, m.Name)
+		}
+		if linked, err := h.BackfillAuthors(); err != nil {
+			log.Printf("Error linking book authors: %v", err)
+		} else if linked > 0 {
+			log.Printf("Linked authors of %d books", linked)
+		}
+		if found, err := h.BackfillSeries(); err != nil {
+			log.Printf("Error extracting book series: %v", err)
+		} else if found > 0 {
+			log.Printf("Found series of %d books", found)
+		}
+		if hashed, err := h.BackfillHashes(); err != nil {
+			log.Printf("Error fingerprinting books: %v", err)
+		} else if hashed > 0 {
+			log.Printf("Fingerprinted %d books", hashed)
+		}
+		if moved, err := h.BackfillCovers(); err != nil {
+			log.Printf("Error moving cached covers: %v", err)
+		} else if moved > 0 {
+			log.Printf("Moved %d covers into the cover store", moved)
+		}
+	}
+
+	if len(args) > 0 {
+		if cli.NeedsCoverWorker(args) {
+			go runCoverWorker(h)
+		}
+		if err := cli.Run(h, args); err != nil {
+			fmt.Fprintf(os.Stderr, "kindria: %v\n", err)
+			os.Exit(1)
+		}
+		return
 	}
 
 	log.Printf("Inserting books")
@@ -55,7 +116,7 @@ func main() {
 		fmt.Printf("Error inserting books:  %v", err)
 	}
 	log.Printf("Books selected")
-	go h.UpdateCacheCovers()
+	go runCoverWorker(h)
 
 	log.Printf("Initializing TUI")
 	p := tea.NewProgram(
@@ -70,3 +131,21 @@ func main() {
 
 	log.Printf("TUI Initialized")
 }
+
+func runCoverWorker(h *metadata.Handler) {
+	if err := h.UpdateCacheCovers(); err != nil {
+		log.Printf("Cover worker stopped: %v", err)
+	}
+}
+
+func providerSpecs(cfg config.Config) []metadata.ProviderSpec {
+	if len(cfg.Providers) == 0 {
+		return metadata.DefaultProviders(cfg.LibraryDir)
+	}
+	specs := make([]metadata.ProviderSpec, 0, len(cfg.Providers))
+	for _, p := range cfg.Providers {
+		interval, _ := p.Interval()
+		specs = append(specs, metadata.ProviderSpec{Name: p.Name, Dir: p.Dir, RateLimit: interval})
+	}
+	return specs
+}
@@ -2,7 +2,6 @@ package kindle
 
 import (
 	metadata "Kindria/internal/core/api/books"
-	"Kindria/internal/utils"
 	"bufio"
 	"context"
 	"errors"
@@ -23,9 +22,14 @@ var (
 type SyncResult struct {
 	DetectedBooks []string
 	Inserted      int
+	Replaced      int
 	Failed        int
 	Duplicated    int
 	Refreshed     []*metadata.Package
+	// Conflicts are left for the caller with DuplicateAsk. Their converted
+	// files stay in Dir, which the caller removes once they are resolved.
+	Conflicts []metadata.Conflict
+	Dir       string
 }
 
 func DetectKindleRootURI(ctx context.Context) (string, error) {
@@ -122,7 +126,7 @@ func ScanKindleBooks(ctx context.Context) (docsURI string, books []string, err e
 	return docsURI, FilterConvertibleBooks(entries), nil
 }
 
-func KindleExtract(ctx context.Context, h *metadata.Handler, docsURI string, selected []string) (SyncResult, error) {
+func KindleExtract(ctx context.Context, h *metadata.Handler, docsURI string, selected []string, policy metadata.DuplicatePolicy) (SyncResult, error) {
 	var result SyncResult
 	if docsURI == "" {
 		root, err := DetectKindleRootURI(ctx)
@@ -144,22 +148,17 @@ func KindleExtract(ctx context.Context, h *metadata.Handler, docsURI string, sel
 		target = detected
 	}
 
-	booksFolder, err := os.ReadDir("./books")
-	if err != nil {
-		return result, err
-	}
-	existingNames := make(map[string]struct{}, len(booksFolder))
-	for _, b := range booksFolder {
-		existingNames[b.Name()] = struct{}{}
-	}
-
 	tmpDir, err := os.MkdirTemp("", "kindria-kindle-sync-*")
 	if err != nil {
 		return result, err
 	}
-	defer os.RemoveAll(tmpDir)
+	defer func() {
+		if result.Dir == "" {
+			os.RemoveAll(tmpDir)
+		}
+	}()
 
-	copied := 0
+	converted := make([]string, 0, len(target))
 	for _, name := range target {
 		srcURI := JoinMTP(docsURI, name)
 		localSrc := filepath.Join(tmpDir, name)
@@ -169,56 +168,33 @@ func KindleExtract(ctx context.Context, h *metadata.Handler, docsURI string, sel
 			continue
 		}
 
-		outputName := name
 		finalSrc := localSrc
 		if strings.ToLower(filepath.Ext(name)) != ".epub" {
-			outputName = strings.TrimSuffix(name, filepath.Ext(name)) + ".epub"
-			converted := filepath.Join(tmpDir, outputName)
-			if err := convertToEPUB(ctx, localSrc, converted); err != nil {
+			outputName := strings.TrimSuffix(name, filepath.Ext(name)) + ".epub"
+			finalSrc = filepath.Join(tmpDir, outputName)
+			if err := convertToEPUB(ctx, localSrc, finalSrc); err != nil {
 				result.Failed++
 				continue
 			}
-			finalSrc = converted
-		}
-
-		exists, err := h.CheckBookExist(outputName)
-		if err != nil {
-			result.Failed++
-			continue
-		}
-		if exists != 0 {
-			result.Duplicated++
-			continue
 		}
-		if _, ok := existingNames[outputName]; ok {
-			result.Duplicated++
-			continue
-		}
-
-		if err := utils.CopyFile(finalSrc, "./books/"+outputName); err != nil {
-			result.Failed++
-			continue
-		}
-
-		existingNames[outputName] = struct{}{}
-		copied++
+		converted = append(converted, finalSrc)
 	}
 
-	if copied == 0 {
+	if len(converted) == 0 {
 		return result, nil
 	}
 
-	insertedRows, err := h.InsertBooks()
-	if err != nil {
-		return result, err
-	}
-	result.Inserted = len(insertedRows)
-	refreshed, err := h.SelectBooks()
-	if err != nil {
-		return result, err
+	res, err := h.ImportFiles(converted, policy)
+	result.Inserted = len(res.Inserted)
+	result.Replaced = len(res.Replaced)
+	result.Failed += len(res.Failed)
+	result.Duplicated = res.Duplicated
+	result.Refreshed = res.Refreshed
+	if len(res.Conflicts) > 0 {
+		result.Conflicts = res.Conflicts
+		result.Dir = tmpDir
 	}
-	result.Refreshed = refreshed
-	return result, nil
+	return result, err
 }
 
 func convertToEPUB(ctx context.Context, src, dst string) error {